@@ -148,3 +148,20 @@ func introspectToken(token string) (data.IntrospectResponse, error) {
 
 	return introspectResp, nil
 }
+
+// RequireRole is a middleware that only lets requests through when the authenticated user has the given role.
+// It must be registered after AuthMiddleware so the roles are available in the context.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !CheckRole(GetRolesFromContext(c), role) {
+			logger.ActError("Missing required role", zap.String("endpoint", c.Request.URL.Path), zap.String("role", role))
+			c.AbortWithStatusJSON(http.StatusForbidden, data.ErrorResponse{
+				Error:            "forbidden",
+				ErrorDescription: "User does not have the required role",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,270 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"shophub-backend/money"
+	"strconv"
+	"strings"
+)
+
+// Columns of the product CSV in the order they are exported. Only the required columns must be
+// present on import, empty values of the other columns keep the product's current value.
+var Columns = []string{
+	"slug",
+	"name",
+	"price",
+	"currency",
+	"category_slug",
+	"stock",
+	"image_url",
+	"weight_kg",
+	"length_cm",
+	"width_cm",
+	"height_cm",
+	"reorder_threshold",
+}
+
+var requiredColumns = []string{"slug", "name", "price", "category_slug"}
+
+// Row is a product as it appears in the CSV. Line is the line of the row in the file, the header is line 1.
+type Row struct {
+	Line             int
+	Slug             string
+	Name             string
+	Price            money.Money
+	Currency         string
+	CategorySlug     string
+	Stock            *int
+	ImageURL         *string
+	WeightKg         *float64
+	LengthCm         *float64
+	WidthCm          *float64
+	HeightCm         *float64
+	ReorderThreshold *int
+}
+
+// RowError is a problem with one row of the CSV
+type RowError struct {
+	Line  int    `json:"line"`
+	Slug  string `json:"slug,omitempty"`
+	Error string `json:"error"`
+}
+
+// Read parses the product CSV. Rows with invalid values are returned as row errors and left out of the rows,
+// an error is returned when the file itself cannot be read or its header is missing required columns.
+func Read(r io.Reader) ([]Row, []RowError, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("the file is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read the header: %v", err)
+	}
+
+	index := map[string]int{}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if !isColumn(column) {
+			return nil, nil, fmt.Errorf("unknown column %q", column)
+		}
+		if _, ok := index[column]; ok {
+			return nil, nil, fmt.Errorf("column %q appears more than once", column)
+		}
+		index[column] = i
+	}
+	for _, column := range requiredColumns {
+		if _, ok := index[column]; !ok {
+			return nil, nil, fmt.Errorf("missing required column %q", column)
+		}
+	}
+	// Every row must have as many fields as the header
+	reader.FieldsPerRecord = len(header)
+
+	var rows []Row
+	var rowErrors []RowError
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount) {
+				rowErrors = append(rowErrors, RowError{Line: parseErr.StartLine, Error: fmt.Sprintf("expected %d fields", len(header))})
+				continue
+			}
+			return nil, nil, fmt.Errorf("unable to read the file: %v", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		value := func(column string) string {
+			if i, ok := index[column]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if isBlank(record) {
+			continue
+		}
+		row, err := parseRow(line, value)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Slug: value("slug"), Error: err.Error()})
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, rowErrors, nil
+}
+
+func parseRow(line int, value func(column string) string) (Row, error) {
+	row := Row{
+		Line:         line,
+		Slug:         strings.ToLower(value("slug")),
+		Name:         value("name"),
+		Currency:     strings.ToUpper(value("currency")),
+		CategorySlug: strings.ToLower(value("category_slug")),
+	}
+	for _, column := range requiredColumns {
+		if value(column) == "" {
+			return row, fmt.Errorf("%s is required", column)
+		}
+	}
+	if len(row.Slug) > 250 {
+		return row, fmt.Errorf("slug must be at most 250 characters")
+	}
+	if len(row.Name) > 250 {
+		return row, fmt.Errorf("name must be at most 250 characters")
+	}
+
+	price, err := money.Parse(value("price"), row.Currency)
+	if err != nil || !price.IsPositive() {
+		return row, fmt.Errorf("price must be a positive amount")
+	}
+	row.Price = price
+
+	if row.Stock, err = parseInt(value("stock"), "stock"); err != nil {
+		return row, err
+	}
+	if row.ReorderThreshold, err = parseInt(value("reorder_threshold"), "reorder_threshold"); err != nil {
+		return row, err
+	}
+	if imageURL := value("image_url"); imageURL != "" {
+		row.ImageURL = &imageURL
+	}
+	for column, target := range map[string]**float64{
+		"weight_kg": &row.WeightKg,
+		"length_cm": &row.LengthCm,
+		"width_cm":  &row.WidthCm,
+		"height_cm": &row.HeightCm,
+	} {
+		if *target, err = parseFloat(value(column), column); err != nil {
+			return row, err
+		}
+	}
+	return row, nil
+}
+
+// parseInt reads a whole number that is not negative, nil when the value is empty
+func parseInt(value string, column string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return nil, fmt.Errorf("%s must be a whole number of 0 or more", column)
+	}
+	return &number, nil
+}
+
+// parseFloat reads a number that is not negative, nil when the value is empty
+func parseFloat(value string, column string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return nil, fmt.Errorf("%s must be a number of 0 or more", column)
+	}
+	return &number, nil
+}
+
+func isColumn(column string) bool {
+	for _, known := range Columns {
+		if column == known {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Writer writes products as CSV with all the columns
+type Writer struct {
+	csv *csv.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{csv: csv.NewWriter(w)}
+}
+
+func (w *Writer) WriteHeader() error {
+	return w.csv.Write(Columns)
+}
+
+func (w *Writer) Write(row Row) error {
+	return w.csv.Write([]string{
+		row.Slug,
+		row.Name,
+		row.Price.String(),
+		row.Currency,
+		row.CategorySlug,
+		formatInt(row.Stock),
+		formatString(row.ImageURL),
+		formatFloat(row.WeightKg),
+		formatFloat(row.LengthCm),
+		formatFloat(row.WidthCm),
+		formatFloat(row.HeightCm),
+		formatInt(row.ReorderThreshold),
+	})
+}
+
+// Flush writes the buffered rows, it returns the first error of any write
+func (w *Writer) Flush() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+func formatInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+func formatFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+func formatString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
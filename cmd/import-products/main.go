@@ -0,0 +1,85 @@
+// Command import-products creates or updates products from a CSV file, the same way as
+// POST /admin/products/import. Run with -dry-run to only validate the file.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"shophub-backend/config"
+	"shophub-backend/database"
+	"shophub-backend/logger"
+	"shophub-backend/money"
+	"shophub-backend/repository"
+	"shophub-backend/service"
+)
+
+// importActor is recorded as the actor of the stock movements of the import
+const importActor = "import-products"
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "validate the file without importing anything")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: import-products [-dry-run] products.csv")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	defer logger.Sync()
+	logger.Init()
+
+	if os.Getenv("ENV") != "production" {
+		config.LoadEnv()
+	}
+	money.DefaultCurrency = config.LoadConfig().DefaultCurrency
+	pgDb := database.InitDB()
+
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer file.Close()
+
+	currencyService, err := service.NewCurrencyServiceImpl(repository.NewExchangeRateRepository(pgDb))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to initialize the currency service:", err)
+		os.Exit(1)
+	}
+	catalogService, err := service.NewCatalogServiceImpl(
+		repository.NewProductRepository(pgDb),
+		repository.NewCategoryRepository(pgDb),
+		repository.NewWarehouseRepository(pgDb),
+		currencyService,
+	)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to initialize the catalog service:", err)
+		os.Exit(1)
+	}
+
+	report, err := catalogService.ImportProducts(file, *dryRun, importActor)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, rowError := range report.Errors {
+		fmt.Printf("line %d %s: %s\n", rowError.Line, rowError.Slug, rowError.Error)
+	}
+
+	switch {
+	case *dryRun && len(report.Errors) == 0:
+		fmt.Printf("%d rows are valid, %d products would be created and %d updated\n", report.Rows, report.Created, report.Updated)
+	case !report.Applied:
+		fmt.Printf("%d of %d rows are invalid, nothing was imported\n", len(report.Errors), report.Rows)
+		os.Exit(2)
+	default:
+		fmt.Printf("%d products created, %d updated\n", report.Created, report.Updated)
+		if len(report.Errors) > 0 {
+			os.Exit(2)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Command reconcile-stock recomputes the stock of every warehouse and product from the stock ledger
+// and reports where it differs from the recorded stock. Run with -apply to correct the recorded stock.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"shophub-backend/config"
+	"shophub-backend/database"
+	"shophub-backend/logger"
+	"shophub-backend/repository"
+	"shophub-backend/service"
+	"time"
+)
+
+func main() {
+	apply := flag.Bool("apply", false, "overwrite the recorded stock with the stock from the ledger")
+	flag.Parse()
+
+	defer logger.Sync()
+	logger.Init()
+
+	if os.Getenv("ENV") != "production" {
+		config.LoadEnv()
+	}
+	pgDb := database.InitDB()
+
+	inventoryService, err := service.NewInventoryServiceImpl(
+		repository.NewInventoryRepository(pgDb),
+		repository.NewStockMovementRepository(pgDb),
+		repository.NewOrderRepository(pgDb),
+		repository.NewPaymentRepositoryImpl(pgDb),
+		time.Duration(config.LoadConfig().ReservationTTLMinutes)*time.Minute,
+	)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to initialize the inventory service:", err)
+		os.Exit(1)
+	}
+
+	discrepancies, err := inventoryService.Reconcile(*apply)
+	for _, discrepancy := range discrepancies {
+		location := "total"
+		if discrepancy.WarehouseID != nil {
+			location = fmt.Sprintf("warehouse %d", *discrepancy.WarehouseID)
+		}
+		product := fmt.Sprintf("product %d", discrepancy.ProductID)
+		if discrepancy.VariantID != 0 {
+			product = fmt.Sprintf("%s variant %d", product, discrepancy.VariantID)
+		}
+		fmt.Printf("%s %s: recorded %d, ledger %d (%+d)\n",
+			product, location, discrepancy.Recorded, discrepancy.Ledger, discrepancy.Difference)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	switch {
+	case len(discrepancies) == 0:
+		fmt.Println("stock matches the ledger")
+	case *apply:
+		fmt.Printf("%d discrepancies corrected\n", len(discrepancies))
+	default:
+		fmt.Printf("%d discrepancies found, run with -apply to correct them\n", len(discrepancies))
+		os.Exit(2)
+	}
+}
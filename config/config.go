@@ -24,6 +24,34 @@ type Config struct {
 	IdpRealm        string
 	IdpClientSecret string
 	IdpClientId     string
+	AdminRole       string
+	DefaultCurrency string
+
+	ExchangeRatesFile string
+
+	MaxAddressesPerUser int
+
+	ReservationTTLMinutes           int
+	ReservationSweepIntervalMinutes int
+
+	LowStockThreshold         int
+	LowStockWindowDays        int
+	StockAlertIntervalMinutes int
+	StockAlertRecipient       string
+
+	ProductPublishIntervalMinutes int
+
+	RecommendationRefreshIntervalMinutes int
+	RecommendationWindowDays             int
+	RecommendationBasketMinutes          int
+	RecommendationMinPairCount           int
+	RecommendationLimit                  int
+	RecommendationMaxLimit               int
+
+	AbandonedCartThresholdMinutes     int
+	AbandonedCartCheckIntervalMinutes int
+	NotifierType                      string
+	NotificationFilePath              string
 }
 
 func LoadEnv() {
@@ -57,6 +85,34 @@ func LoadConfig() *Config {
 		IdpRealm:        Getenv("IDP_REALM", ""),
 		IdpClientId:     Getenv("IDP_CLIENT_ID", ""),
 		IdpClientSecret: Getenv("IDP_CLIENT_SECRET", ""),
+		AdminRole:       Getenv("ADMIN_ROLE", "admin"),
+		DefaultCurrency: Getenv("DEFAULT_CURRENCY", "USD"),
+
+		ExchangeRatesFile: Getenv("EXCHANGE_RATES_FILE", ""),
+
+		MaxAddressesPerUser: GetenvAsInt("MAX_ADDRESSES_PER_USER", 20),
+
+		ReservationTTLMinutes:           GetenvAsInt("RESERVATION_TTL_MINUTES", 15),
+		ReservationSweepIntervalMinutes: GetenvAsInt("RESERVATION_SWEEP_INTERVAL_MINUTES", 1),
+
+		LowStockThreshold:         GetenvAsInt("LOW_STOCK_THRESHOLD", 5),
+		LowStockWindowDays:        GetenvAsInt("LOW_STOCK_WINDOW_DAYS", 30),
+		StockAlertIntervalMinutes: GetenvAsInt("STOCK_ALERT_CHECK_INTERVAL_MINUTES", 15),
+		StockAlertRecipient:       Getenv("STOCK_ALERT_RECIPIENT", "merchandising"),
+
+		ProductPublishIntervalMinutes: GetenvAsInt("PRODUCT_PUBLISH_INTERVAL_MINUTES", 1),
+
+		RecommendationRefreshIntervalMinutes: GetenvAsInt("RECOMMENDATION_REFRESH_INTERVAL_MINUTES", 360),
+		RecommendationWindowDays:             GetenvAsInt("RECOMMENDATION_WINDOW_DAYS", 180),
+		RecommendationBasketMinutes:          GetenvAsInt("RECOMMENDATION_BASKET_MINUTES", 30),
+		RecommendationMinPairCount:           GetenvAsInt("RECOMMENDATION_MIN_PAIR_COUNT", 1),
+		RecommendationLimit:                  GetenvAsInt("RECOMMENDATION_LIMIT", 8),
+		RecommendationMaxLimit:               GetenvAsInt("RECOMMENDATION_MAX_LIMIT", 50),
+
+		AbandonedCartThresholdMinutes:     GetenvAsInt("ABANDONED_CART_THRESHOLD_MINUTES", 1440),
+		AbandonedCartCheckIntervalMinutes: GetenvAsInt("ABANDONED_CART_CHECK_INTERVAL_MINUTES", 60),
+		NotifierType:                      Getenv("NOTIFIER_TYPE", "log"),
+		NotificationFilePath:              Getenv("NOTIFICATION_FILE_PATH", "logs/notifications.log"),
 	}
 
 }
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"net/http"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AbandonedCartController struct {
+	AbandonedCartService service.AbandonedCartService
+}
+
+func NewAbandonedCartController(AbandonedCartService service.AbandonedCartService) *AbandonedCartController {
+	return &AbandonedCartController{
+		AbandonedCartService: AbandonedCartService,
+	}
+}
+
+func (c *AbandonedCartController) GetAbandonmentReport(ctx *gin.Context) {
+	logger.ActInfo("Fetching abandoned cart report")
+
+	// Optional override of the configured idle threshold
+	var threshold time.Duration
+	if thresholdParam := ctx.Query("threshold_minutes"); thresholdParam != "" {
+		minutes, err := strconv.Atoi(thresholdParam)
+		if err != nil || minutes <= 0 {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: "threshold_minutes must be a positive number",
+			})
+			return
+		}
+		threshold = time.Duration(minutes) * time.Minute
+	}
+
+	report, err := c.AbandonedCartService.GetAbandonmentReport(threshold)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: "Failed to fetch the abandoned cart report",
+			Details:          err.Error(),
+		})
+		return
+	}
+	logger.ActInfo("Abandoned cart report fetched successfully")
+	ctx.JSON(http.StatusOK, report)
+}
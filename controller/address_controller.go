@@ -1,12 +1,15 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"shophub-backend/auth"
 	"shophub-backend/data"
 	"shophub-backend/logger"
 	"shophub-backend/model"
+	"shophub-backend/postal"
 	"shophub-backend/service"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -68,37 +71,25 @@ func (c *AddressController) CreateAddress(ctx *gin.Context) {
 		logger.ActError("Failed to bind request body", zap.Error(err))
 		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
 			Error:            "Bad Request",
-			ErrorDescription: "Invalid request body. Expected: {line1: string, line2: string, city: string, postal_code: string, country: string}",
+			ErrorDescription: "Invalid request body. Expected: {label?: string, line1: string, line2: string, city: string, postal_code: string, country: string, region?: string}",
 			Details:          err.Error(),
 		})
 		return
 	}
-	//checking if all field are not null
-	if req.Line1 == "" || req.Line2 == "" || req.City == "" || req.PostalCode == "" || req.Country == "" {
-		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
-			Error:            "Bad Request",
-			ErrorDescription: "All fields are required",
-		})
-		return
-	}
-
 	//creating address object
 	address := &model.Address{
 		KeycloakUserID: keycloakUserID,
+		Label:          req.Label,
 		Line1:          req.Line1,
 		Line2:          req.Line2,
 		City:           req.City,
 		PostalCode:     req.PostalCode,
 		Country:        req.Country,
+		Region:         req.Region,
 	}
 	//Calling create address service
 	if err := c.AddressService.CreateAddress(address); err != nil {
-		logger.ActError("Failed to create address", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
-			Error:            "Internal Server Error",
-			ErrorDescription: "Failed to create address",
-			Details:          err.Error(),
-		})
+		respondAddressError(ctx, "Failed to create address", err)
 		return
 	}
 	logger.ActInfo("Address created successfully")
@@ -106,3 +97,144 @@ func (c *AddressController) CreateAddress(ctx *gin.Context) {
 		Message: "Address created successfully",
 	})
 }
+
+func (c *AddressController) UpdateAddress(ctx *gin.Context) {
+	logger.ActInfo("Updating address")
+
+	// Extract Keycloak user ID from token claims
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	addressId, ok := parseIdParam(ctx, "addressId")
+	if !ok {
+		return
+	}
+
+	var req data.CreateAddressRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {label?: string, line1: string, line2: string, city: string, postal_code: string, country: string, region?: string}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	address, err := c.AddressService.UpdateAddress(claims.Sub, addressId, req)
+	if err != nil {
+		respondAddressError(ctx, "Failed to update address", err)
+		return
+	}
+	logger.ActInfo("Address updated successfully")
+	ctx.JSON(http.StatusOK, address)
+}
+
+func (c *AddressController) DeleteAddress(ctx *gin.Context) {
+	logger.ActInfo("Deleting address")
+
+	// Extract Keycloak user ID from token claims
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	addressId, ok := parseIdParam(ctx, "addressId")
+	if !ok {
+		return
+	}
+
+	if err := c.AddressService.DeleteAddress(claims.Sub, addressId); err != nil {
+		respondAddressError(ctx, "Failed to delete address", err)
+		return
+	}
+	logger.ActInfo("Address deleted successfully")
+	ctx.JSON(http.StatusOK, data.MessageResponse{
+		Message: "Address deleted successfully",
+	})
+}
+
+func (c *AddressController) SetDefaultShippingAddress(ctx *gin.Context) {
+	c.setDefaultAddress(ctx, service.AddressDefaultShipping)
+}
+
+func (c *AddressController) SetDefaultBillingAddress(ctx *gin.Context) {
+	c.setDefaultAddress(ctx, service.AddressDefaultBilling)
+}
+
+func (c *AddressController) setDefaultAddress(ctx *gin.Context, kind string) {
+	logger.ActInfo("Setting default " + kind + " address")
+
+	// Extract Keycloak user ID from token claims
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	addressId, ok := parseIdParam(ctx, "addressId")
+	if !ok {
+		return
+	}
+
+	address, err := c.AddressService.SetDefaultAddress(claims.Sub, addressId, kind)
+	if err != nil {
+		respondAddressError(ctx, "Failed to set default address", err)
+		return
+	}
+	logger.ActInfo("Default " + kind + " address set successfully")
+	ctx.JSON(http.StatusOK, address)
+}
+
+func respondAddressError(ctx *gin.Context, description string, err error) {
+	logger.ActError(description, zap.Error(err))
+	if respondValidationError(ctx, err) {
+		return
+	}
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+			Error:            "Not Found",
+			ErrorDescription: err.Error(),
+		})
+	case strings.Contains(err.Error(), "address book is full"),
+		strings.Contains(err.Error(), "invalid"):
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: err.Error(),
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: description,
+			Details:          err.Error(),
+		})
+	}
+}
+
+// respondValidationError reports the invalid address fields, false when the error is not a validation error
+func respondValidationError(ctx *gin.Context, err error) bool {
+	var validationErr *postal.ValidationError
+	if !errors.As(err, &validationErr) {
+		return false
+	}
+	ctx.JSON(http.StatusBadRequest, data.ValidationErrorResponse{
+		Error:            "Bad Request",
+		ErrorDescription: err.Error(),
+		Fields:           validationErr.Fields,
+	})
+	return true
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"net/http"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type AttributeController struct {
+	AttributeService service.AttributeService
+}
+
+func NewAttributeController(AttributeService service.AttributeService) *AttributeController {
+	return &AttributeController{
+		AttributeService: AttributeService,
+	}
+}
+
+func (c *AttributeController) CreateAttribute(ctx *gin.Context) {
+	logger.ActInfo("Creating category attribute")
+	categoryId, ok := parseIdParam(ctx, "categoryId")
+	if !ok {
+		return
+	}
+
+	var req data.CreateAttributeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {code: string, name: string, type: TEXT|NUMBER|BOOLEAN|ENUM, unit?: string, is_filterable?: boolean, position?: number, values?: string[]}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	attribute, err := c.AttributeService.CreateAttribute(categoryId, req)
+	if err != nil {
+		respondAttributeError(ctx, "Failed to create the attribute", err)
+		return
+	}
+	logger.ActInfo("Category attribute created successfully")
+	ctx.JSON(http.StatusOK, attribute)
+}
+
+func (c *AttributeController) GetCategoryAttributes(ctx *gin.Context) {
+	logger.ActInfo("Fetching category attributes")
+	categoryId, ok := parseIdParam(ctx, "categoryId")
+	if !ok {
+		return
+	}
+
+	attributes, err := c.AttributeService.GetCategoryAttributes(categoryId)
+	if err != nil {
+		respondAttributeError(ctx, "Failed to fetch the category attributes", err)
+		return
+	}
+	logger.ActInfo("Category attributes fetched successfully")
+	ctx.JSON(http.StatusOK, attributes)
+}
+
+func (c *AttributeController) SetProductAttributes(ctx *gin.Context) {
+	logger.ActInfo("Setting product attributes")
+	productId, ok := parseIdParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req data.SetProductAttributesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {values: {code: value}}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	values, err := c.AttributeService.SetProductAttributes(productId, req)
+	if err != nil {
+		respondAttributeError(ctx, "Failed to set the product attributes", err)
+		return
+	}
+	logger.ActInfo("Product attributes set successfully")
+	ctx.JSON(http.StatusOK, values)
+}
+
+func respondAttributeError(ctx *gin.Context, description string, err error) {
+	logger.ActError(description, zap.Error(err))
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+			Error:            "Not Found",
+			ErrorDescription: err.Error(),
+		})
+	case strings.Contains(err.Error(), "failed to"):
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: description,
+			Details:          err.Error(),
+		})
+	default:
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: err.Error(),
+		})
+	}
+}
@@ -37,8 +37,39 @@ func (c *CartController) GetUserCart(ctx *gin.Context) {
 	}
 
 	keycloakUserID := claims.Sub
-	cart, err := c.CartService.GetUserCart(keycloakUserID)
+
+	// The tax is previewed for the shipping address given as address_id
+	var addressId *uint
+	if param := strings.TrimSpace(ctx.Query("address_id")); param != "" {
+		id, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: "Invalid address_id",
+				Details:          err.Error(),
+			})
+			return
+		}
+		parsed := uint(id)
+		addressId = &parsed
+	}
+
+	cart, err := c.CartService.GetUserCart(keycloakUserID, requestCurrency(ctx), addressId)
 	if err != nil {
+		if isCurrencyError(err) {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: err.Error(),
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "address not found") {
+			ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+				Error:            "Not Found",
+				ErrorDescription: err.Error(),
+			})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
 			Error:            "Internal Server Error",
 			ErrorDescription: "Failed to fetch the user cart",
@@ -70,7 +101,7 @@ func (c *CartController) AddItemToCart(ctx *gin.Context) {
 		logger.ActError("Failed to bind request body", zap.Error(err))
 		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
 			Error:            "Bad Request",
-			ErrorDescription: "Invalid request body. Expected: {product_id: number, quantity: number}",
+			ErrorDescription: "Invalid request body. Expected: {product_id: number, quantity: number, variant_id?: number}",
 			Details:          err.Error(),
 		})
 		return
@@ -84,13 +115,13 @@ func (c *CartController) AddItemToCart(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.CartService.AddTOCart(keycloakUserID, req.ProductID, req.Quantity); err != nil {
-		if strings.Contains(err.Error(), "insufficient stock") {
+	if err := c.CartService.AddTOCart(keycloakUserID, req.ProductID, req.VariantID, req.Quantity); err != nil {
+		if strings.Contains(err.Error(), "insufficient stock") || strings.Contains(err.Error(), "variant is required") || strings.Contains(err.Error(), "not available") {
 			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
 				Error:            "Bad Request",
 				ErrorDescription: err.Error(),
 			})
-		} else if strings.Contains(err.Error(), "product not found") {
+		} else if strings.Contains(err.Error(), "product not found") || strings.Contains(err.Error(), "variant not found") {
 			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
 				Error:            "Not Found",
 				ErrorDescription: err.Error(),
@@ -228,3 +259,209 @@ func (c *CartController) UpdateCartItemQuantity(ctx *gin.Context) {
 		Message: "Cart item quantity updated successfully",
 	})
 }
+
+func (c *CartController) SelectCartItem(ctx *gin.Context) {
+	logger.ActInfo("Updating cart item selection")
+
+	// Extract Keycloak user ID from token claims
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	keycloakUserID := claims.Sub
+
+	itemIdParam := ctx.Param("itemId")
+	if itemIdParam == "" {
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Missing itemId path parameter",
+		})
+		return
+	}
+
+	itemId, err := strconv.ParseUint(itemIdParam, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid itemId",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	var req data.UpdateCartItemSelectionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {is_selected: boolean}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	if err := c.CartService.SelectCartItem(keycloakUserID, uint(itemId), *req.IsSelected); err != nil {
+		if strings.Contains(err.Error(), "cart item not found") {
+			ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+				Error:            "Not Found",
+				ErrorDescription: err.Error(),
+			})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+				Error:            "Internal Server Error",
+				ErrorDescription: "Failed to update cart item selection",
+				Details:          err.Error(),
+			})
+		}
+		return
+	}
+	logger.ActInfo("Cart item selection updated successfully")
+	ctx.JSON(http.StatusOK, data.MessageResponse{
+		Message: "Cart item selection updated successfully",
+	})
+}
+
+func (c *CartController) SelectAllCartItems(ctx *gin.Context) {
+	logger.ActInfo("Updating selection for all cart items")
+
+	// Extract Keycloak user ID from token claims
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	keycloakUserID := claims.Sub
+
+	var req data.UpdateCartItemSelectionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {is_selected: boolean}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	if err := c.CartService.SelectAllCartItems(keycloakUserID, *req.IsSelected); err != nil {
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: "Failed to update cart selection",
+			Details:          err.Error(),
+		})
+		return
+	}
+	logger.ActInfo("Cart selection updated successfully")
+	ctx.JSON(http.StatusOK, data.MessageResponse{
+		Message: "Cart selection updated successfully",
+	})
+}
+
+func (c *CartController) AcknowledgePriceChanges(ctx *gin.Context) {
+	logger.ActInfo("Acknowledging cart price changes")
+
+	// Extract Keycloak user ID from token claims
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	keycloakUserID := claims.Sub
+
+	if err := c.CartService.AcknowledgePriceChanges(keycloakUserID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: "Failed to acknowledge cart price changes",
+			Details:          err.Error(),
+		})
+		return
+	}
+	logger.ActInfo("Cart price changes acknowledged successfully")
+	ctx.JSON(http.StatusOK, data.MessageResponse{
+		Message: "Cart price changes acknowledged",
+	})
+}
+
+func (c *CartController) ApplyCoupon(ctx *gin.Context) {
+	logger.ActInfo("Applying coupon to the cart")
+
+	// Extract Keycloak user ID from token claims
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	keycloakUserID := claims.Sub
+
+	var req data.ApplyCouponRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {code: string}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	if err := c.CartService.ApplyCoupon(keycloakUserID, req.Code); err != nil {
+		if strings.Contains(err.Error(), "coupon") {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: err.Error(),
+			})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+				Error:            "Internal Server Error",
+				ErrorDescription: "Failed to apply coupon",
+				Details:          err.Error(),
+			})
+		}
+		return
+	}
+	logger.ActInfo("Coupon applied successfully")
+	ctx.JSON(http.StatusOK, data.MessageResponse{
+		Message: "Coupon applied successfully",
+	})
+}
+
+func (c *CartController) RemoveCoupon(ctx *gin.Context) {
+	logger.ActInfo("Removing coupon from the cart")
+
+	// Extract Keycloak user ID from token claims
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	if err := c.CartService.RemoveCoupon(claims.Sub); err != nil {
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: "Failed to remove coupon",
+			Details:          err.Error(),
+		})
+		return
+	}
+	logger.ActInfo("Coupon removed successfully")
+	ctx.JSON(http.StatusOK, data.MessageResponse{
+		Message: "Coupon removed successfully",
+	})
+}
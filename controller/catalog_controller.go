@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"io"
+	"net/http"
+	"shophub-backend/auth"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/service"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxProductImportBytes limits the size of an uploaded product CSV
+const maxProductImportBytes = 10 << 20
+
+type CatalogController struct {
+	CatalogService service.CatalogService
+}
+
+func NewCatalogController(CatalogService service.CatalogService) *CatalogController {
+	return &CatalogController{
+		CatalogService: CatalogService,
+	}
+}
+
+// ImportProducts reads the CSV from the "file" field of a multipart form or from the request body.
+// ?dry_run=true only validates the file.
+func (c *CatalogController) ImportProducts(ctx *gin.Context) {
+	logger.ActInfo("Importing products")
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	dryRun := false
+	if dryRunParam := strings.TrimSpace(ctx.Query("dry_run")); dryRunParam != "" {
+		parsed, err := strconv.ParseBool(dryRunParam)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: "Invalid dry_run, expected true or false",
+				Details:          err.Error(),
+			})
+			return
+		}
+		dryRun = parsed
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxProductImportBytes)
+	var body io.Reader = ctx.Request.Body
+	if strings.HasPrefix(ctx.ContentType(), "multipart/form-data") {
+		header, err := ctx.FormFile("file")
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: "Expected the CSV in the file field of the form",
+				Details:          err.Error(),
+			})
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: "Unable to read the uploaded file",
+				Details:          err.Error(),
+			})
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	report, err := c.CatalogService.ImportProducts(body, dryRun, claims.Sub)
+	if err != nil {
+		logger.ActError("Failed to import the products", zap.Error(err))
+		if strings.Contains(err.Error(), "failed to") {
+			ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+				Error:            "Internal Server Error",
+				ErrorDescription: "Failed to import the products",
+				Details:          err.Error(),
+			})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	// Nothing was imported because of invalid rows, the report lists them
+	if !report.DryRun && !report.Applied {
+		logger.ActInfo("Product import rejected", zap.Int("errors", len(report.Errors)))
+		ctx.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+	logger.ActInfo("Products imported successfully")
+	ctx.JSON(http.StatusOK, report)
+}
+
+// ExportProducts streams the whole catalogue as CSV
+func (c *CatalogController) ExportProducts(ctx *gin.Context) {
+	logger.ActInfo("Exporting products")
+	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Header("Content-Disposition", `attachment; filename="products.csv"`)
+	ctx.Status(http.StatusOK)
+
+	if err := c.CatalogService.ExportProducts(ctx.Writer); err != nil {
+		logger.ActError("Failed to export the products", zap.Error(err))
+		// Once rows were sent the status cannot change anymore, the export is cut short instead
+		if !ctx.Writer.Written() {
+			ctx.Header("Content-Type", "")
+			ctx.Header("Content-Disposition", "")
+			ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+				Error:            "Internal Server Error",
+				ErrorDescription: "Failed to export the products",
+				Details:          err.Error(),
+			})
+		}
+		return
+	}
+	logger.ActInfo("Products exported successfully")
+}
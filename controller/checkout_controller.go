@@ -6,6 +6,8 @@ import (
 	"shophub-backend/data"
 	"shophub-backend/logger"
 	"shophub-backend/service"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -13,11 +15,13 @@ import (
 
 type CheckoutController struct {
 	CheckoutService service.CheckoutService
+	ShippingService service.ShippingService
 }
 
-func NewCheckoutController(CheckoutService service.CheckoutService) *CheckoutController {
+func NewCheckoutController(CheckoutService service.CheckoutService, ShippingService service.ShippingService) *CheckoutController {
 	return &CheckoutController{
 		CheckoutService: CheckoutService,
+		ShippingService: ShippingService,
 	}
 }
 
@@ -42,7 +46,7 @@ func (c *CheckoutController) CreateOrder(ctx *gin.Context) {
 		logger.ActError("Failed to bind request body", zap.Error(err))
 		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
 			Error:            "Bad Request",
-			ErrorDescription: "Invalid request body. Expected: {payment_method: string, address: {line1: string, line2: string, city: string, postal_code: string, country: string}}",
+			ErrorDescription: "Invalid request body. Expected: {payment_method: string, address_id?: number, address?: {line1: string, line2: string, city: string, postal_code: string, country: string, region?: string}, billing_address_id?: number, billing_address?: {...}, save_address?: boolean, shipping_method_id?: number}",
 			Details:          err.Error(),
 		})
 		return
@@ -57,24 +61,42 @@ func (c *CheckoutController) CreateOrder(ctx *gin.Context) {
 		return
 	}
 
-	// Validate address fields
-	if req.Address.Line1 == "" || req.Address.Line2 == "" || req.Address.City == "" || req.Address.PostalCode == "" || req.Address.Country == "" {
+	// A saved address is used as it is, new addresses are validated for their country
+	if req.AddressID == nil && req.Address == nil {
 		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
 			Error:            "Bad Request",
-			ErrorDescription: "All address fields are required",
+			ErrorDescription: "An address_id or address is required",
 		})
 		return
 	}
 
 	// Call checkout service to place order
-	order, err := c.CheckoutService.PlaceOrder(keycloakUserID, req.PaymentMethod, req.Address)
+	order, err := c.CheckoutService.PlaceOrder(keycloakUserID, req, requestCurrency(ctx))
 	if err != nil {
 		logger.ActError("Failed to place order", zap.Error(err))
-		if err.Error() == "cart not found" || err.Error() == "cart is empty" {
+		if respondValidationError(ctx, err) {
+			return
+		}
+		if err.Error() == "cart not found" || err.Error() == "cart is empty" || err.Error() == "no items selected for checkout" {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: err.Error(),
+			})
+		} else if strings.Contains(err.Error(), "address not found") {
+			ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+				Error:            "Not Found",
+				ErrorDescription: err.Error(),
+			})
+		} else if strings.Contains(err.Error(), "address is required") || strings.Contains(err.Error(), "address book is full") || strings.Contains(err.Error(), "coupon") || strings.Contains(err.Error(), "shipping method") || isCurrencyError(err) {
 			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
 				Error:            "Bad Request",
 				ErrorDescription: err.Error(),
 			})
+		} else if strings.Contains(err.Error(), "prices have changed") {
+			ctx.JSON(http.StatusConflict, data.ErrorResponse{
+				Error:            "Conflict",
+				ErrorDescription: err.Error(),
+			})
 		} else {
 			ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
 				Error:            "Internal Server Error",
@@ -88,3 +110,123 @@ func (c *CheckoutController) CreateOrder(ctx *gin.Context) {
 	logger.ActInfo("Order placed successfully through checkout")
 	ctx.JSON(http.StatusOK, order)
 }
+
+func (c *CheckoutController) GetShippingOptions(ctx *gin.Context) {
+	logger.ActInfo("Fetching shipping options")
+
+	// Extract Keycloak user ID from token claims
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	// Shipping is quoted to a saved address given as address_id, or to a country
+	var addressId *uint
+	if param := strings.TrimSpace(ctx.Query("address_id")); param != "" {
+		id, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: "Invalid address_id",
+				Details:          err.Error(),
+			})
+			return
+		}
+		parsed := uint(id)
+		addressId = &parsed
+	}
+
+	options, err := c.ShippingService.GetShippingOptions(claims.Sub, addressId, ctx.Query("country"), requestCurrency(ctx))
+	if err != nil {
+		logger.ActError("Failed to fetch shipping options", zap.Error(err))
+		switch {
+		case strings.Contains(err.Error(), "address not found"):
+			ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+				Error:            "Not Found",
+				ErrorDescription: err.Error(),
+			})
+		case strings.Contains(err.Error(), "is required"),
+			strings.Contains(err.Error(), "no items selected"),
+			isCurrencyError(err):
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: err.Error(),
+			})
+		default:
+			ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+				Error:            "Internal Server Error",
+				ErrorDescription: "Failed to fetch shipping options",
+				Details:          err.Error(),
+			})
+		}
+		return
+	}
+	logger.ActInfo("Shipping options fetched successfully")
+	ctx.JSON(http.StatusOK, options)
+}
+
+// Quote prices the checkout without placing the order, a payment method is not needed yet
+func (c *CheckoutController) Quote(ctx *gin.Context) {
+	logger.ActInfo("Quoting checkout")
+
+	// Extract Keycloak user ID from token claims
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	// Bind request body
+	var req data.PlaceOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {payment_method?: string, address_id?: number, address?: {country: string, region?: string, ...}, shipping_method_id?: number}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	// Shipping and tax depend on the country, a saved address already has one
+	if req.AddressID == nil && (req.Address == nil || strings.TrimSpace(req.Address.Country) == "") {
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "An address_id or address country is required",
+		})
+		return
+	}
+
+	quote, err := c.CheckoutService.QuoteOrder(claims.Sub, req, requestCurrency(ctx))
+	if err != nil {
+		logger.ActError("Failed to quote checkout", zap.Error(err))
+		if err.Error() == "cart not found" || err.Error() == "cart is empty" || err.Error() == "no items selected for checkout" || isCurrencyError(err) {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: err.Error(),
+			})
+		} else if strings.Contains(err.Error(), "address not found") {
+			ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+				Error:            "Not Found",
+				ErrorDescription: err.Error(),
+			})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+				Error:            "Internal Server Error",
+				ErrorDescription: "Failed to quote checkout",
+				Details:          err.Error(),
+			})
+		}
+		return
+	}
+
+	logger.ActInfo("Checkout quoted successfully")
+	ctx.JSON(http.StatusOK, quote)
+}
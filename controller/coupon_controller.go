@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"net/http"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type CouponController struct {
+	PromotionService service.PromotionService
+}
+
+func NewCouponController(PromotionService service.PromotionService) *CouponController {
+	return &CouponController{
+		PromotionService: PromotionService,
+	}
+}
+
+func (c *CouponController) GetAllCoupons(ctx *gin.Context) {
+	logger.ActInfo("Fetching all coupons")
+	coupons, err := c.PromotionService.GetAllCoupons()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: "Failed to fetch the coupons",
+			Details:          err.Error(),
+		})
+		return
+	}
+	logger.ActInfo("Coupons fetched successfully")
+	ctx.JSON(http.StatusOK, coupons)
+}
+
+func (c *CouponController) CreateCoupon(ctx *gin.Context) {
+	logger.ActInfo("Creating coupon")
+
+	var req data.CreateCouponRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {code: string, discount_type: PERCENTAGE|FIXED, discount_value: number, ...}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	coupon, err := c.PromotionService.CreateCoupon(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "failed to create coupon") {
+			ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+				Error:            "Internal Server Error",
+				ErrorDescription: "Failed to create coupon",
+				Details:          err.Error(),
+			})
+		} else {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: err.Error(),
+			})
+		}
+		return
+	}
+	logger.ActInfo("Coupon created successfully")
+	ctx.JSON(http.StatusOK, coupon)
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"net/http"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CurrencyHeader selects the currency prices are returned and charged in, the currency query parameter does the same
+const CurrencyHeader = "X-Currency"
+
+type CurrencyController struct {
+	CurrencyService service.CurrencyService
+}
+
+func NewCurrencyController(CurrencyService service.CurrencyService) *CurrencyController {
+	return &CurrencyController{
+		CurrencyService: CurrencyService,
+	}
+}
+
+func (c *CurrencyController) GetExchangeRates(ctx *gin.Context) {
+	logger.ActInfo("Fetching exchange rates")
+	ctx.JSON(http.StatusOK, c.CurrencyService.GetRates())
+}
+
+func (c *CurrencyController) UpdateExchangeRates(ctx *gin.Context) {
+	logger.ActInfo("Updating exchange rates")
+
+	var req data.UpdateExchangeRatesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {rates: {currency: number}}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	rates, err := c.CurrencyService.UpdateRates(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "failed to save exchange rates") {
+			ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+				Error:            "Internal Server Error",
+				ErrorDescription: "Failed to update exchange rates",
+				Details:          err.Error(),
+			})
+		} else {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: err.Error(),
+			})
+		}
+		return
+	}
+	logger.ActInfo("Exchange rates updated successfully")
+	ctx.JSON(http.StatusOK, rates)
+}
+
+// requestCurrency reads the currency selected for the request, empty when none was selected
+func requestCurrency(ctx *gin.Context) string {
+	if currency := strings.TrimSpace(ctx.GetHeader(CurrencyHeader)); currency != "" {
+		return currency
+	}
+	return strings.TrimSpace(ctx.Query("currency"))
+}
+
+// isCurrencyError reports whether the error is caused by an unsupported currency
+func isCurrencyError(err error) bool {
+	return strings.Contains(err.Error(), "unsupported currency")
+}
@@ -6,6 +6,7 @@ import (
 	"shophub-backend/data"
 	"shophub-backend/logger"
 	"shophub-backend/service"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -80,3 +81,36 @@ func (c *OrderController) GetOrderByUser(ctx *gin.Context) {
 	logger.ActInfo("Orders fetched successfully")
 	ctx.JSON(http.StatusOK, orders)
 }
+
+func (c *OrderController) MarkDelivered(ctx *gin.Context) {
+	logger.ActInfo("Marking order as delivered")
+	orderId, ok := parseIdParam(ctx, "orderId")
+	if !ok {
+		return
+	}
+
+	order, err := c.OrderService.MarkDelivered(orderId)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+				Error:            "Not Found",
+				ErrorDescription: err.Error(),
+			})
+		case strings.Contains(err.Error(), "failed to"):
+			ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+				Error:            "Internal Server Error",
+				ErrorDescription: "Failed to mark the order as delivered",
+				Details:          err.Error(),
+			})
+		default:
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: err.Error(),
+			})
+		}
+		return
+	}
+	logger.ActInfo("Order marked as delivered successfully")
+	ctx.JSON(http.StatusOK, order)
+}
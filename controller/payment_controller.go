@@ -1,11 +1,13 @@
 package controller
 
 import (
+	"shophub-backend/auth"
 	"shophub-backend/data"
 	"shophub-backend/logger"
 	"shophub-backend/service"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -97,6 +99,13 @@ func (c *PaymentController) ProcessPayment(ctx *gin.Context) {
 
 	payment, err := c.PaymentService.ProcessPayment(uint(orderId), req.PaymentMethod)
 	if err != nil {
+		if strings.Contains(err.Error(), "stock reservation") {
+			ctx.JSON(http.StatusConflict, data.ErrorResponse{
+				Error:            "Conflict",
+				ErrorDescription: err.Error(),
+			})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
 			Error:            "Internal Server Error",
 			ErrorDescription: "Failed to process payment",
@@ -109,3 +118,46 @@ func (c *PaymentController) ProcessPayment(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, payment)
 
 }
+
+// FailPayment records a failed payment on one of the user's pending orders, the order is cancelled and its stock given back
+func (c *PaymentController) FailPayment(ctx *gin.Context) {
+	logger.ActInfo("Recording failed payment")
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+	orderId, ok := parseIdParam(ctx, "orderId")
+	if !ok {
+		return
+	}
+
+	payment, err := c.PaymentService.FailPayment(claims.Sub, orderId)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+				Error:            "Not Found",
+				ErrorDescription: err.Error(),
+			})
+		case strings.Contains(err.Error(), "already been paid"), strings.Contains(err.Error(), "only pending orders"):
+			ctx.JSON(http.StatusConflict, data.ErrorResponse{
+				Error:            "Conflict",
+				ErrorDescription: err.Error(),
+			})
+		default:
+			ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+				Error:            "Internal Server Error",
+				ErrorDescription: "Failed to record failed payment",
+				Details:          err.Error(),
+			})
+		}
+		return
+	}
+
+	logger.ActInfo("Failed payment recorded")
+	ctx.JSON(http.StatusOK, payment)
+}
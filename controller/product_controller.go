@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 type ProductController struct {
@@ -23,8 +24,19 @@ func NewProductController(ProductService service.ProductService) *ProductControl
 
 func (c *ProductController) GetAllProducts(ctx *gin.Context) {
 	logger.ActInfo("Fetching all products")
-	products, err := c.ProductService.GetAllProducts()
+	query, ok := productListQuery(ctx)
+	if !ok {
+		return
+	}
+	products, err := c.ProductService.GetAllProducts(requestCurrency(ctx), query)
 	if err != nil {
+		if isCurrencyError(err) || strings.Contains(err.Error(), "invalid filter") || strings.Contains(err.Error(), "invalid sort") {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: err.Error(),
+			})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
 			Error:            "Internal Server Error",
 			ErrorDescription: "Failed to fetch the products",
@@ -59,8 +71,15 @@ func (c *ProductController) GetProductById(ctx *gin.Context) {
 		})
 		return
 	}
-	product, err := c.ProductService.GetProductById(uint(id))
+	product, err := c.ProductService.GetProductById(uint(id), requestCurrency(ctx))
 	if err != nil {
+		if isCurrencyError(err) {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: err.Error(),
+			})
+			return
+		}
 		ctx.JSON(http.StatusNotFound, data.ErrorResponse{
 			Error:            "Bad Request",
 			ErrorDescription: "Product not found",
@@ -84,8 +103,22 @@ func (c *ProductController) GetProductBySlug(ctx *gin.Context) {
 		return
 	}
 
-	products, err := c.ProductService.GetProductBySlug(slugParam)
+	products, err := c.ProductService.GetProductBySlug(slugParam, requestCurrency(ctx))
 	if err != nil {
+		if isCurrencyError(err) {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: err.Error(),
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+				Error:            "Not Found",
+				ErrorDescription: err.Error(),
+			})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
 			Error:            "Internal server error",
 			ErrorDescription: "Failed to fetch the products",
@@ -96,3 +129,123 @@ func (c *ProductController) GetProductBySlug(ctx *gin.Context) {
 	logger.ActInfo("Products fetched successfully")
 	ctx.JSON(http.StatusOK, products)
 }
+
+// GetStockHistory lists every recorded change to the product's stock, newest first
+func (c *ProductController) GetStockHistory(ctx *gin.Context) {
+	logger.ActInfo("Fetching product stock history")
+	productId, ok := parseIdParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	movements, err := c.ProductService.GetStockHistory(productId)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+				Error:            "Not Found",
+				ErrorDescription: err.Error(),
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: "Failed to fetch the stock history",
+			Details:          err.Error(),
+		})
+		return
+	}
+	logger.ActInfo("Product stock history fetched successfully")
+	ctx.JSON(http.StatusOK, movements)
+}
+
+func (c *ProductController) UpdateProductStatus(ctx *gin.Context) {
+	logger.ActInfo("Updating product status")
+	productId, ok := parseIdParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req data.UpdateProductStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {status: DRAFT|ACTIVE|ARCHIVED, publish_at?: datetime, unpublish_at?: datetime}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	product, err := c.ProductService.UpdateProductStatus(productId, req)
+	if err != nil {
+		respondProductError(ctx, "Failed to update the product status", err)
+		return
+	}
+	logger.ActInfo("Product status updated successfully")
+	ctx.JSON(http.StatusOK, product)
+}
+
+func (c *ProductController) DeleteProduct(ctx *gin.Context) {
+	logger.ActInfo("Deleting product")
+	productId, ok := parseIdParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.ProductService.DeleteProduct(productId); err != nil {
+		respondProductError(ctx, "Failed to delete the product", err)
+		return
+	}
+	logger.ActInfo("Product deleted successfully")
+	ctx.JSON(http.StatusOK, data.MessageResponse{Message: "Product deleted successfully"})
+}
+
+func respondProductError(ctx *gin.Context, description string, err error) {
+	logger.ActError(description, zap.Error(err))
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+			Error:            "Not Found",
+			ErrorDescription: err.Error(),
+		})
+	case strings.Contains(err.Error(), "failed to"):
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: description,
+			Details:          err.Error(),
+		})
+	default:
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: err.Error(),
+		})
+	}
+}
+
+// productListQuery reads the category, the attribute filters and the order of the product listing,
+// such as ?category_id=2&filter[storage]=64,128&filter[colour]=Black&sort=rating
+func productListQuery(ctx *gin.Context) (data.ProductListQuery, bool) {
+	query := data.ProductListQuery{
+		Filters: map[string][]string{},
+		Sort:    strings.TrimSpace(ctx.Query("sort")),
+	}
+
+	if categoryParam := strings.TrimSpace(ctx.Query("category_id")); categoryParam != "" {
+		categoryId, err := strconv.ParseUint(categoryParam, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: "Invalid category ID",
+				Details:          err.Error(),
+			})
+			return query, false
+		}
+		id := uint(categoryId)
+		query.CategoryID = &id
+	}
+
+	for code, values := range ctx.QueryMap("filter") {
+		query.Filters[code] = strings.Split(values, ",")
+	}
+	return query, true
+}
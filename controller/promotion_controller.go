@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"net/http"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type PromotionController struct {
+	PromotionService service.PromotionService
+}
+
+func NewPromotionController(PromotionService service.PromotionService) *PromotionController {
+	return &PromotionController{
+		PromotionService: PromotionService,
+	}
+}
+
+func (c *PromotionController) GetAllPromotions(ctx *gin.Context) {
+	logger.ActInfo("Fetching all promotions")
+	promotions, err := c.PromotionService.GetAllPromotions()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: "Failed to fetch the promotions",
+			Details:          err.Error(),
+		})
+		return
+	}
+	logger.ActInfo("Promotions fetched successfully")
+	ctx.JSON(http.StatusOK, promotions)
+}
+
+func (c *PromotionController) CreatePromotion(ctx *gin.Context) {
+	logger.ActInfo("Creating promotion")
+
+	var req data.CreatePromotionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {name: string, type: BUY_X_GET_Y|BUNDLE|TIERED_SPEND, priority: number, ...}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	promotion, err := c.PromotionService.CreatePromotion(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "failed to create promotion") {
+			ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+				Error:            "Internal Server Error",
+				ErrorDescription: "Failed to create promotion",
+				Details:          err.Error(),
+			})
+		} else {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: err.Error(),
+			})
+		}
+		return
+	}
+	logger.ActInfo("Promotion created successfully")
+	ctx.JSON(http.StatusOK, promotion)
+}
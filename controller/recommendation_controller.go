@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"net/http"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/service"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type RecommendationController struct {
+	RecommendationService service.RecommendationService
+}
+
+func NewRecommendationController(RecommendationService service.RecommendationService) *RecommendationController {
+	return &RecommendationController{
+		RecommendationService: RecommendationService,
+	}
+}
+
+func (c *RecommendationController) GetRecommendations(ctx *gin.Context) {
+	logger.ActInfo("Fetching product recommendations")
+	productId, ok := parseIdParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if limitParam := strings.TrimSpace(ctx.Query("limit")); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: "Invalid limit",
+				Details:          err.Error(),
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	recommendations, err := c.RecommendationService.GetRecommendations(productId, limit, requestCurrency(ctx))
+	if err != nil {
+		logger.ActError("Failed to fetch the recommendations", zap.Error(err))
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+				Error:            "Not Found",
+				ErrorDescription: err.Error(),
+			})
+		case isCurrencyError(err) || strings.Contains(err.Error(), "limit must be"):
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: err.Error(),
+			})
+		default:
+			ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+				Error:            "Internal Server Error",
+				ErrorDescription: "Failed to fetch the recommendations",
+				Details:          err.Error(),
+			})
+		}
+		return
+	}
+	logger.ActInfo("Product recommendations fetched successfully")
+	ctx.JSON(http.StatusOK, recommendations)
+}
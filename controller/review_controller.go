@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"net/http"
+	"shophub-backend/auth"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type ReviewController struct {
+	ReviewService service.ReviewService
+}
+
+func NewReviewController(ReviewService service.ReviewService) *ReviewController {
+	return &ReviewController{
+		ReviewService: ReviewService,
+	}
+}
+
+func (c *ReviewController) CreateReview(ctx *gin.Context) {
+	logger.ActInfo("Creating product review")
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+	productId, ok := parseIdParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req data.CreateReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {rating: 1-5, title: string, body: string}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	review, err := c.ReviewService.CreateReview(claims.Sub, productId, req)
+	if err != nil {
+		respondReviewError(ctx, "Failed to create the review", err)
+		return
+	}
+	logger.ActInfo("Product review created successfully")
+	ctx.JSON(http.StatusOK, review)
+}
+
+func (c *ReviewController) GetProductReviews(ctx *gin.Context) {
+	logger.ActInfo("Fetching product reviews")
+	productId, ok := parseIdParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	reviews, err := c.ReviewService.GetProductReviews(productId)
+	if err != nil {
+		respondReviewError(ctx, "Failed to fetch the reviews", err)
+		return
+	}
+	logger.ActInfo("Product reviews fetched successfully")
+	ctx.JSON(http.StatusOK, reviews)
+}
+
+func (c *ReviewController) GetReviews(ctx *gin.Context) {
+	logger.ActInfo("Fetching reviews for moderation")
+	reviews, err := c.ReviewService.GetReviews(ctx.Query("status"))
+	if err != nil {
+		respondReviewError(ctx, "Failed to fetch the reviews", err)
+		return
+	}
+	logger.ActInfo("Reviews fetched successfully")
+	ctx.JSON(http.StatusOK, reviews)
+}
+
+func (c *ReviewController) ModerateReview(ctx *gin.Context) {
+	logger.ActInfo("Moderating review")
+	reviewId, ok := parseIdParam(ctx, "reviewId")
+	if !ok {
+		return
+	}
+
+	var req data.ModerateReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {status: APPROVED|REJECTED}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	review, err := c.ReviewService.ModerateReview(reviewId, req)
+	if err != nil {
+		respondReviewError(ctx, "Failed to moderate the review", err)
+		return
+	}
+	logger.ActInfo("Review moderated successfully")
+	ctx.JSON(http.StatusOK, review)
+}
+
+func respondReviewError(ctx *gin.Context, description string, err error) {
+	logger.ActError(description, zap.Error(err))
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+			Error:            "Not Found",
+			ErrorDescription: err.Error(),
+		})
+	case strings.Contains(err.Error(), "only customers who received"):
+		ctx.JSON(http.StatusForbidden, data.ErrorResponse{
+			Error:            "Forbidden",
+			ErrorDescription: err.Error(),
+		})
+	case strings.Contains(err.Error(), "already reviewed"):
+		ctx.JSON(http.StatusConflict, data.ErrorResponse{
+			Error:            "Conflict",
+			ErrorDescription: err.Error(),
+		})
+	case strings.Contains(err.Error(), "failed to"):
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: description,
+			Details:          err.Error(),
+		})
+	default:
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: err.Error(),
+		})
+	}
+}
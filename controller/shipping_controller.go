@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"net/http"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type ShippingController struct {
+	ShippingService service.ShippingService
+}
+
+func NewShippingController(ShippingService service.ShippingService) *ShippingController {
+	return &ShippingController{
+		ShippingService: ShippingService,
+	}
+}
+
+func (c *ShippingController) GetAllZones(ctx *gin.Context) {
+	logger.ActInfo("Fetching all shipping zones")
+	zones, err := c.ShippingService.GetAllZones()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: "Failed to fetch the shipping zones",
+			Details:          err.Error(),
+		})
+		return
+	}
+	logger.ActInfo("Shipping zones fetched successfully")
+	ctx.JSON(http.StatusOK, zones)
+}
+
+func (c *ShippingController) CreateZone(ctx *gin.Context) {
+	logger.ActInfo("Creating shipping zone")
+
+	var req data.CreateShippingZoneRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {name: string, is_default: boolean, countries: [string]}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	zone, err := c.ShippingService.CreateZone(req)
+	if err != nil {
+		respondShippingError(ctx, "Failed to create shipping zone", err)
+		return
+	}
+	logger.ActInfo("Shipping zone created successfully")
+	ctx.JSON(http.StatusOK, zone)
+}
+
+func (c *ShippingController) CreateMethod(ctx *gin.Context) {
+	logger.ActInfo("Creating shipping method")
+
+	zoneId, ok := parseIdParam(ctx, "zoneId")
+	if !ok {
+		return
+	}
+
+	var req data.CreateShippingMethodRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {name: string, type: FLAT_RATE|WEIGHT_BASED|FREE_OVER_THRESHOLD, base_rate: number, ...}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	method, err := c.ShippingService.CreateMethod(zoneId, req)
+	if err != nil {
+		respondShippingError(ctx, "Failed to create shipping method", err)
+		return
+	}
+	logger.ActInfo("Shipping method created successfully")
+	ctx.JSON(http.StatusOK, method)
+}
+
+func respondShippingError(ctx *gin.Context, description string, err error) {
+	logger.ActError(description, zap.Error(err))
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+			Error:            "Not Found",
+			ErrorDescription: err.Error(),
+		})
+	case strings.Contains(err.Error(), "failed to"):
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: description,
+			Details:          err.Error(),
+		})
+	default:
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: err.Error(),
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"net/http"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/service"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type StockAlertController struct {
+	StockAlertService service.StockAlertService
+}
+
+func NewStockAlertController(StockAlertService service.StockAlertService) *StockAlertController {
+	return &StockAlertController{
+		StockAlertService: StockAlertService,
+	}
+}
+
+func (c *StockAlertController) GetLowStockReport(ctx *gin.Context) {
+	logger.ActInfo("Fetching low stock report")
+
+	// Optional override of the configured sales velocity window
+	var windowDays int
+	if daysParam := ctx.Query("days"); daysParam != "" {
+		days, err := strconv.Atoi(daysParam)
+		if err != nil || days <= 0 {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: "days must be a positive number",
+			})
+			return
+		}
+		windowDays = days
+	}
+
+	report, err := c.StockAlertService.GetLowStockReport(windowDays)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: "Failed to fetch the low stock report",
+			Details:          err.Error(),
+		})
+		return
+	}
+	logger.ActInfo("Low stock report fetched successfully")
+	ctx.JSON(http.StatusOK, report)
+}
+
+func (c *StockAlertController) SetReorderThreshold(ctx *gin.Context) {
+	logger.ActInfo("Updating reorder threshold")
+	productId, ok := parseIdParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req data.UpdateReorderThresholdRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {reorder_threshold: number|null}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	product, err := c.StockAlertService.SetReorderThreshold(productId, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+				Error:            "Not Found",
+				ErrorDescription: err.Error(),
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: "Failed to update the reorder threshold",
+			Details:          err.Error(),
+		})
+		return
+	}
+	logger.ActInfo("Reorder threshold updated successfully")
+	ctx.JSON(http.StatusOK, product)
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"net/http"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type TaxController struct {
+	TaxService service.TaxService
+}
+
+func NewTaxController(TaxService service.TaxService) *TaxController {
+	return &TaxController{
+		TaxService: TaxService,
+	}
+}
+
+func (c *TaxController) GetAllTaxRates(ctx *gin.Context) {
+	logger.ActInfo("Fetching all tax rates")
+	rates, err := c.TaxService.GetAllTaxRates()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: "Failed to fetch the tax rates",
+			Details:          err.Error(),
+		})
+		return
+	}
+	logger.ActInfo("Tax rates fetched successfully")
+	ctx.JSON(http.StatusOK, rates)
+}
+
+func (c *TaxController) CreateTaxRate(ctx *gin.Context) {
+	logger.ActInfo("Creating tax rate")
+
+	var req data.CreateTaxRateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {name: string, country: string, region?: string, category_id?: number, rate: number, inclusive: boolean}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	rate, err := c.TaxService.CreateTaxRate(req)
+	if err != nil {
+		logger.ActError("Failed to create tax rate", zap.Error(err))
+		if strings.Contains(err.Error(), "unknown country") {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: err.Error(),
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: "Failed to create tax rate",
+			Details:          err.Error(),
+		})
+		return
+	}
+	logger.ActInfo("Tax rate created successfully")
+	ctx.JSON(http.StatusOK, rate)
+}
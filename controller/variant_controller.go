@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"net/http"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type VariantController struct {
+	VariantService service.VariantService
+}
+
+func NewVariantController(VariantService service.VariantService) *VariantController {
+	return &VariantController{
+		VariantService: VariantService,
+	}
+}
+
+func (c *VariantController) CreateOption(ctx *gin.Context) {
+	logger.ActInfo("Creating product option")
+	productId, ok := parseIdParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req data.CreateProductOptionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {name: string, values: string[]}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	option, err := c.VariantService.CreateOption(productId, req)
+	if err != nil {
+		respondVariantError(ctx, "Failed to create the product option", err)
+		return
+	}
+	logger.ActInfo("Product option created successfully")
+	ctx.JSON(http.StatusOK, option)
+}
+
+func (c *VariantController) CreateVariant(ctx *gin.Context) {
+	logger.ActInfo("Creating product variant")
+	productId, ok := parseIdParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req data.CreateVariantRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {sku: string, option_value_ids: number[], price_override?: number, image_urls?: string[], is_active?: boolean}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	variant, err := c.VariantService.CreateVariant(productId, req)
+	if err != nil {
+		respondVariantError(ctx, "Failed to create the product variant", err)
+		return
+	}
+	logger.ActInfo("Product variant created successfully")
+	ctx.JSON(http.StatusOK, variant)
+}
+
+func (c *VariantController) UpdateVariant(ctx *gin.Context) {
+	logger.ActInfo("Updating product variant")
+	productId, ok := parseIdParam(ctx, "id")
+	if !ok {
+		return
+	}
+	variantId, ok := parseIdParam(ctx, "variantId")
+	if !ok {
+		return
+	}
+
+	var req data.UpdateVariantRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {sku: string, price_override?: number, image_urls?: string[], is_active?: boolean}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	variant, err := c.VariantService.UpdateVariant(productId, variantId, req)
+	if err != nil {
+		respondVariantError(ctx, "Failed to update the product variant", err)
+		return
+	}
+	logger.ActInfo("Product variant updated successfully")
+	ctx.JSON(http.StatusOK, variant)
+}
+
+func respondVariantError(ctx *gin.Context, description string, err error) {
+	logger.ActError(description, zap.Error(err))
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+			Error:            "Not Found",
+			ErrorDescription: err.Error(),
+		})
+	case strings.Contains(err.Error(), "failed to"):
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: description,
+			Details:          err.Error(),
+		})
+	default:
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: err.Error(),
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"net/http"
+	"shophub-backend/auth"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type WarehouseController struct {
+	WarehouseService service.WarehouseService
+}
+
+func NewWarehouseController(WarehouseService service.WarehouseService) *WarehouseController {
+	return &WarehouseController{
+		WarehouseService: WarehouseService,
+	}
+}
+
+func (c *WarehouseController) GetAllWarehouses(ctx *gin.Context) {
+	logger.ActInfo("Fetching all warehouses")
+	warehouses, err := c.WarehouseService.GetAllWarehouses()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: "Failed to fetch the warehouses",
+			Details:          err.Error(),
+		})
+		return
+	}
+	logger.ActInfo("Warehouses fetched successfully")
+	ctx.JSON(http.StatusOK, warehouses)
+}
+
+func (c *WarehouseController) CreateWarehouse(ctx *gin.Context) {
+	logger.ActInfo("Creating warehouse")
+
+	var req data.CreateWarehouseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {code: string, name: string, country?: string, is_active?: boolean}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	warehouse, err := c.WarehouseService.CreateWarehouse(req)
+	if err != nil {
+		respondWarehouseError(ctx, "Failed to create warehouse", err)
+		return
+	}
+	logger.ActInfo("Warehouse created successfully")
+	ctx.JSON(http.StatusOK, warehouse)
+}
+
+func (c *WarehouseController) GetWarehouseStock(ctx *gin.Context) {
+	logger.ActInfo("Fetching warehouse stock")
+	warehouseId, ok := parseIdParam(ctx, "warehouseId")
+	if !ok {
+		return
+	}
+
+	levels, err := c.WarehouseService.GetWarehouseStock(warehouseId)
+	if err != nil {
+		respondWarehouseError(ctx, "Failed to fetch warehouse stock", err)
+		return
+	}
+	logger.ActInfo("Warehouse stock fetched successfully")
+	ctx.JSON(http.StatusOK, levels)
+}
+
+func (c *WarehouseController) AdjustStock(ctx *gin.Context) {
+	logger.ActInfo("Adjusting warehouse stock")
+	warehouseId, ok := parseIdParam(ctx, "warehouseId")
+	if !ok {
+		return
+	}
+	productId, ok := parseIdParam(ctx, "productId")
+	if !ok {
+		return
+	}
+
+	var req data.AdjustStockRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {delta: number, variant_id?: number, reason?: ADJUSTMENT|RETURN|CANCELLATION, reference?: string}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	// The admin making the change is recorded in the stock ledger
+	actor := ""
+	if claims := auth.GetClaims(ctx); claims != nil {
+		actor = claims.Sub
+	}
+
+	level, err := c.WarehouseService.AdjustStock(warehouseId, productId, actor, req)
+	if err != nil {
+		respondWarehouseError(ctx, "Failed to adjust stock", err)
+		return
+	}
+	logger.ActInfo("Warehouse stock adjusted successfully")
+	ctx.JSON(http.StatusOK, level)
+}
+
+func respondWarehouseError(ctx *gin.Context, description string, err error) {
+	logger.ActError(description, zap.Error(err))
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+			Error:            "Not Found",
+			ErrorDescription: err.Error(),
+		})
+	case strings.Contains(err.Error(), "failed to"):
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: description,
+			Details:          err.Error(),
+		})
+	default:
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: err.Error(),
+		})
+	}
+}
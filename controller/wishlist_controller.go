@@ -0,0 +1,307 @@
+package controller
+
+import (
+	"net/http"
+	"shophub-backend/auth"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/service"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type WishlistController struct {
+	WishlistService service.WishlistService
+}
+
+func NewWishlistController(WishlistService service.WishlistService) *WishlistController {
+	return &WishlistController{
+		WishlistService: WishlistService,
+	}
+}
+
+func (c *WishlistController) GetUserWishlists(ctx *gin.Context) {
+	logger.ActInfo("Fetching user wishlists")
+
+	// Extract Keycloak user ID from token claims
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	wishlists, err := c.WishlistService.GetUserWishlists(claims.Sub)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: "Failed to fetch user wishlists",
+			Details:          err.Error(),
+		})
+		return
+	}
+	logger.ActInfo("User wishlists fetched successfully")
+	ctx.JSON(http.StatusOK, wishlists)
+}
+
+func (c *WishlistController) CreateWishlist(ctx *gin.Context) {
+	logger.ActInfo("Creating wishlist")
+
+	// Extract Keycloak user ID from token claims
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	var req data.CreateWishlistRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {name: string}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	wishlist, err := c.WishlistService.CreateWishlist(claims.Sub, strings.TrimSpace(req.Name))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: "Failed to create wishlist",
+			Details:          err.Error(),
+		})
+		return
+	}
+	logger.ActInfo("Wishlist created successfully")
+	ctx.JSON(http.StatusOK, wishlist)
+}
+
+func (c *WishlistController) DeleteWishlist(ctx *gin.Context) {
+	logger.ActInfo("Deleting wishlist")
+
+	// Extract Keycloak user ID from token claims
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	wishlistId, ok := parseIdParam(ctx, "wishlistId")
+	if !ok {
+		return
+	}
+
+	if err := c.WishlistService.DeleteWishlist(claims.Sub, wishlistId); err != nil {
+		respondWishlistError(ctx, "Failed to delete wishlist", err)
+		return
+	}
+	logger.ActInfo("Wishlist deleted successfully")
+	ctx.JSON(http.StatusOK, data.MessageResponse{
+		Message: "Wishlist deleted successfully",
+	})
+}
+
+func (c *WishlistController) AddToWishlist(ctx *gin.Context) {
+	logger.ActInfo("Adding item to the wishlist")
+
+	// Extract Keycloak user ID from token claims
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	wishlistId, ok := parseIdParam(ctx, "wishlistId")
+	if !ok {
+		return
+	}
+
+	var req data.AddToWishlistRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.ActError("Failed to bind request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid request body. Expected: {product_id: number}",
+			Details:          err.Error(),
+		})
+		return
+	}
+
+	if err := c.WishlistService.AddToWishlist(claims.Sub, wishlistId, req.ProductID); err != nil {
+		respondWishlistError(ctx, "Failed to add item to the wishlist", err)
+		return
+	}
+	logger.ActInfo("Item added to the wishlist successfully")
+	ctx.JSON(http.StatusOK, data.MessageResponse{
+		Message: "Item added to the wishlist successfully",
+	})
+}
+
+func (c *WishlistController) RemoveFromWishlist(ctx *gin.Context) {
+	logger.ActInfo("Removing item from the wishlist")
+
+	// Extract Keycloak user ID from token claims
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	wishlistId, ok := parseIdParam(ctx, "wishlistId")
+	if !ok {
+		return
+	}
+	itemId, ok := parseIdParam(ctx, "itemId")
+	if !ok {
+		return
+	}
+
+	if err := c.WishlistService.RemoveFromWishlist(claims.Sub, wishlistId, itemId); err != nil {
+		respondWishlistError(ctx, "Failed to remove the item from the wishlist", err)
+		return
+	}
+	logger.ActInfo("Item removed from the wishlist successfully")
+	ctx.JSON(http.StatusOK, data.MessageResponse{
+		Message: "Item removed from the wishlist successfully",
+	})
+}
+
+func (c *WishlistController) MoveToCart(ctx *gin.Context) {
+	logger.ActInfo("Moving wishlist item to the cart")
+
+	// Extract Keycloak user ID from token claims
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	wishlistId, ok := parseIdParam(ctx, "wishlistId")
+	if !ok {
+		return
+	}
+	itemId, ok := parseIdParam(ctx, "itemId")
+	if !ok {
+		return
+	}
+
+	if err := c.WishlistService.MoveToCart(claims.Sub, wishlistId, itemId); err != nil {
+		respondWishlistError(ctx, "Failed to move the item to the cart", err)
+		return
+	}
+	logger.ActInfo("Wishlist item moved to the cart successfully")
+	ctx.JSON(http.StatusOK, data.MessageResponse{
+		Message: "Item moved to the cart successfully",
+	})
+}
+
+func (c *WishlistController) SaveForLater(ctx *gin.Context) {
+	logger.ActInfo("Saving cart item for later")
+
+	// Extract Keycloak user ID from token claims
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Sub == "" {
+		ctx.JSON(http.StatusUnauthorized, data.ErrorResponse{
+			Error:            "unauthorized",
+			ErrorDescription: "User not authenticated or missing user ID in token",
+		})
+		return
+	}
+
+	cartItemId, ok := parseIdParam(ctx, "cartItemId")
+	if !ok {
+		return
+	}
+
+	// The body is optional, an empty body saves to the default wishlist
+	var req data.SaveForLaterRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+				Error:            "Bad Request",
+				ErrorDescription: "Invalid request body. Expected: {wishlist_id?: number}",
+				Details:          err.Error(),
+			})
+			return
+		}
+	}
+
+	if err := c.WishlistService.SaveForLater(claims.Sub, cartItemId, req.WishlistID); err != nil {
+		respondWishlistError(ctx, "Failed to save the item for later", err)
+		return
+	}
+	logger.ActInfo("Cart item saved for later successfully")
+	ctx.JSON(http.StatusOK, data.MessageResponse{
+		Message: "Item saved for later successfully",
+	})
+}
+
+// parseIdParam reads a numeric path parameter, writing a bad request response when it is invalid
+func parseIdParam(ctx *gin.Context, name string) (uint, bool) {
+	param := strings.TrimSpace(ctx.Param(name))
+	if param == "" {
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Missing " + name + " path parameter",
+		})
+		return 0, false
+	}
+
+	id, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: "Invalid " + name,
+			Details:          err.Error(),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func respondWishlistError(ctx *gin.Context, description string, err error) {
+	logger.ActError(description, zap.Error(err))
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		ctx.JSON(http.StatusNotFound, data.ErrorResponse{
+			Error:            "Not Found",
+			ErrorDescription: err.Error(),
+		})
+	case strings.Contains(err.Error(), "insufficient stock"),
+		strings.Contains(err.Error(), "variant is required"),
+		strings.Contains(err.Error(), "not available"),
+		strings.Contains(err.Error(), "cannot be deleted"):
+		ctx.JSON(http.StatusBadRequest, data.ErrorResponse{
+			Error:            "Bad Request",
+			ErrorDescription: err.Error(),
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, data.ErrorResponse{
+			Error:            "Internal Server Error",
+			ErrorDescription: description,
+			Details:          err.Error(),
+		})
+	}
+}
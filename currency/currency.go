@@ -0,0 +1,146 @@
+package currency
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"shophub-backend/money"
+	"sort"
+	"strings"
+)
+
+// Table holds exchange rates as units of each currency per one unit of the base currency,
+// the base currency itself always has a rate of 1
+type Table struct {
+	Base  string
+	rates map[string]float64
+}
+
+// NewTable creates a rate table, rates that are not positive are ignored
+func NewTable(base string, rates map[string]float64) *Table {
+	base = Normalize(base)
+	table := &Table{
+		Base:  base,
+		rates: map[string]float64{base: 1},
+	}
+	for code, rate := range rates {
+		code = Normalize(code)
+		if code == base || rate <= 0 {
+			continue
+		}
+		table.rates[code] = rate
+	}
+	return table
+}
+
+// Normalize makes currency codes case-insensitive
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// IsValidCode checks that the code looks like an ISO 4217 code
+func IsValidCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *Table) Supports(code string) bool {
+	_, ok := t.rates[Normalize(code)]
+	return ok
+}
+
+// Codes returns the supported currencies in alphabetical order
+func (t *Table) Codes() []string {
+	codes := make([]string, 0, len(t.rates))
+	for code := range t.rates {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Rates returns a copy of the rates, including the base currency
+func (t *Table) Rates() map[string]float64 {
+	rates := make(map[string]float64, len(t.rates))
+	for code, rate := range t.rates {
+		rates[code] = rate
+	}
+	return rates
+}
+
+// Rate is the number of units of the target currency for one unit of the source currency
+func (t *Table) Rate(from string, to string) (float64, error) {
+	from, to = Normalize(from), Normalize(to)
+	if from == to {
+		return 1, nil
+	}
+	fromRate, ok := t.rates[from]
+	if !ok {
+		return 0, fmt.Errorf("unsupported currency %s", from)
+	}
+	toRate, ok := t.rates[to]
+	if !ok {
+		return 0, fmt.Errorf("unsupported currency %s", to)
+	}
+	return toRate / fromRate, nil
+}
+
+// Convert converts the amount to the target currency, rounding to the nearest minor unit.
+// The rate that was used is returned so it can be recorded.
+func (t *Table) Convert(amount money.Money, to string) (money.Money, float64, error) {
+	from := amount.Currency
+	if from == "" {
+		from = t.Base
+	}
+	rate, err := t.Rate(from, to)
+	if err != nil {
+		return money.Money{}, 0, err
+	}
+	return amount.Exchange(rate, Normalize(to)), rate, nil
+}
+
+// rateFile is the format of the exchange rate file:
+//
+//	{"base": "USD", "rates": {"EUR": 0.92, "GBP": 0.79}}
+type rateFile struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// LoadFile reads a rate table from a JSON file
+func LoadFile(path string) (*Table, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file rateFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("invalid exchange rate file %s: %w", path, err)
+	}
+	if !IsValidCode(Normalize(file.Base)) {
+		return nil, fmt.Errorf("invalid base currency %q in exchange rate file %s", file.Base, path)
+	}
+	return NewTable(file.Base, file.Rates), nil
+}
+
+// Rebase expresses the table relative to another of its currencies
+func (t *Table) Rebase(base string) (*Table, error) {
+	base = Normalize(base)
+	rates := make(map[string]float64, len(t.rates))
+	for code := range t.rates {
+		rate, err := t.Rate(base, code)
+		if err != nil {
+			return nil, err
+		}
+		rates[code] = rate
+	}
+	return NewTable(base, rates), nil
+}
@@ -1,14 +1,35 @@
 package data
 
+import (
+	"shophub-backend/catalog"
+	"shophub-backend/facet"
+	"shophub-backend/model"
+	"shophub-backend/money"
+	"shophub-backend/postal"
+	"shophub-backend/promotion"
+	"shophub-backend/shipping"
+	"shophub-backend/tax"
+	"time"
+)
+
 type ErrorResponse struct {
 	Error            string `json:"error"`
 	ErrorDescription string `json:"error_description,omitempty"`
 	Details          string `json:"details,omitempty"`
 }
 
+// ValidationErrorResponse reports every invalid field of the request
+type ValidationErrorResponse struct {
+	Error            string              `json:"error"`
+	ErrorDescription string              `json:"error_description,omitempty"`
+	Fields           []postal.FieldError `json:"fields"`
+}
+
 type AddToCartRequest struct {
 	ProductID uint `json:"product_id" binding:"required"`
 	Quantity  int  `json:"quantity" binding:"required,min=1"`
+	// VariantID is required for a product sold as variants
+	VariantID *uint `json:"variant_id"`
 	// Legacy fields - ignored but kept for backward compatibility
 	UserID uint `json:"user_id,omitempty" binding:"-"`
 	CartID uint `json:"cart_id,omitempty" binding:"-"`
@@ -18,6 +39,50 @@ type UpdateCartItemQuantityRequest struct {
 	Quantity int `json:"quantity"`
 }
 
+type UpdateCartItemSelectionRequest struct {
+	IsSelected *bool `json:"is_selected" binding:"required"`
+}
+
+// Cart Item Response Struct, priced at the current product price in the cart currency
+type CartItemResponse struct {
+	model.CartItem
+	CurrentPrice      money.Money `json:"current_price"`
+	CurrentTotalPrice money.Money `json:"current_total_price"`
+	DiscountAmount    money.Money `json:"discount_amount"`
+	AvailableStock    int         `json:"available_stock"`
+	PriceChanged      bool        `json:"price_changed"`
+	InsufficientStock bool        `json:"insufficient_stock"`
+	// Unavailable items can no longer be ordered, their product was archived, unpublished or deleted
+	Unavailable bool `json:"unavailable"`
+}
+
+// Cart Response Struct, selected totals only cover the items selected for checkout.
+// Tax is only calculated once a shipping address is chosen, the total includes the tax that is not already in the prices.
+type CartResponse struct {
+	CartID              uint                         `json:"cart_id"`
+	KeycloakUserID      string                       `json:"keycloak_user_id"`
+	Currency            string                       `json:"currency"`
+	Items               []CartItemResponse           `json:"cart_items"`
+	ItemCount           int                          `json:"item_count"`
+	Subtotal            money.Money                  `json:"subtotal"`
+	SelectedItemCount   int                          `json:"selected_item_count"`
+	SelectedSubtotal    money.Money                  `json:"selected_subtotal"`
+	CouponCode          string                       `json:"coupon_code,omitempty"`
+	CouponError         string                       `json:"coupon_error,omitempty"`
+	AppliedPromotions   []promotion.AppliedPromotion `json:"applied_promotions"`
+	AutomaticDiscount   money.Money                  `json:"automatic_discount"`
+	CouponDiscount      money.Money                  `json:"coupon_discount"`
+	DiscountAmount      money.Money                  `json:"discount_amount"`
+	AddressID           *uint                        `json:"address_id,omitempty"`
+	TaxCalculated       bool                         `json:"tax_calculated"`
+	TaxLines            []tax.TaxLine                `json:"tax_lines"`
+	TaxAmount           money.Money                  `json:"tax_amount"`
+	Total               money.Money                  `json:"total"`
+	HasPriceChanges     bool                         `json:"has_price_changes"`
+	HasStockIssues      bool                         `json:"has_stock_issues"`
+	HasUnavailableItems bool                         `json:"has_unavailable_items"`
+}
+
 type MessageResponse struct {
 	Message string `json:"message"`
 }
@@ -62,22 +127,369 @@ type IntrospectResponse struct {
 	Active            bool          `json:"active"`
 }
 
+// Wishlist Request Structs
+type CreateWishlistRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=100"`
+}
+
+type AddToWishlistRequest struct {
+	ProductID uint `json:"product_id" binding:"required"`
+}
+
+type SaveForLaterRequest struct {
+	// Optional, the default wishlist is used when not provided
+	WishlistID uint `json:"wishlist_id"`
+}
+
+// Coupon Request Structs
+type ApplyCouponRequest struct {
+	Code string `json:"code" binding:"required,min=1,max=50"`
+}
+
+type CreateCouponRequest struct {
+	Code              string      `json:"code" binding:"required,min=3,max=50"`
+	Description       string      `json:"description" binding:"max=250"`
+	DiscountType      string      `json:"discount_type" binding:"required,oneof=PERCENTAGE FIXED"`
+	DiscountValue     float64     `json:"discount_value" binding:"required,gt=0"`
+	MaxDiscountAmount money.Money `json:"max_discount_amount"`
+	MinOrderValue     money.Money `json:"min_order_value"`
+	UsageLimit        int         `json:"usage_limit" binding:"gte=0"`
+	PerUserLimit      int         `json:"per_user_limit" binding:"gte=0"`
+	StartsAt          *time.Time  `json:"starts_at"`
+	EndsAt            *time.Time  `json:"ends_at"`
+	ProductIDs        []uint      `json:"product_ids"`
+	CategoryIDs       []uint      `json:"category_ids"`
+}
+
+// Promotion Request Structs
+type PromotionTierRequest struct {
+	MinSpend      money.Money `json:"min_spend"`
+	DiscountType  string      `json:"discount_type" binding:"required,oneof=PERCENTAGE FIXED"`
+	DiscountValue float64     `json:"discount_value" binding:"required,gt=0"`
+}
+
+type CreatePromotionRequest struct {
+	Name               string                 `json:"name" binding:"required,min=1,max=150"`
+	Description        string                 `json:"description" binding:"max=250"`
+	Type               string                 `json:"type" binding:"required,oneof=BUY_X_GET_Y BUNDLE TIERED_SPEND"`
+	Priority           int                    `json:"priority"`
+	Exclusive          bool                   `json:"exclusive"`
+	BuyQuantity        int                    `json:"buy_quantity" binding:"gte=0"`
+	GetQuantity        int                    `json:"get_quantity" binding:"gte=0"`
+	GetDiscountPercent float64                `json:"get_discount_percent" binding:"gte=0"`
+	BundlePrice        money.Money            `json:"bundle_price"`
+	StartsAt           *time.Time             `json:"starts_at"`
+	EndsAt             *time.Time             `json:"ends_at"`
+	ProductIDs         []uint                 `json:"product_ids"`
+	CategoryIDs        []uint                 `json:"category_ids"`
+	Tiers              []PromotionTierRequest `json:"tiers" binding:"dive"`
+}
+
 // Payment Request Struct
 type ProcessPaymentRequest struct {
 	PaymentMethod string `json:"payment_method"`
 }
 
 // Address Request Struct
+// Required fields and formats depend on the country, see the postal package
 type CreateAddressRequest struct {
-	Line1      string `json:"line1" binding:"required,min=1,max=200"`
-	Line2      string `json:"line2" binding:"required,min=1,max=200"`
-	City       string `json:"city" binding:"required"`
-	PostalCode string `json:"postal_code" binding:"required,min=1,max=100"`
-	Country    string `json:"country" binding:"required,min=1,max=100"`
+	Label      string `json:"label" binding:"max=50"`
+	Line1      string `json:"line1" binding:"max=200"`
+	Line2      string `json:"line2" binding:"max=200"`
+	City       string `json:"city" binding:"max=100"`
+	PostalCode string `json:"postal_code" binding:"max=100"`
+	Country    string `json:"country" binding:"max=100"`
+	Region     string `json:"region" binding:"max=100"`
 }
 
-// Place Order Request Struct
+// Place Order Request Struct, the shipping address is a saved address_id or a new address.
+// The billing address defaults to the shipping address.
 type PlaceOrderRequest struct {
-	PaymentMethod string               `json:"payment_method"`
-	Address       CreateAddressRequest `json:"address"`
+	PaymentMethod    string                `json:"payment_method"`
+	AddressID        *uint                 `json:"address_id"`
+	Address          *CreateAddressRequest `json:"address"`
+	BillingAddressID *uint                 `json:"billing_address_id"`
+	BillingAddress   *CreateAddressRequest `json:"billing_address"`
+	SaveAddress      bool                  `json:"save_address"`
+	ShippingMethodID uint                  `json:"shipping_method_id"`
+}
+
+// Abandoned Cart Report Structs
+type AbandonedCartSummary struct {
+	CartID         uint        `json:"cart_id"`
+	KeycloakUserID string      `json:"keycloak_user_id"`
+	ItemCount      int         `json:"item_count"`
+	CartValue      money.Money `json:"cart_value"`
+	LastActivityAt time.Time   `json:"last_activity_at"`
+	ReminderSentAt *time.Time  `json:"reminder_sent_at"`
+}
+
+type AbandonedCartReport struct {
+	ThresholdMinutes int                    `json:"threshold_minutes"`
+	NonEmptyCarts    int64                  `json:"non_empty_carts"`
+	AbandonedCarts   int                    `json:"abandoned_carts"`
+	AbandonmentRate  float64                `json:"abandonment_rate"`
+	RemindersSent    int                    `json:"reminders_sent"`
+	AbandonedValue   money.Money            `json:"abandoned_value"`
+	GeneratedAt      time.Time              `json:"generated_at"`
+	Carts            []AbandonedCartSummary `json:"carts"`
+}
+
+// Currency Structs
+type ExchangeRatesResponse struct {
+	Base       string             `json:"base"`
+	Currencies []string           `json:"currencies"`
+	Rates      map[string]float64 `json:"rates"`
+}
+
+type UpdateExchangeRatesRequest struct {
+	Rates map[string]float64 `json:"rates" binding:"required,min=1"`
+}
+
+// Product Response Struct, with the price converted to the requested currency
+type ProductResponse struct {
+	model.Product
+	DisplayCurrency string      `json:"display_currency"`
+	DisplayPrice    money.Money `json:"display_price"`
+	ExchangeRate    float64     `json:"exchange_rate"`
+	// AvailableStock is the stock that is not reserved for unpaid orders
+	AvailableStock int `json:"available_stock"`
+	// Variants replaces the product's variants with the active ones, priced in the display currency
+	Variants []VariantResponse `json:"variants,omitempty"`
+}
+
+type VariantResponse struct {
+	model.ProductVariant
+	Name           string      `json:"name"`
+	DisplayPrice   money.Money `json:"display_price"`
+	AvailableStock int         `json:"available_stock"`
+}
+
+// CreateProductOptionRequest adds an option such as size with its values in display order
+type CreateProductOptionRequest struct {
+	Name   string   `json:"name" binding:"required,min=1,max=50"`
+	Values []string `json:"values" binding:"required,min=1,dive,min=1,max=50"`
+}
+
+// CreateVariantRequest adds a variant with one value of each of the product's options.
+// Without a price override the variant is sold at the product price.
+type CreateVariantRequest struct {
+	SKU            string       `json:"sku" binding:"required,min=1,max=100"`
+	OptionValueIDs []uint       `json:"option_value_ids" binding:"required,min=1"`
+	PriceOverride  *money.Money `json:"price_override"`
+	ImageURLs      []string     `json:"image_urls" binding:"dive,min=1,max=500"`
+	IsActive       *bool        `json:"is_active"`
+}
+
+// UpdateVariantRequest replaces the SKU, price override, images and status of a variant,
+// a null price override sells the variant at the product price
+type UpdateVariantRequest struct {
+	SKU           string       `json:"sku" binding:"required,min=1,max=100"`
+	PriceOverride *money.Money `json:"price_override"`
+	ImageURLs     []string     `json:"image_urls" binding:"dive,min=1,max=500"`
+	IsActive      *bool        `json:"is_active"`
+}
+
+// Product listing Structs. Filters are the chosen values per attribute code, a product matches when it
+// has one of the chosen values of every filtered attribute. Sort is empty for the default order or
+// rating for the best rated products first.
+type ProductListQuery struct {
+	CategoryID *uint
+	Filters    map[string][]string
+	Sort       string
+}
+
+// ProductListResponse has the matching products and the facets to narrow them down further.
+// The counts of a facet are the products that would match with that value chosen instead.
+type ProductListResponse struct {
+	Products []ProductResponse `json:"products"`
+	Facets   []facet.Facet     `json:"facets"`
+	Total    int               `json:"total"`
+}
+
+// CreateAttributeRequest defines an attribute for the products of a category. Values lists the allowed
+// values of an ENUM attribute in display order, other types take any value of their type.
+type CreateAttributeRequest struct {
+	Code         string   `json:"code" binding:"required,min=1,max=50"`
+	Name         string   `json:"name" binding:"required,min=1,max=100"`
+	Type         string   `json:"type" binding:"required,oneof=TEXT NUMBER BOOLEAN ENUM"`
+	Unit         string   `json:"unit" binding:"max=20"`
+	IsFilterable *bool    `json:"is_filterable"`
+	Position     int      `json:"position"`
+	Values       []string `json:"values" binding:"dive,min=1,max=100"`
+}
+
+// SetProductAttributesRequest replaces the attribute values of a product, by attribute code
+type SetProductAttributesRequest struct {
+	Values map[string]string `json:"values" binding:"required"`
+}
+
+// UpdateProductStatusRequest replaces the status and publishing schedule of a product, a draft with
+// a publish time is published once that time has come
+type UpdateProductStatusRequest struct {
+	Status      string     `json:"status" binding:"required,oneof=DRAFT ACTIVE ARCHIVED"`
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
+}
+
+// ProductImportReport is the outcome of a CSV import. Nothing is imported when any row is invalid,
+// a dry run only validates. Created and Updated count the rows that were, or on a dry run would be, imported.
+type ProductImportReport struct {
+	DryRun  bool               `json:"dry_run"`
+	Applied bool               `json:"applied"`
+	Rows    int                `json:"rows"`
+	Created int                `json:"created"`
+	Updated int                `json:"updated"`
+	Errors  []catalog.RowError `json:"errors"`
+}
+
+// Recommendation Structs. Reason is frequently_bought_together, with Count the number of orders the
+// products were bought together in, or category_bestseller, with Count the units sold.
+type Recommendation struct {
+	ProductResponse
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
+}
+
+type RecommendationResponse struct {
+	ProductID       uint             `json:"product_id"`
+	Recommendations []Recommendation `json:"recommendations"`
+}
+
+// Review Structs, reviews are published once a moderator approves them
+type CreateReviewRequest struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Title  string `json:"title" binding:"required,min=1,max=150"`
+	Body   string `json:"body" binding:"required,min=1,max=5000"`
+}
+
+type ModerateReviewRequest struct {
+	Status string `json:"status" binding:"required,oneof=APPROVED REJECTED"`
+}
+
+// Tax Rate Request Struct, a rate without a region or category applies to the whole country
+type CreateTaxRateRequest struct {
+	Name       string  `json:"name" binding:"required,min=1,max=100"`
+	Country    string  `json:"country" binding:"required,min=1,max=100"`
+	Region     string  `json:"region" binding:"max=100"`
+	CategoryID *uint   `json:"category_id"`
+	Rate       float64 `json:"rate" binding:"gte=0,lte=100"`
+	Inclusive  bool    `json:"inclusive"`
+}
+
+// Shipping Structs
+type ShippingOptionsResponse struct {
+	AddressID *uint             `json:"address_id,omitempty"`
+	Country   string            `json:"country"`
+	Currency  string            `json:"currency"`
+	WeightKg  float64           `json:"weight_kg"`
+	Subtotal  money.Money       `json:"subtotal"`
+	Options   []shipping.Option `json:"options"`
+}
+
+type CreateShippingZoneRequest struct {
+	Name      string   `json:"name" binding:"required,min=1,max=100"`
+	IsDefault bool     `json:"is_default"`
+	Countries []string `json:"countries"`
+}
+
+type CreateShippingMethodRequest struct {
+	Name            string      `json:"name" binding:"required,min=1,max=100"`
+	Type            string      `json:"type" binding:"required,oneof=FLAT_RATE WEIGHT_BASED FREE_OVER_THRESHOLD"`
+	BaseRate        money.Money `json:"base_rate"`
+	RatePerKg       money.Money `json:"rate_per_kg"`
+	FreeThreshold   money.Money `json:"free_threshold"`
+	MaxWeightKg     float64     `json:"max_weight_kg" binding:"gte=0"`
+	MinDeliveryDays int         `json:"min_delivery_days" binding:"gte=0"`
+	MaxDeliveryDays int         `json:"max_delivery_days" binding:"gte=0"`
+}
+
+// Checkout Quote Structs, the quote is priced exactly like the order would be
+type CheckoutQuoteLine struct {
+	CartItemID     uint        `json:"cart_item_id"`
+	ProductID      uint        `json:"product_id"`
+	ProductName    string      `json:"product_name"`
+	VariantID      *uint       `json:"variant_id,omitempty"`
+	VariantName    string      `json:"variant_name,omitempty"`
+	Quantity       int         `json:"quantity"`
+	UnitPrice      money.Money `json:"unit_price"`
+	Subtotal       money.Money `json:"subtotal"`
+	DiscountAmount money.Money `json:"discount_amount"`
+	TaxAmount      money.Money `json:"tax_amount"`
+	ShippingCost   money.Money `json:"shipping_cost"`
+	Total          money.Money `json:"total"`
+}
+
+type CheckoutQuoteResponse struct {
+	Currency          string                       `json:"currency"`
+	Lines             []CheckoutQuoteLine          `json:"lines"`
+	Subtotal          money.Money                  `json:"subtotal"`
+	AppliedPromotions []promotion.AppliedPromotion `json:"applied_promotions"`
+	AutomaticDiscount money.Money                  `json:"automatic_discount"`
+	CouponCode        string                       `json:"coupon_code,omitempty"`
+	CouponDiscount    money.Money                  `json:"coupon_discount"`
+	DiscountAmount    money.Money                  `json:"discount_amount"`
+	ShippingMethod    *shipping.Option             `json:"shipping_method"`
+	ShippingOptions   []shipping.Option            `json:"shipping_options"`
+	ShippingCost      money.Money                  `json:"shipping_cost"`
+	TaxLines          []tax.TaxLine                `json:"tax_lines"`
+	TaxAmount         money.Money                  `json:"tax_amount"`
+	IncludedTaxAmount money.Money                  `json:"included_tax_amount"`
+	GrandTotal        money.Money                  `json:"grand_total"`
+	CanPlaceOrder     bool                         `json:"can_place_order"`
+	Issues            []string                     `json:"issues"`
+}
+
+// Warehouse Structs
+type CreateWarehouseRequest struct {
+	Code     string `json:"code" binding:"required,min=1,max=50"`
+	Name     string `json:"name" binding:"required,min=1,max=100"`
+	Country  string `json:"country" binding:"max=100"`
+	IsActive *bool  `json:"is_active"`
+}
+
+// AdjustStockRequest changes the stock by Delta, negative to remove stock. VariantID is required for a product
+// sold as variants. Reason defaults to ADJUSTMENT, Reference is free text such as a return or purchase order number.
+type AdjustStockRequest struct {
+	Delta     int    `json:"delta"`
+	VariantID uint   `json:"variant_id"`
+	Reason    string `json:"reason" binding:"omitempty,oneof=ADJUSTMENT RETURN CANCELLATION"`
+	Reference string `json:"reference" binding:"max=100"`
+}
+
+// UpdateReorderThresholdRequest sets the stock at or below which the product is low on stock,
+// a null threshold uses the store's default
+type UpdateReorderThresholdRequest struct {
+	ReorderThreshold *int `json:"reorder_threshold" binding:"omitempty,min=0"`
+}
+
+// LowStockProduct is a product at or below its reorder threshold. SalesVelocity is the average
+// units sold per day over the report window, DaysOfCover how long the available stock lasts at that rate.
+type LowStockProduct struct {
+	ProductID        uint     `json:"product_id"`
+	ProductName      string   `json:"product_name"`
+	ProductSlug      string   `json:"product_slug"`
+	AvailableStock   int      `json:"available_stock"`
+	ReorderThreshold int      `json:"reorder_threshold"`
+	Status           string   `json:"status"`
+	UnitsSold        int      `json:"units_sold"`
+	SalesVelocity    float64  `json:"sales_velocity"`
+	DaysOfCover      *float64 `json:"days_of_cover"`
+}
+
+type LowStockReport struct {
+	WindowDays  int               `json:"window_days"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Products    []LowStockProduct `json:"products"`
+}
+
+// StockDiscrepancy is recorded stock that differs from the sum of its stock movements, WarehouseID is
+// nil for the product's total stock
+type StockDiscrepancy struct {
+	ProductID   uint  `json:"product_id"`
+	VariantID   uint  `json:"variant_id"`
+	WarehouseID *uint `json:"warehouse_id"`
+	Recorded    int   `json:"recorded"`
+	Ledger      int   `json:"ledger"`
+	Difference  int   `json:"difference"`
 }
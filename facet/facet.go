@@ -0,0 +1,207 @@
+package facet
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	TypeText    = "TEXT"
+	TypeNumber  = "NUMBER"
+	TypeBoolean = "BOOLEAN"
+	TypeEnum    = "ENUM"
+)
+
+// Attribute is a filterable attribute, attributes of different categories with the same code share a facet
+type Attribute struct {
+	Code     string
+	Name     string
+	Type     string
+	Unit     string
+	Position int
+	// AllowedValues are the values of an enum attribute in display order
+	AllowedValues []string
+}
+
+// Item is a product with its attribute values by attribute code
+type Item struct {
+	ID     uint
+	Values map[string]string
+}
+
+type Value struct {
+	Value    string `json:"value"`
+	Count    int    `json:"count"`
+	Selected bool   `json:"selected"`
+}
+
+// Facet is the values of an attribute with the number of items that have each value
+type Facet struct {
+	Code   string  `json:"code"`
+	Name   string  `json:"name"`
+	Type   string  `json:"type"`
+	Unit   string  `json:"unit,omitempty"`
+	Values []Value `json:"values"`
+}
+
+// Selection is the values chosen per attribute code. An item matches when it has one of the chosen
+// values of every attribute in the selection.
+type Selection map[string][]string
+
+// Matches is true when the item has one of the selected values of every attribute except the skipped one
+func (s Selection) Matches(item Item, skip string) bool {
+	for code, values := range s {
+		if code == skip || len(values) == 0 {
+			continue
+		}
+		value, ok := item.Values[code]
+		if !ok || !contains(values, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the items that match the selection
+func Filter(items []Item, selection Selection) []Item {
+	var matched []Item
+	for _, item := range items {
+		if selection.Matches(item, "") {
+			matched = append(matched, item)
+		}
+	}
+	return matched
+}
+
+// Compute counts the values of every attribute over the items. The counts of an attribute ignore
+// the selection on that attribute itself, so choosing 64GB still shows how many items have 128GB.
+// Values that no item has are left out, except for selected ones.
+func Compute(attributes []Attribute, items []Item, selection Selection) []Facet {
+	ordered := make([]Attribute, len(attributes))
+	copy(ordered, attributes)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Position < ordered[j].Position
+	})
+
+	facets := []Facet{}
+	for _, attribute := range ordered {
+		counts := map[string]int{}
+		for _, item := range items {
+			value, ok := item.Values[attribute.Code]
+			if !ok || !selection.Matches(item, attribute.Code) {
+				continue
+			}
+			counts[value]++
+		}
+		for _, value := range selection[attribute.Code] {
+			if !hasValue(counts, value) {
+				counts[value] = 0
+			}
+		}
+		if len(counts) == 0 {
+			continue
+		}
+
+		facet := Facet{Code: attribute.Code, Name: attribute.Name, Type: attribute.Type, Unit: attribute.Unit}
+		for value, count := range counts {
+			facet.Values = append(facet.Values, Value{
+				Value:    value,
+				Count:    count,
+				Selected: contains(selection[attribute.Code], value),
+			})
+		}
+		sortValues(attribute, facet.Values)
+		facets = append(facets, facet)
+	}
+	return facets
+}
+
+// Normalize checks a value against the attribute type and returns it in its canonical form,
+// numbers without trailing zeros, booleans as true or false and enum values as they are defined
+func Normalize(attribute Attribute, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("value of %s must not be empty", attribute.Code)
+	}
+
+	switch attribute.Type {
+	case TypeNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("value of %s must be a number", attribute.Code)
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	case TypeBoolean:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("value of %s must be true or false", attribute.Code)
+		}
+		return strconv.FormatBool(boolean), nil
+	case TypeEnum:
+		for _, allowed := range attribute.AllowedValues {
+			if strings.EqualFold(allowed, value) {
+				return allowed, nil
+			}
+		}
+		return "", fmt.Errorf("value of %s must be one of %s", attribute.Code, strings.Join(attribute.AllowedValues, ", "))
+	default:
+		return value, nil
+	}
+}
+
+// IsValidType is true for the supported attribute types
+func IsValidType(attributeType string) bool {
+	switch attributeType {
+	case TypeText, TypeNumber, TypeBoolean, TypeEnum:
+		return true
+	}
+	return false
+}
+
+// sortValues orders numbers numerically, enum values as defined and other values alphabetically
+func sortValues(attribute Attribute, values []Value) {
+	position := map[string]int{}
+	for i, allowed := range attribute.AllowedValues {
+		position[allowed] = i
+	}
+
+	sort.SliceStable(values, func(i, j int) bool {
+		a, b := values[i].Value, values[j].Value
+		switch attribute.Type {
+		case TypeNumber:
+			x, errX := strconv.ParseFloat(a, 64)
+			y, errY := strconv.ParseFloat(b, 64)
+			if errX == nil && errY == nil {
+				return x < y
+			}
+		case TypeEnum:
+			x, okX := position[a]
+			y, okY := position[b]
+			if okX && okY {
+				return x < y
+			}
+		}
+		return strings.ToLower(a) < strings.ToLower(b)
+	})
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasValue is true when the counts have the value, in any case
+func hasValue(counts map[string]int, value string) bool {
+	for counted := range counts {
+		if strings.EqualFold(counted, value) {
+			return true
+		}
+	}
+	return false
+}
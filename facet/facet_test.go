@@ -0,0 +1,200 @@
+package facet
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var attributes = []Attribute{
+	{Code: "waterproof", Name: "Waterproof", Type: TypeBoolean, Position: 4},
+	{Code: "storage", Name: "Storage", Type: TypeNumber, Unit: "GB", Position: 2},
+	{Code: "brand", Name: "Brand", Type: TypeText, Position: 1},
+	{Code: "color", Name: "Color", Type: TypeEnum, Position: 3, AllowedValues: []string{"Red", "Green", "Blue"}},
+	{Code: "size", Name: "Size", Type: TypeText, Position: 5},
+}
+
+var items = []Item{
+	{ID: 1, Values: map[string]string{"brand": "Acme", "storage": "64", "color": "Red", "waterproof": "true"}},
+	{ID: 2, Values: map[string]string{"brand": "Acme", "storage": "128", "color": "Blue"}},
+	{ID: 3, Values: map[string]string{"brand": "Zeta", "storage": "64", "color": "Red"}},
+	{ID: 4, Values: map[string]string{"brand": "Zeta", "storage": "256"}},
+}
+
+// describe writes a facet as code=value:count,... with selected values marked by a star
+func describe(facets []Facet) []string {
+	described := []string{}
+	for _, facet := range facets {
+		values := make([]string, len(facet.Values))
+		for i, value := range facet.Values {
+			values[i] = fmt.Sprintf("%s:%d", value.Value, value.Count)
+			if value.Selected {
+				values[i] += "*"
+			}
+		}
+		described = append(described, facet.Code+"="+strings.Join(values, ","))
+	}
+	return described
+}
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection Selection
+		want      []string
+	}{
+		{
+			name: "no selection counts every item in attribute position order",
+			want: []string{"brand=Acme:2,Zeta:2", "storage=64:2,128:1,256:1", "color=Red:2,Blue:1", "waterproof=true:1"},
+		},
+		{
+			name:      "selection on an attribute does not narrow its own counts",
+			selection: Selection{"storage": {"64"}},
+			want:      []string{"brand=Acme:1,Zeta:1", "storage=64:2*,128:1,256:1", "color=Red:2", "waterproof=true:1"},
+		},
+		{
+			name:      "each attribute is counted against the selection on the others",
+			selection: Selection{"brand": {"Acme"}, "storage": {"64"}},
+			want:      []string{"brand=Acme:1*,Zeta:1", "storage=64:1*,128:1", "color=Red:1", "waterproof=true:1"},
+		},
+		{
+			name:      "values of one attribute are alternatives",
+			selection: Selection{"storage": {"64", "128"}},
+			want:      []string{"brand=Acme:2,Zeta:1", "storage=64:2*,128:1*,256:1", "color=Red:2,Blue:1", "waterproof=true:1"},
+		},
+		{
+			name:      "selected value without items is kept and other attributes without counts are left out",
+			selection: Selection{"color": {"Green"}},
+			want:      []string{"color=Red:2,Green:0*,Blue:1"},
+		},
+		{
+			name:      "selection is case insensitive",
+			selection: Selection{"brand": {"acme"}},
+			want:      []string{"brand=Acme:2*,Zeta:2", "storage=64:1,128:1", "color=Red:1,Blue:1", "waterproof=true:1"},
+		},
+		{
+			name:      "empty selection of an attribute is ignored",
+			selection: Selection{"brand": {}},
+			want:      []string{"brand=Acme:2,Zeta:2", "storage=64:2,128:1,256:1", "color=Red:2,Blue:1", "waterproof=true:1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := describe(Compute(attributes, items, tt.selection))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("facets = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeWithoutItems(t *testing.T) {
+	facets := Compute(attributes, nil, nil)
+	if facets == nil || len(facets) != 0 {
+		t.Errorf("facets = %v, want an empty list", facets)
+	}
+}
+
+func TestComputeDoesNotReorderAttributes(t *testing.T) {
+	Compute(attributes, items, nil)
+
+	if attributes[0].Code != "waterproof" || attributes[4].Code != "size" {
+		t.Errorf("attributes were reordered: %+v", attributes)
+	}
+}
+
+func TestComputeKeepsFacetDetails(t *testing.T) {
+	facets := Compute(attributes, items, nil)
+
+	storage := facets[1]
+	if storage.Name != "Storage" || storage.Type != TypeNumber || storage.Unit != "GB" {
+		t.Errorf("storage facet = %+v", storage)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection Selection
+		want      []uint
+	}{
+		{"no selection", nil, []uint{1, 2, 3, 4}},
+		{"one value", Selection{"brand": {"Zeta"}}, []uint{3, 4}},
+		{"alternative values", Selection{"storage": {"128", "256"}}, []uint{2, 4}},
+		{"every attribute must match", Selection{"brand": {"Acme"}, "color": {"Red"}}, []uint{1}},
+		{"items without the attribute do not match", Selection{"waterproof": {"true"}}, []uint{1}},
+		{"nothing matches", Selection{"color": {"Green"}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []uint
+			for _, item := range Filter(items, tt.selection) {
+				got = append(got, item.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("items = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	number := Attribute{Code: "storage", Type: TypeNumber}
+	boolean := Attribute{Code: "waterproof", Type: TypeBoolean}
+	enum := Attribute{Code: "color", Type: TypeEnum, AllowedValues: []string{"Red", "Blue"}}
+	text := Attribute{Code: "brand", Type: TypeText}
+
+	tests := []struct {
+		name      string
+		attribute Attribute
+		value     string
+		want      string
+		wantErr   bool
+	}{
+		{"number", number, "64", "64", false},
+		{"number without trailing zeros", number, "64.50", "64.5", false},
+		{"number with whitespace", number, " 128 ", "128", false},
+		{"not a number", number, "big", "", true},
+		{"boolean", boolean, "TRUE", "true", false},
+		{"boolean shorthand", boolean, "0", "false", false},
+		{"not a boolean", boolean, "maybe", "", true},
+		{"enum as defined", enum, "red", "Red", false},
+		{"not an enum value", enum, "Green", "", true},
+		{"text as given", text, "  Acme Inc ", "Acme Inc", false},
+		{"empty value", text, "   ", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.attribute, tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Normalize(%q) = %q, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize(%q) failed: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsValidType(t *testing.T) {
+	for _, attributeType := range []string{TypeText, TypeNumber, TypeBoolean, TypeEnum} {
+		if !IsValidType(attributeType) {
+			t.Errorf("IsValidType(%s) = false", attributeType)
+		}
+	}
+	for _, attributeType := range []string{"", "text", "DATE"} {
+		if IsValidType(attributeType) {
+			t.Errorf("IsValidType(%q) = true", attributeType)
+		}
+	}
+}
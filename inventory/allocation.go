@@ -0,0 +1,66 @@
+package inventory
+
+import (
+	"sort"
+	"strings"
+)
+
+// Stock is what a warehouse can still allocate of a product
+type Stock struct {
+	WarehouseID uint
+	Country     string
+	Available   int
+}
+
+// Allocation is the quantity taken from one warehouse
+type Allocation struct {
+	WarehouseID uint
+	Quantity    int
+}
+
+// Allocate picks the warehouses to ship the quantity from. Warehouses in the destination country
+// come first, then the ones with the most stock, so an order is split over as few warehouses as possible.
+// It returns false when the warehouses together do not have enough stock.
+func Allocate(stock []Stock, quantity int, country string) ([]Allocation, bool) {
+	candidates := make([]Stock, 0, len(stock))
+	total := 0
+	for _, s := range stock {
+		if s.Available > 0 {
+			candidates = append(candidates, s)
+			total += s.Available
+		}
+	}
+	if quantity <= 0 || total < quantity {
+		return nil, false
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		iLocal, jLocal := sameCountry(candidates[i].Country, country), sameCountry(candidates[j].Country, country)
+		if iLocal != jLocal {
+			return iLocal
+		}
+		if candidates[i].Available != candidates[j].Available {
+			return candidates[i].Available > candidates[j].Available
+		}
+		return candidates[i].WarehouseID < candidates[j].WarehouseID
+	})
+
+	var allocations []Allocation
+	remaining := quantity
+	for _, candidate := range candidates {
+		take := candidate.Available
+		if take > remaining {
+			take = remaining
+		}
+		allocations = append(allocations, Allocation{WarehouseID: candidate.WarehouseID, Quantity: take})
+		remaining -= take
+		if remaining == 0 {
+			break
+		}
+	}
+	return allocations, true
+}
+
+func sameCountry(a string, b string) bool {
+	return a != "" && strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
@@ -0,0 +1,143 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name     string
+		stock    []Stock
+		quantity int
+		country  string
+		want     []Allocation
+		wantOk   bool
+	}{
+		{
+			name:     "local warehouse before a remote one with more stock",
+			stock:    []Stock{{1, "US", 50}, {2, "DE", 5}},
+			quantity: 3,
+			country:  "DE",
+			want:     []Allocation{{2, 3}},
+			wantOk:   true,
+		},
+		{
+			name:     "country is matched case insensitively",
+			stock:    []Stock{{1, "US", 50}, {2, " de", 5}},
+			quantity: 3,
+			country:  "De ",
+			want:     []Allocation{{2, 3}},
+			wantOk:   true,
+		},
+		{
+			name:     "most stock first without a local warehouse",
+			stock:    []Stock{{1, "US", 5}, {2, "FR", 20}, {3, "GB", 10}},
+			quantity: 4,
+			country:  "JP",
+			want:     []Allocation{{2, 4}},
+			wantOk:   true,
+		},
+		{
+			name:     "warehouse without a country is never local",
+			stock:    []Stock{{1, "", 5}, {2, "US", 3}},
+			quantity: 2,
+			country:  "",
+			want:     []Allocation{{1, 2}},
+			wantOk:   true,
+		},
+		{
+			name:     "ties are broken by warehouse id",
+			stock:    []Stock{{7, "US", 10}, {3, "US", 10}, {5, "US", 10}},
+			quantity: 4,
+			country:  "US",
+			want:     []Allocation{{3, 4}},
+			wantOk:   true,
+		},
+		{
+			name:     "split over local warehouses before remote ones",
+			stock:    []Stock{{1, "US", 100}, {2, "DE", 2}, {3, "DE", 4}},
+			quantity: 10,
+			country:  "DE",
+			want:     []Allocation{{3, 4}, {2, 2}, {1, 4}},
+			wantOk:   true,
+		},
+		{
+			name:     "split over as few warehouses as possible",
+			stock:    []Stock{{1, "US", 3}, {2, "US", 8}, {3, "US", 5}},
+			quantity: 12,
+			country:  "US",
+			want:     []Allocation{{2, 8}, {3, 4}},
+			wantOk:   true,
+		},
+		{
+			name:     "exactly the total stock",
+			stock:    []Stock{{1, "US", 3}, {2, "US", 2}},
+			quantity: 5,
+			country:  "US",
+			want:     []Allocation{{1, 3}, {2, 2}},
+			wantOk:   true,
+		},
+		{
+			name:     "warehouses without stock are skipped",
+			stock:    []Stock{{1, "DE", 0}, {2, "DE", -2}, {3, "US", 4}},
+			quantity: 2,
+			country:  "DE",
+			want:     []Allocation{{3, 2}},
+			wantOk:   true,
+		},
+		{
+			name:     "insufficient total stock",
+			stock:    []Stock{{1, "US", 3}, {2, "DE", 2}},
+			quantity: 6,
+			country:  "US",
+		},
+		{
+			name:     "negative stock does not count towards the total",
+			stock:    []Stock{{1, "US", 5}, {2, "US", -3}},
+			quantity: 5,
+			country:  "US",
+			want:     []Allocation{{1, 5}},
+			wantOk:   true,
+		},
+		{
+			name:     "no warehouses",
+			quantity: 1,
+			country:  "US",
+		},
+		{
+			name:     "zero quantity",
+			stock:    []Stock{{1, "US", 5}},
+			quantity: 0,
+			country:  "US",
+		},
+		{
+			name:     "negative quantity",
+			stock:    []Stock{{1, "US", 5}},
+			quantity: -1,
+			country:  "US",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Allocate(tt.stock, tt.quantity, tt.country)
+			if ok != tt.wantOk {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocateDoesNotReorderStock(t *testing.T) {
+	stock := []Stock{{1, "US", 1}, {2, "DE", 9}}
+
+	Allocate(stock, 5, "DE")
+
+	if stock[0].WarehouseID != 1 || stock[1].WarehouseID != 2 {
+		t.Errorf("stock was reordered: %v", stock)
+	}
+}
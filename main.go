@@ -8,8 +8,11 @@ import (
 	"shophub-backend/database"
 	"shophub-backend/logger"
 	"shophub-backend/migration"
+	"shophub-backend/money"
+	"shophub-backend/notification"
 	"shophub-backend/repository"
 	"shophub-backend/router"
+	"shophub-backend/scheduler"
 	"shophub-backend/service"
 	"shophub-backend/utils"
 	"strconv"
@@ -41,15 +44,44 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	cfg := config.LoadConfig()
+
 	//Load Origins
-	processAllowedOrigins()
+	processAllowedOrigins(cfg)
 
 	logger.AppInfo("Loading database configurations")
 	pgDb := database.InitDB()
 
+	// Prices are exact decimal amounts in the configured currency
+	money.DefaultCurrency = cfg.DefaultCurrency
+
+	if err := migration.ConvertMoneyColumns(pgDb); err != nil {
+		logger.AppError("Money column conversion failed", zap.Error(err))
+	}
+	if err := migration.AddCurrencyColumns(pgDb); err != nil {
+		logger.AppError("Currency column migration failed", zap.Error(err))
+	}
+	if err := migration.AddColumns(pgDb); err != nil {
+		logger.AppError("Column migration failed", zap.Error(err))
+	}
+	if err := migration.DropIndexes(pgDb); err != nil {
+		logger.AppError("Index migration failed", zap.Error(err))
+	}
 	if err := migration.Migrate(pgDb); err != nil {
 		logger.AppError("Migration failed", zap.Error(err))
 	}
+	if err := migration.BackfillCarts(pgDb); err != nil {
+		logger.AppError("Cart backfill failed", zap.Error(err))
+	}
+	if err := migration.BackfillOrderSnapshots(pgDb); err != nil {
+		logger.AppError("Order snapshot backfill failed", zap.Error(err))
+	}
+	if err := migration.SeedWarehouseStock(pgDb); err != nil {
+		logger.AppError("Warehouse stock seeding failed", zap.Error(err))
+	}
+	if err := migration.BackfillStockMovements(pgDb); err != nil {
+		logger.AppError("Stock movement backfill failed", zap.Error(err))
+	}
 
 	//Initializing the repository files
 	cartRepository := repository.NewCartRepository(pgDb)
@@ -58,50 +90,222 @@ func main() {
 	paymentRepository := repository.NewPaymentRepositoryImpl(pgDb)
 	addressRepository := repository.NewAddressRepository(pgDb)
 	userRepository := repository.NewUserRepository(pgDb)
+	wishlistRepository := repository.NewWishlistRepository(pgDb)
+	couponRepository := repository.NewCouponRepository(pgDb)
+	promotionRepository := repository.NewPromotionRepository(pgDb)
+	exchangeRateRepository := repository.NewExchangeRateRepository(pgDb)
+	taxRepository := repository.NewTaxRepository(pgDb)
+	shippingRepository := repository.NewShippingRepository(pgDb)
+	inventoryRepository := repository.NewInventoryRepository(pgDb)
+	warehouseRepository := repository.NewWarehouseRepository(pgDb)
+	stockMovementRepository := repository.NewStockMovementRepository(pgDb)
+	variantRepository := repository.NewVariantRepository(pgDb)
+	attributeRepository := repository.NewAttributeRepository(pgDb)
+	reviewRepository := repository.NewReviewRepository(pgDb)
+	recommendationRepository := repository.NewRecommendationRepository(pgDb)
+	categoryRepository := repository.NewCategoryRepository(pgDb)
+
+	currencyService, err := service.NewCurrencyServiceImpl(exchangeRateRepository)
+	if err != nil {
+		logger.ActError("Failed to initialize the currency service", zap.Error(err))
+		return
+	}
+	if ratesFile := cfg.ExchangeRatesFile; ratesFile != "" {
+		if err := currencyService.LoadRatesFile(ratesFile); err != nil {
+			logger.AppError("Failed to load the exchange rates file", zap.Error(err))
+		}
+	}
+
+	reservationTTL := time.Duration(cfg.ReservationTTLMinutes) * time.Minute
+	inventoryService, err := service.NewInventoryServiceImpl(inventoryRepository, stockMovementRepository, orderRepository, paymentRepository, reservationTTL)
+	if err != nil {
+		logger.ActError("Failed to initialize the inventory service", zap.Error(err))
+		return
+	}
 
-	cartService, err := service.NewCartServiceImpl(cartRepository, productRepository)
+	warehouseService, err := service.NewWarehouseServiceImpl(warehouseRepository, productRepository)
+	if err != nil {
+		logger.ActError("Failed to initialize the warehouse service", zap.Error(err))
+		return
+	}
+
+	variantService, err := service.NewVariantServiceImpl(variantRepository, productRepository)
+	if err != nil {
+		logger.ActError("Failed to initialize the variant service", zap.Error(err))
+		return
+	}
+
+	attributeService, err := service.NewAttributeServiceImpl(attributeRepository, productRepository)
+	if err != nil {
+		logger.ActError("Failed to initialize the attribute service", zap.Error(err))
+		return
+	}
+
+	reviewService, err := service.NewReviewServiceImpl(reviewRepository, productRepository, orderRepository)
+	if err != nil {
+		logger.ActError("Failed to initialize the review service", zap.Error(err))
+		return
+	}
+
+	catalogService, err := service.NewCatalogServiceImpl(productRepository, categoryRepository, warehouseRepository, currencyService)
+	if err != nil {
+		logger.ActError("Failed to initialize the catalog service", zap.Error(err))
+		return
+	}
+
+	taxService, err := service.NewTaxServiceImpl(taxRepository)
+	if err != nil {
+		logger.ActError("Failed to initialize the tax service", zap.Error(err))
+		return
+	}
+
+	promotionService, err := service.NewPromotionServiceImpl(couponRepository, promotionRepository, currencyService)
+	if err != nil {
+		logger.ActError("Failed to initialize the promotion service", zap.Error(err))
+		return
+	}
+
+	cartService, err := service.NewCartServiceImpl(cartRepository, productRepository, promotionService, currencyService, taxService, addressRepository, inventoryService)
 	if err != nil {
 		logger.ActError("Failed to initialize the cart service", zap.Error(err))
 		return
 	}
 
-	productService, err := service.NewProductServiceImpl(productRepository)
+	productService, err := service.NewProductServiceImpl(productRepository, attributeRepository, currencyService, inventoryService)
 	if err != nil {
 		logger.ActError("Failed to initialize the product service", zap.Error(err))
 		return
 	}
 
-	orderService, err := service.NewOrderServiceImpl(orderRepository, productRepository, cartRepository, paymentRepository)
+	orderService, err := service.NewOrderServiceImpl(orderRepository, productRepository, cartRepository, paymentRepository, inventoryService)
 	if err != nil {
 		logger.ActError("Failed to initialize the order service", zap.Error(err))
 		return
 	}
 
-	paymentService, err := service.NewPaymentServiceImpl(paymentRepository, orderRepository)
+	paymentService, err := service.NewPaymentServiceImpl(paymentRepository, orderRepository, inventoryService)
 	if err != nil {
 		logger.ActError("Failed to initialize the payment service", zap.Error(err))
 		return
 	}
 
-	addressService, err := service.NewAddressServiceImpl(addressRepository)
+	addressService, err := service.NewAddressServiceImpl(addressRepository, cfg.MaxAddressesPerUser)
 	if err != nil {
 		logger.ActError("Failed to initialize the address service", zap.Error(err))
 		return
 	}
 
-	checkoutService, err := service.NewCheckoutServiceImpl(orderRepository, productRepository, cartRepository, paymentRepository, addressRepository, userRepository)
+	shippingService, err := service.NewShippingServiceImpl(shippingRepository, cartRepository, addressRepository, promotionService, currencyService)
+	if err != nil {
+		logger.ActError("Failed to initialize the shipping service", zap.Error(err))
+		return
+	}
+
+	checkoutService, err := service.NewCheckoutServiceImpl(orderRepository, productRepository, cartRepository, paymentRepository, addressRepository, userRepository, addressService, promotionService, currencyService, taxService, shippingService, inventoryService)
 	if err != nil {
 		logger.ActError("Failed to initialize the checkout service", zap.Error(err))
 		return
 	}
 
+	wishlistService, err := service.NewWishlistServiceImpl(wishlistRepository, cartRepository, productRepository, inventoryService)
+	if err != nil {
+		logger.ActError("Failed to initialize the wishlist service", zap.Error(err))
+		return
+	}
+
+	notifier := notification.NewNotifier(cfg.NotifierType, cfg.NotificationFilePath)
+
+	abandonedCartThreshold := time.Duration(cfg.AbandonedCartThresholdMinutes) * time.Minute
+	abandonedCartService, err := service.NewAbandonedCartServiceImpl(cartRepository, currencyService, notifier, abandonedCartThreshold)
+	if err != nil {
+		logger.ActError("Failed to initialize the abandoned cart service", zap.Error(err))
+		return
+	}
+
+	stockAlertService, err := service.NewStockAlertServiceImpl(productRepository, stockMovementRepository, inventoryService, notifier, cfg.LowStockThreshold, cfg.LowStockWindowDays, cfg.StockAlertRecipient)
+	if err != nil {
+		logger.ActError("Failed to initialize the stock alert service", zap.Error(err))
+		return
+	}
+
+	recommendationService, err := service.NewRecommendationServiceImpl(recommendationRepository, productRepository, productService, cfg.RecommendationWindowDays, cfg.RecommendationBasketMinutes, cfg.RecommendationMinPairCount, cfg.RecommendationLimit, cfg.RecommendationMaxLimit)
+	if err != nil {
+		logger.ActError("Failed to initialize the recommendation service", zap.Error(err))
+		return
+	}
+
+	//Starting the background jobs
+	stopAbandonedCartJob := scheduler.Start(
+		"abandoned-cart-reminders",
+		time.Duration(cfg.AbandonedCartCheckIntervalMinutes)*time.Minute,
+		func() error {
+			_, err := abandonedCartService.SendReminders()
+			return err
+		},
+	)
+	defer stopAbandonedCartJob()
+
+	stopReservationSweeper := scheduler.Start(
+		"inventory-reservation-sweeper",
+		time.Duration(cfg.ReservationSweepIntervalMinutes)*time.Minute,
+		func() error {
+			_, err := inventoryService.ReleaseExpired()
+			return err
+		},
+	)
+	defer stopReservationSweeper()
+
+	stopStockAlertJob := scheduler.Start(
+		"low-stock-alerts",
+		time.Duration(cfg.StockAlertIntervalMinutes)*time.Minute,
+		func() error {
+			_, err := stockAlertService.CheckStock()
+			return err
+		},
+	)
+	defer stopStockAlertJob()
+
+	stopPublishingJob := scheduler.Start(
+		"product-publishing",
+		time.Duration(cfg.ProductPublishIntervalMinutes)*time.Minute,
+		func() error {
+			_, err := productService.ApplyPublishingSchedule()
+			return err
+		},
+	)
+	defer stopPublishingJob()
+
+	stopRecommendationJob := scheduler.Start(
+		"product-recommendations",
+		time.Duration(cfg.RecommendationRefreshIntervalMinutes)*time.Minute,
+		func() error {
+			_, err := recommendationService.RefreshCoPurchases()
+			return err
+		},
+	)
+	defer stopRecommendationJob()
+
 	//Initializing the controllers
 	cartController := controller.NewCartController(cartService)
 	productController := controller.NewProductController(productService)
 	orderController := controller.NewOrderController(orderService)
 	paymentController := controller.NewPaymentController(paymentService)
 	addressController := controller.NewAddressController(addressService)
-	checkoutController := controller.NewCheckoutController(checkoutService)
+	checkoutController := controller.NewCheckoutController(checkoutService, shippingService)
+	wishlistController := controller.NewWishlistController(wishlistService)
+	abandonedCartController := controller.NewAbandonedCartController(abandonedCartService)
+	couponController := controller.NewCouponController(promotionService)
+	promotionController := controller.NewPromotionController(promotionService)
+	currencyController := controller.NewCurrencyController(currencyService)
+	taxController := controller.NewTaxController(taxService)
+	shippingController := controller.NewShippingController(shippingService)
+	warehouseController := controller.NewWarehouseController(warehouseService)
+	stockAlertController := controller.NewStockAlertController(stockAlertService)
+	variantController := controller.NewVariantController(variantService)
+	attributeController := controller.NewAttributeController(attributeService)
+	reviewController := controller.NewReviewController(reviewService)
+	recommendationController := controller.NewRecommendationController(recommendationService)
+	catalogController := controller.NewCatalogController(catalogService)
 
 	//Create gin router
 	r := gin.Default()
@@ -113,30 +317,44 @@ func main() {
 	router.RegisterPaymentRoutes(r, paymentController)
 	router.RegisterAddressRoutes(r, addressController)
 	router.RegisterCheckoutRoutes(r, checkoutController)
+	router.RegisterWishlistRoutes(r, wishlistController)
+	router.RegisterAdminReportRoutes(r, abandonedCartController)
+	router.RegisterCouponRoutes(r, couponController)
+	router.RegisterPromotionRoutes(r, promotionController)
+	router.RegisterCurrencyRoutes(r, currencyController)
+	router.RegisterTaxRoutes(r, taxController)
+	router.RegisterShippingRoutes(r, shippingController)
+	router.RegisterWarehouseRoutes(r, warehouseController)
+	router.RegisterStockAlertRoutes(r, stockAlertController)
+	router.RegisterVariantRoutes(r, variantController)
+	router.RegisterAttributeRoutes(r, attributeController)
+	router.RegisterReviewRoutes(r, reviewController)
+	router.RegisterRecommendationRoutes(r, recommendationController)
+	router.RegisterCatalogRoutes(r, catalogController)
 
 	// Enable CORS for all origins
 	corsHandler := cors.New(cors.Options{
 		AllowedOrigins:   allowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-User-ID"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-User-ID", "X-Currency"},
 		AllowCredentials: true,
 	}).Handler(r)
 
 	server := &http.Server{
-		Addr:           ":" + strconv.Itoa(config.LoadConfig().Port),
+		Addr:           ":" + strconv.Itoa(cfg.Port),
 		Handler:        corsHandler,
 		ReadTimeout:    10 * time.Second,
 		WriteTimeout:   10 * time.Second,
 		MaxHeaderBytes: 1 << 20,
 	}
 
-	logger.AppInfo("Server started on port " + strconv.Itoa(config.LoadConfig().Port))
+	logger.AppInfo("Server started on port " + strconv.Itoa(cfg.Port))
 	err = server.ListenAndServe()
 	utils.ErrorPanic(err)
 }
 
-func processAllowedOrigins() {
-	origins := config.LoadConfig().AllowedOrigins
+func processAllowedOrigins(cfg *config.Config) {
+	origins := cfg.AllowedOrigins
 	if origins == "" {
 		logger.AppError("Allowed origins not set")
 
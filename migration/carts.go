@@ -0,0 +1,38 @@
+package migration
+
+import (
+	"shophub-backend/logger"
+
+	"gorm.io/gorm"
+)
+
+// cartBackfills fill the cart columns added after carts were created. Only rows without a value are
+// updated, so it is safe to run on every start.
+var cartBackfills = []string{
+	// Items from before the currency was kept with the prices are priced in the currency of their product
+	`UPDATE cart_items SET currency = p.currency
+	FROM products p
+	WHERE p.product_id = cart_items.product_id AND COALESCE(cart_items.currency, '') = '' AND COALESCE(p.currency, '') <> ''`,
+
+	// Carts from before carts kept their creation time are counted from the first start that has it
+	`UPDATE carts SET created_at = NOW() WHERE created_at IS NULL OR created_at < '1970-01-02'`,
+
+	// Carts from before carts kept their last update are idle since they were created
+	`UPDATE carts SET updated_at = COALESCE(created_at, NOW()) WHERE updated_at IS NULL OR updated_at < '1970-01-02'`,
+}
+
+// BackfillCarts fills the new cart columns of existing carts, it runs after Migrate has added the columns.
+// Items that were in the cart before items could be selected were stored unselected, they are selected
+// once so they are checked out as before. Later the same rows are items the user deselected.
+func BackfillCarts(db *gorm.DB) error {
+	logger.AppInfo("Backfilling carts")
+	if err := runOnce(db, "select existing cart items", `UPDATE cart_items SET is_selected = true`); err != nil {
+		return err
+	}
+	for _, sql := range cartBackfills {
+		if err := db.Exec(sql).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
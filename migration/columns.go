@@ -0,0 +1,71 @@
+package migration
+
+import (
+	"shophub-backend/logger"
+	"shophub-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// addedColumns are columns added to tables that are not auto migrated
+var addedColumns = []struct {
+	Model interface{}
+	Field string
+}{
+	{&model.Product{}, "WeightKg"},
+	{&model.Product{}, "LengthCm"},
+	{&model.Product{}, "WidthCm"},
+	{&model.Product{}, "HeightCm"},
+	{&model.Product{}, "ReorderThreshold"},
+	{&model.Product{}, "StockAlert"},
+	{&model.Product{}, "RatingAverage"},
+	{&model.Product{}, "RatingCount"},
+	{&model.Product{}, "Status"},
+	{&model.Product{}, "PublishAt"},
+	{&model.Product{}, "UnpublishAt"},
+	{&model.Product{}, "DeletedAt"},
+	{&model.Address{}, "Region"},
+	{&model.Address{}, "Unlisted"},
+	{&model.Address{}, "Label"},
+	{&model.Address{}, "IsDefaultShipping"},
+	{&model.Address{}, "IsDefaultBilling"},
+	{&model.Address{}, "DeletedAt"},
+}
+
+// droppedIndexes are indexes replaced by an index with other columns, they are dropped before migrating
+var droppedIndexes = []struct {
+	Model interface{}
+	Name  string
+}{
+	// Stock levels became unique per variant
+	{&model.StockLevel{}, "idx_stock_level_warehouse_product"},
+}
+
+// DropIndexes drops the indexes that were replaced, so the new ones can be created by the migration
+func DropIndexes(db *gorm.DB) error {
+	logger.AppInfo("Dropping replaced indexes")
+	for _, index := range droppedIndexes {
+		if !db.Migrator().HasIndex(index.Model, index.Name) {
+			continue
+		}
+		if err := db.Migrator().DropIndex(index.Model, index.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddColumns adds the new columns to the tables that are not auto migrated, existing rows are left empty.
+// The currency columns are added by AddCurrencyColumns.
+func AddColumns(db *gorm.DB) error {
+	logger.AppInfo("Adding new columns")
+	for _, column := range addedColumns {
+		if !db.Migrator().HasTable(column.Model) || db.Migrator().HasColumn(column.Model, column.Field) {
+			continue
+		}
+		if err := db.Migrator().AddColumn(column.Model, column.Field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package migration
+
+import (
+	"shophub-backend/logger"
+	"shophub-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// AddCurrencyColumns adds the currency columns to the tables that are not auto migrated.
+// Existing rows are left empty, which is read as the default currency.
+func AddCurrencyColumns(db *gorm.DB) error {
+	logger.AppInfo("Adding currency columns")
+	for _, table := range []interface{}{&model.Product{}, &model.Payment{}} {
+		if !db.Migrator().HasTable(table) || db.Migrator().HasColumn(table, "Currency") {
+			continue
+		}
+		if err := db.Migrator().AddColumn(table, "Currency"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
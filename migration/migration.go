@@ -13,5 +13,33 @@ func Migrate(db DBMigrator) error {
 	logger.AppInfo("Database Migration")
 	return db.AutoMigrate(
 		&model.User{},
+		&model.Cart{},
+		&model.CartItem{},
+		&model.Order{},
+		&model.Wishlist{},
+		&model.WishlistItem{},
+		&model.Coupon{},
+		&model.CouponRedemption{},
+		&model.Promotion{},
+		&model.PromotionTier{},
+		&model.ExchangeRate{},
+		&model.TaxRate{},
+		&model.OrderTaxLine{},
+		&model.ShippingZone{},
+		&model.ShippingZoneCountry{},
+		&model.ShippingMethod{},
+		&model.InventoryReservation{},
+		&model.Warehouse{},
+		&model.StockLevel{},
+		&model.StockMovement{},
+		&model.ProductOption{},
+		&model.ProductOptionValue{},
+		&model.ProductVariant{},
+		&model.ProductVariantImage{},
+		&model.AttributeDefinition{},
+		&model.AttributeAllowedValue{},
+		&model.ProductAttributeValue{},
+		&model.Review{},
+		&model.CoPurchase{},
 	)
 }
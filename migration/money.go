@@ -0,0 +1,52 @@
+package migration
+
+import (
+	"fmt"
+	"shophub-backend/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// moneyColumns are the columns that held float prices before amounts were stored as numeric(12,2)
+var moneyColumns = []struct {
+	Table  string
+	Column string
+}{
+	{"products", "product_price"},
+	{"cart_items", "unit_price"},
+	{"cart_items", "total_price"},
+	{"orders", "product_price"},
+	{"orders", "total_price"},
+	{"orders", "discount_amount"},
+	{"payments", "payment_amount"},
+}
+
+// ConvertMoneyColumns converts the float money columns to numeric(12,2), rounding existing values
+// to cents. Columns that are already numeric or do not exist yet are skipped, so it is safe to run on every start.
+func ConvertMoneyColumns(db *gorm.DB) error {
+	logger.AppInfo("Converting money columns")
+	for _, mc := range moneyColumns {
+		var dataType string
+		err := db.Raw(
+			"SELECT data_type FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?",
+			mc.Table, mc.Column,
+		).Scan(&dataType).Error
+		if err != nil {
+			return err
+		}
+		if dataType == "" || dataType == "numeric" {
+			continue
+		}
+
+		sql := fmt.Sprintf(
+			"ALTER TABLE %s ALTER COLUMN %s TYPE numeric(12,2) USING round(%s::numeric, 2)",
+			mc.Table, mc.Column, mc.Column,
+		)
+		if err := db.Exec(sql).Error; err != nil {
+			return fmt.Errorf("failed to convert %s.%s: %w", mc.Table, mc.Column, err)
+		}
+		logger.AppInfo("Converted money column", zap.String("table", mc.Table), zap.String("column", mc.Column))
+	}
+	return nil
+}
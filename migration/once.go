@@ -0,0 +1,47 @@
+package migration
+
+import (
+	"shophub-backend/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// appliedBackfill marks a backfill that changes rows which cannot be told apart from new ones,
+// so it must only run once
+type appliedBackfill struct {
+	Name      string `gorm:"primaryKey;size:100"`
+	AppliedAt time.Time
+}
+
+func (appliedBackfill) TableName() string {
+	return "applied_backfills"
+}
+
+// runOnce runs the statements in one transaction together with the marker of the backfill,
+// the backfill is skipped when its marker already exists
+func runOnce(db *gorm.DB, name string, statements ...string) error {
+	if err := db.AutoMigrate(&appliedBackfill{}); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&appliedBackfill{Name: name, AppliedAt: time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		for _, sql := range statements {
+			if err := tx.Exec(sql).Error; err != nil {
+				return err
+			}
+		}
+		logger.AppInfo("Applied one-time backfill", zap.String("name", name))
+		return nil
+	})
+}
@@ -0,0 +1,37 @@
+package migration
+
+import (
+	"shophub-backend/logger"
+
+	"gorm.io/gorm"
+)
+
+// snapshotBackfills copy the product and addresses into orders placed before orders kept their own copy.
+// Only orders without a copy are updated, so it is safe to run on every start.
+var snapshotBackfills = []string{
+	`UPDATE orders SET product_name = p.product_name, product_slug = p.product_slug, product_image = p.image_url_main
+	FROM products p
+	WHERE p.product_id = orders.product_id AND COALESCE(orders.product_name, '') = ''`,
+
+	`UPDATE orders SET shipping_line1 = a.line1, shipping_line2 = a.line2, shipping_city = a.city,
+		shipping_postal_code = a.postal_code, shipping_country = a.country, shipping_region = a.region
+	FROM addresses a
+	WHERE a.address_id = orders.address_id AND COALESCE(orders.shipping_line1, '') = ''`,
+
+	`UPDATE orders SET billing_line1 = a.line1, billing_line2 = a.line2, billing_city = a.city,
+		billing_postal_code = a.postal_code, billing_country = a.country, billing_region = a.region
+	FROM addresses a
+	WHERE a.address_id = COALESCE(orders.billing_address_id, orders.address_id) AND COALESCE(orders.billing_line1, '') = ''`,
+}
+
+// BackfillOrderSnapshots fills the product and address copies of existing orders, it runs after Migrate
+// has added the columns
+func BackfillOrderSnapshots(db *gorm.DB) error {
+	logger.AppInfo("Backfilling order snapshots")
+	for _, sql := range snapshotBackfills {
+		if err := db.Exec(sql).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package migration
+
+import (
+	"shophub-backend/logger"
+	"shophub-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// BackfillStockMovements records the current stock of every warehouse that has no movements yet as an
+// opening movement, so the stock of each product is the sum of its ledger. It is safe to run on every start.
+func BackfillStockMovements(db *gorm.DB) error {
+	logger.AppInfo("Backfilling stock movements")
+
+	return db.Exec(
+		`INSERT INTO stock_movements (product_id, variant_id, warehouse_id, quantity, reason, actor, reference, created_at)
+		SELECT s.product_id, s.variant_id, s.warehouse_id, s.quantity, ?, 'system', 'opening balance', NOW()
+		FROM stock_levels s
+		WHERE s.quantity <> 0 AND NOT EXISTS (
+			SELECT 1 FROM stock_movements m
+			WHERE m.product_id = s.product_id AND m.variant_id = s.variant_id AND m.warehouse_id = s.warehouse_id
+		)`,
+		model.StockMovementOpening,
+	).Error
+}
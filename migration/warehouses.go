@@ -0,0 +1,37 @@
+package migration
+
+import (
+	"shophub-backend/logger"
+	"shophub-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// DefaultWarehouseCode is the warehouse that holds the stock products had before stock was kept per warehouse
+const DefaultWarehouseCode = "MAIN"
+
+// SeedWarehouseStock creates the default warehouse when there is none, and moves the stock of every product
+// that is not stocked in any warehouse yet into the first warehouse. It is safe to run on every start.
+func SeedWarehouseStock(db *gorm.DB) error {
+	logger.AppInfo("Seeding warehouse stock")
+
+	var warehouse model.Warehouse
+	result := db.Order("warehouse_id ASC").Limit(1).Find(&warehouse)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		warehouse = model.Warehouse{Code: DefaultWarehouseCode, Name: "Main warehouse", IsActive: true}
+		if err := db.Create(&warehouse).Error; err != nil {
+			return err
+		}
+	}
+
+	return db.Exec(
+		`INSERT INTO stock_levels (warehouse_id, product_id, quantity, updated_at)
+		SELECT ?, p.product_id, GREATEST(p.product_stock, 0), NOW()
+		FROM products p
+		WHERE NOT EXISTS (SELECT 1 FROM stock_levels s WHERE s.product_id = p.product_id)`,
+		warehouse.WarehouseID,
+	).Error
+}
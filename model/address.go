@@ -1,11 +1,25 @@
 package model
 
+import "gorm.io/gorm"
+
 type Address struct {
 	AddressId      uint   `gorm:"primaryKey;autoIncrement" json:"address_id"`
 	KeycloakUserID string `gorm:"not null;index" json:"keycloak_user_id"`
+	Label          string `gorm:"size:50" json:"label"`
 	Line1          string `gorm:"size:200;not null" json:"line1"`
 	Line2          string `gorm:"size:200;not null" json:"line2"`
 	City           string `gorm:"size:100;not null" json:"city"`
 	PostalCode     string `gorm:"size:100;not null" json:"postal_code"`
 	Country        string `gorm:"size:100;not null" json:"country"`
+	Region         string `gorm:"size:100" json:"region"`
+
+	IsDefaultShipping bool `gorm:"not null;default:false" json:"is_default_shipping"`
+	IsDefaultBilling  bool `gorm:"not null;default:false" json:"is_default_billing"`
+
+	// Unlisted addresses were entered at checkout without saving them, they are kept
+	// for the orders but not shown in the address book
+	Unlisted bool `gorm:"not null;default:false" json:"-"`
+
+	// Deleted addresses are only hidden, the orders shipped to them still reference them
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
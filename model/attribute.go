@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// AttributeDefinition is a typed specification of the products of a category, such as RAM or storage.
+// Code identifies the attribute in product filters and is unique within the category. The values of
+// an ENUM attribute are limited to its allowed values.
+type AttributeDefinition struct {
+	AttributeID  uint      `gorm:"primaryKey" json:"attribute_id"`
+	CategoryID   uint      `gorm:"not null;uniqueIndex:idx_attribute_category_code" json:"category_id"`
+	Code         string    `gorm:"size:50;not null;uniqueIndex:idx_attribute_category_code" json:"code"`
+	Name         string    `gorm:"size:100;not null" json:"name"`
+	Type         string    `gorm:"size:20;not null" json:"type"`
+	Unit         string    `gorm:"size:20;not null;default:''" json:"unit"`
+	IsFilterable bool      `gorm:"not null;default:true" json:"is_filterable"`
+	Position     int       `gorm:"not null;default:0" json:"position"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	AllowedValues []AttributeAllowedValue `gorm:"foreignKey:AttributeID" json:"allowed_values,omitempty"`
+}
+
+type AttributeAllowedValue struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	AttributeID uint   `gorm:"not null;index" json:"attribute_id"`
+	Value       string `gorm:"size:100;not null" json:"value"`
+	Position    int    `gorm:"not null;default:0" json:"position"`
+}
+
+// ProductAttributeValue is the value of an attribute for a product, kept in the canonical form of the attribute type
+type ProductAttributeValue struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	ProductID   uint   `gorm:"not null;uniqueIndex:idx_product_attribute" json:"product_id"`
+	AttributeID uint   `gorm:"not null;uniqueIndex:idx_product_attribute" json:"attribute_id"`
+	Value       string `gorm:"size:100;not null" json:"value"`
+
+	Attribute AttributeDefinition `gorm:"foreignKey:AttributeID;references:AttributeID" json:"attribute"`
+}
+
+// Values are the allowed values of the attribute in display order
+func (a *AttributeDefinition) Values() []string {
+	values := make([]string, 0, len(a.AllowedValues))
+	for _, allowed := range a.AllowedValues {
+		values = append(values, allowed.Value)
+	}
+	return values
+}
@@ -1,8 +1,19 @@
 package model
 
+import (
+	"shophub-backend/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
 type Cart struct {
-	CartID         uint   `gorm:"primaryKey" json:"cart_id"`
-	KeycloakUserID string `gorm:"not null;index" json:"keycloak_user_id"`
+	CartID         uint       `gorm:"primaryKey" json:"cart_id"`
+	KeycloakUserID string     `gorm:"not null;index" json:"keycloak_user_id"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime;index" json:"updated_at"`
+	ReminderSentAt *time.Time `json:"reminder_sent_at"`
+	CouponCode     string     `gorm:"size:50" json:"coupon_code"`
 
 	// Relationships
 
@@ -13,11 +24,45 @@ type CartItem struct {
 	ID        uint `gorm:"primaryKey" json:"id"`
 	CartID    uint `gorm:"not null"`
 	ProductID uint `json:"product_id"`
+	// VariantID is the chosen variant of a product sold as variants
+	VariantID *uint `gorm:"index" json:"variant_id"`
+
+	UnitPrice  money.Money `gorm:"type:numeric(12,2)" json:"unit_price"`
+	Quantity   int         `json:"quantity"`
+	TotalPrice money.Money `gorm:"type:numeric(12,2)" json:"total_price"`
+	IsSelected bool        `json:"is_selected"`
+	// Currency of the prices, the currency of the product when the item was added
+	Currency string `gorm:"size:3" json:"currency"`
+
+	Product Product         `gorm:"foreignKey:ProductID" json:"product"`
+	Variant *ProductVariant `gorm:"foreignKey:VariantID" json:"variant,omitempty"`
+}
 
-	UnitPrice  float64 `json:"unit_price"`
-	Quantity   int     `json:"quantity"`
-	TotalPrice float64 `json:"total_price"`
-	IsSelected bool    `json:"is_selected"`
+// BeforeSave keeps the currency of the prices next to them
+func (i *CartItem) BeforeSave(tx *gorm.DB) error {
+	i.Currency = i.UnitPrice.Currency
+	if i.Currency == "" {
+		i.Currency = money.DefaultCurrency
+	}
+	return nil
+}
+
+// AfterFind tags the prices with the currency they were stored in
+func (i *CartItem) AfterFind(tx *gorm.DB) error {
+	i.UnitPrice = i.UnitPrice.WithCurrency(i.Currency)
+	i.TotalPrice = i.TotalPrice.WithCurrency(i.Currency)
+	return nil
+}
+
+// CurrentPrice is the price of the item's product or variant today, in the product's currency
+func (i *CartItem) CurrentPrice() money.Money {
+	return i.Product.PriceFor(i.Variant)
+}
 
-	Product Product `gorm:"foreignKey:ProductID" json:"product"`
+// StockVariantID is the variant the item's stock is kept under, zero for a product without variants
+func (i *CartItem) StockVariantID() uint {
+	if i.VariantID == nil {
+		return 0
+	}
+	return *i.VariantID
 }
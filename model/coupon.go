@@ -0,0 +1,47 @@
+package model
+
+import (
+	"shophub-backend/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Coupon struct {
+	CouponID          uint        `gorm:"primaryKey" json:"coupon_id"`
+	Code              string      `gorm:"size:50;uniqueIndex;not null" json:"code"`
+	Description       string      `gorm:"size:250" json:"description"`
+	DiscountType      string      `gorm:"size:20;not null" json:"discount_type"`
+	DiscountValue     float64     `gorm:"type:decimal(10,2);not null" json:"discount_value"`
+	MaxDiscountAmount money.Money `gorm:"type:numeric(12,2);not null;default:0" json:"max_discount_amount"`
+	MinOrderValue     money.Money `gorm:"type:numeric(12,2);not null;default:0" json:"min_order_value"`
+	UsageLimit        int         `gorm:"not null;default:0" json:"usage_limit"`
+	PerUserLimit      int         `gorm:"not null;default:0" json:"per_user_limit"`
+	UsedCount         int         `gorm:"not null;default:0" json:"used_count"`
+	StartsAt          *time.Time  `json:"starts_at"`
+	EndsAt            *time.Time  `json:"ends_at"`
+	IsActive          bool        `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt         time.Time   `gorm:"autoCreateTime" json:"created_at"`
+
+	// Scoping, a coupon without products or categories applies to the whole cart
+	Products   []Product  `gorm:"many2many:coupon_products;joinForeignKey:CouponID;joinReferences:ProductID" json:"products"`
+	Categories []Category `gorm:"many2many:coupon_categories;joinForeignKey:CouponID;joinReferences:CategoryID" json:"categories"`
+}
+
+// CouponRedemption is one use of a coupon by a checkout. OrderId is the first order of the checkout,
+// it is zero while the orders are being created.
+type CouponRedemption struct {
+	RedemptionID   uint        `gorm:"primaryKey" json:"redemption_id"`
+	CouponID       uint        `gorm:"not null;index" json:"coupon_id"`
+	KeycloakUserID string      `gorm:"not null;index" json:"keycloak_user_id"`
+	OrderId        uint        `gorm:"not null;index" json:"order_id"`
+	DiscountAmount money.Money `gorm:"type:numeric(12,2);not null" json:"discount_amount"`
+	Currency       string      `gorm:"size:3" json:"currency"`
+	CreatedAt      time.Time   `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// AfterFind tags the discount with the currency it was given in
+func (r *CouponRedemption) AfterFind(tx *gorm.DB) error {
+	r.DiscountAmount = r.DiscountAmount.WithCurrency(r.Currency)
+	return nil
+}
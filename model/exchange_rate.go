@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// ExchangeRate is the number of units of the currency for one unit of the store's default currency
+type ExchangeRate struct {
+	Currency  string    `gorm:"primaryKey;size:3" json:"currency"`
+	Rate      float64   `gorm:"type:numeric(18,8);not null" json:"rate"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+const (
+	ReservationStatusActive    = "ACTIVE"
+	ReservationStatusCommitted = "COMMITTED"
+	ReservationStatusReleased  = "RELEASED"
+)
+
+// InventoryReservation holds stock of a warehouse for an order while its payment is pending. Committing it
+// takes the quantity off the warehouse and product stock, releasing it makes the quantity available again.
+type InventoryReservation struct {
+	ReservationID  uint       `gorm:"primaryKey" json:"reservation_id"`
+	ProductID      uint       `gorm:"not null;index" json:"product_id"`
+	VariantID      uint       `gorm:"not null;default:0;index" json:"variant_id"`
+	WarehouseID    *uint      `gorm:"index" json:"warehouse_id"`
+	OrderID        *uint      `gorm:"index" json:"order_id"`
+	KeycloakUserID string     `gorm:"not null;index" json:"keycloak_user_id"`
+	Quantity       int        `gorm:"not null" json:"quantity"`
+	Status         string     `gorm:"size:20;not null;index" json:"status"`
+	ReleaseReason  string     `gorm:"size:100" json:"release_reason,omitempty"`
+	ExpiresAt      time.Time  `gorm:"not null;index" json:"expires_at"`
+	CommittedAt    *time.Time `json:"committed_at"`
+	ReleasedAt     *time.Time `json:"released_at"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
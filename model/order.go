@@ -1,24 +1,100 @@
 package model
 
-import "time"
+import (
+	"shophub-backend/money"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Order struct {
 	OrderId        uint   `gorm:"PrimaryKey" json:"order_id"`
 	KeycloakUserID string `gorm:"not null;index" json:"keycloak_user_id"`
 	ProductId      uint   `gorm:"not null"`
+	VariantID      *uint  `gorm:"index" json:"variant_id"`
 	PaymentId      uint   `gorm:"not null"`
 
-	ProductPrice float64 `json:"product_price"`
-	Quantity     uint    `gorm:"not null" json:"qty"`
+	ProductPrice money.Money `gorm:"type:numeric(12,2)" json:"product_price"`
+	Quantity     uint        `gorm:"not null" json:"qty"`
+
+	DiscountAmount money.Money `gorm:"type:numeric(12,2);not null;default:0" json:"discount_amount"`
+	CouponCode     string      `gorm:"size:50" json:"coupon_code"`
+	TaxAmount      money.Money `gorm:"type:numeric(12,2);not null;default:0" json:"tax_amount"`
+	ShippingCost   money.Money `gorm:"type:numeric(12,2);not null;default:0" json:"shipping_cost"`
+	TotalPrice     money.Money `gorm:"type:numeric(12,2)" json:"total_price"`
+	AddressId      *uint       `json:"address_id"`
+	OrderStatus    string      `gorm:"size:50;default:'pending'" json:"order_status"`
+	CreatedAt      time.Time   `gorm:"autoCreateTime" json:"created_at"`
+
+	// Amounts are in Currency, converted from the product's currency at ExchangeRate
+	Currency     string  `gorm:"size:3" json:"currency"`
+	ExchangeRate float64 `gorm:"type:numeric(18,8);not null;default:1" json:"exchange_rate"`
+
+	// The shipping cost of a checkout is split over its orders, ShippingCost is this order's share
+	ShippingMethodID   *uint  `gorm:"index" json:"shipping_method_id"`
+	ShippingMethodName string `gorm:"size:100" json:"shipping_method_name"`
 
-	TotalPrice  float64   `json:"total_price"`
-	AddressId   *uint     `json:"address_id"`
-	OrderStatus string    `gorm:"size:50;default:'pending'" json:"order_status"`
-	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	// AddressId is where the order ships to, the billing address defaults to it
+	BillingAddressId *uint `json:"billing_address_id"`
+
+	// Copies taken when the order was placed, later changes to the product or
+	// to the addresses in the address book do not change the order
+	ProductName     string       `gorm:"size:250" json:"product_name"`
+	ProductSlug     string       `gorm:"size:250" json:"product_slug"`
+	ProductImage    string       `json:"product_image"`
+	VariantSKU      string       `gorm:"column:variant_sku;size:100" json:"variant_sku,omitempty"`
+	VariantName     string       `gorm:"size:250" json:"variant_name,omitempty"`
+	ShippingAddress OrderAddress `gorm:"embedded;embeddedPrefix:shipping_" json:"shipping_address"`
+	BillingAddress  OrderAddress `gorm:"embedded;embeddedPrefix:billing_" json:"billing_address"`
 
 	//Relationships
-	Product Product  `gorm:"foreignKey:ProductId" json:"product"`
-	Address *Address `gorm:"foreignKey:AddressId" json:"address"`
-	Payment Payment  `gorm:"foreignKey:PaymentId" json:"payment"`
-	Price   Product  `gorm:"foreignKey:ProductPrice" json:"price"`
+	Product  Product        `gorm:"foreignKey:ProductId" json:"-"`
+	Payment  Payment        `gorm:"foreignKey:PaymentId" json:"payment"`
+	TaxLines []OrderTaxLine `gorm:"foreignKey:OrderId" json:"tax_lines"`
+	Price    Product        `gorm:"foreignKey:ProductPrice;-:migration" json:"price"`
+}
+
+// OrderAddress is the copy of an address kept with the order
+type OrderAddress struct {
+	Line1      string `gorm:"size:200" json:"line1"`
+	Line2      string `gorm:"size:200" json:"line2"`
+	City       string `gorm:"size:100" json:"city"`
+	PostalCode string `gorm:"size:100" json:"postal_code"`
+	Country    string `gorm:"size:100" json:"country"`
+	Region     string `gorm:"size:100" json:"region"`
+}
+
+func NewOrderAddress(address *Address) OrderAddress {
+	return OrderAddress{
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+		Region:     address.Region,
+	}
+}
+
+// AfterFind tags the amounts with the order currency
+func (o *Order) AfterFind(tx *gorm.DB) error {
+	for _, amount := range []*money.Money{&o.ProductPrice, &o.DiscountAmount, &o.TaxAmount, &o.ShippingCost, &o.TotalPrice} {
+		*amount = amount.WithCurrency(o.Currency)
+	}
+	return nil
+}
+
+// SetProduct copies the product details into the order, and those of the variant when one was ordered
+func (o *Order) SetProduct(product *Product, variant *ProductVariant) {
+	o.ProductName = product.ProductName
+	o.ProductSlug = product.ProductSlug
+	o.ProductImage = product.ImgUrlMain
+	if variant == nil {
+		return
+	}
+	o.VariantID = &variant.VariantID
+	o.VariantSKU = variant.SKU
+	o.VariantName = variant.Name()
+	if image := variant.ImageURL(); image != "" {
+		o.ProductImage = image
+	}
 }
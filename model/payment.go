@@ -1,10 +1,23 @@
 package model
 
+import (
+	"shophub-backend/money"
+
+	"gorm.io/gorm"
+)
+
 type Payment struct {
-	PaymentId      uint    `gorm:"PrimaryKey" json:"payment_id"`
-	OrderId        *uint   `gorm:"index" json:"order_id"`
-	KeycloakUserID string  `gorm:"not null;index" json:"keycloak_user_id"`
-	PaymentMethod  string  `gorm:"size:100;not null" json:"payment_method"`
-	PaymentAmount  float64 `gorm:"type:decimal(10,2);not null" json:"payment_amount"`
-	Status         string  `gorm:"size:50;not null;default:'pending'" json:"status"`
+	PaymentId      uint        `gorm:"PrimaryKey" json:"payment_id"`
+	OrderId        *uint       `gorm:"index" json:"order_id"`
+	KeycloakUserID string      `gorm:"not null;index" json:"keycloak_user_id"`
+	PaymentMethod  string      `gorm:"size:100;not null" json:"payment_method"`
+	PaymentAmount  money.Money `gorm:"type:numeric(12,2);not null" json:"payment_amount"`
+	Currency       string      `gorm:"size:3" json:"currency"`
+	Status         string      `gorm:"size:50;not null;default:'pending'" json:"status"`
+}
+
+// AfterFind tags the amount with the payment currency
+func (p *Payment) AfterFind(tx *gorm.DB) error {
+	p.PaymentAmount = p.PaymentAmount.WithCurrency(p.Currency)
+	return nil
 }
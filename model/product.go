@@ -1,15 +1,84 @@
 package model
 
+import (
+	"shophub-backend/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ProductStatusDraft    = "DRAFT"
+	ProductStatusActive   = "ACTIVE"
+	ProductStatusArchived = "ARCHIVED"
+)
+
+const (
+	StockAlertNone       = ""
+	StockAlertLowStock   = "LOW_STOCK"
+	StockAlertOutOfStock = "OUT_OF_STOCK"
+)
+
 type Product struct {
-	ProductID    uint    `gorm:"primaryKey" json:"product_id"`
-	ProductName  string  `gorm:"size:250; not null" json:"product_name"`
-	ProductPrice float64 `gorm:"not null" json:"product_price"`
-	ProductStock int     `gorm:"not null" json:"product_stock"`
-	ProductSlug  string  `gorm:"size:250; unique; not null" json:"product_slug"`
-	CategoryID   uint    `gorm:"not null;index" json:"category_id"`
-	ImgUrlMain   string  `gorm:"column:image_url_main" json:"image_url_main"`
+	ProductID    uint        `gorm:"primaryKey" json:"product_id"`
+	ProductName  string      `gorm:"size:250; not null" json:"product_name"`
+	ProductPrice money.Money `gorm:"type:numeric(12,2);not null" json:"product_price"`
+	Currency     string      `gorm:"size:3" json:"currency"`
+	ProductStock int         `gorm:"not null" json:"product_stock"`
+	ProductSlug  string      `gorm:"size:250; unique; not null" json:"product_slug"`
+	CategoryID   uint        `gorm:"not null;index" json:"category_id"`
+	ImgUrlMain   string      `gorm:"column:image_url_main" json:"image_url_main"`
+
+	// Shipping weight in kilograms and dimensions in centimetres
+	WeightKg float64 `gorm:"type:decimal(10,3);not null;default:0" json:"weight_kg"`
+	LengthCm float64 `gorm:"type:decimal(10,2);not null;default:0" json:"length_cm"`
+	WidthCm  float64 `gorm:"type:decimal(10,2);not null;default:0" json:"width_cm"`
+	HeightCm float64 `gorm:"type:decimal(10,2);not null;default:0" json:"height_cm"`
+
+	// Stock at or below which the product is low on stock, nil uses the store's default threshold.
+	// StockAlert is the last alert sent so each change of state is only alerted once.
+	ReorderThreshold *int   `json:"reorder_threshold"`
+	StockAlert       string `gorm:"size:20;not null;default:''" json:"-"`
+
+	// Average and number of the approved reviews, kept up to date as reviews are moderated
+	RatingAverage float64 `gorm:"type:decimal(3,2);not null;default:0" json:"rating_average"`
+	RatingCount   int     `gorm:"not null;default:0" json:"rating_count"`
+
+	// Only active products are shown and sold, between PublishAt and UnpublishAt when they are set.
+	// A draft is activated once its PublishAt is reached and an active product archived once its UnpublishAt is.
+	// Deleted products are archived and kept for the orders and carts that refer to them.
+	Status      string         `gorm:"size:20;not null;default:'ACTIVE'" json:"status"`
+	PublishAt   *time.Time     `json:"publish_at"`
+	UnpublishAt *time.Time     `json:"unpublish_at"`
+	DeletedAt   gorm.DeletedAt `json:"-"`
 
 	// Relationships
-	Category      Category       `gorm:"foreignKey:CategoryID;references:CategoryID" json:"category"`
-	ProductImages []ProductImage `gorm:"foreignKey:ProductID" json:"product_images"`
+	Category      Category                `gorm:"foreignKey:CategoryID;references:CategoryID" json:"category"`
+	ProductImages []ProductImage          `gorm:"foreignKey:ProductID" json:"product_images"`
+	Options       []ProductOption         `gorm:"foreignKey:ProductID" json:"options,omitempty"`
+	Variants      []ProductVariant        `gorm:"foreignKey:ProductID" json:"variants,omitempty"`
+	Attributes    []ProductAttributeValue `gorm:"foreignKey:ProductID" json:"attributes,omitempty"`
+}
+
+// AfterFind tags the price with the product's base currency, products without one use the default currency
+func (p *Product) AfterFind(tx *gorm.DB) error {
+	if p.Currency == "" {
+		p.Currency = money.DefaultCurrency
+	}
+	p.ProductPrice = p.ProductPrice.WithCurrency(p.Currency)
+	return nil
+}
+
+// IsAvailable is true when the product is shown in the store and can be bought at the time
+func (p *Product) IsAvailable(now time.Time) bool {
+	if p.DeletedAt.Valid || p.Status != ProductStatusActive {
+		return false
+	}
+	if p.PublishAt != nil && now.Before(*p.PublishAt) {
+		return false
+	}
+	if p.UnpublishAt != nil && !now.Before(*p.UnpublishAt) {
+		return false
+	}
+	return true
 }
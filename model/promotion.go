@@ -0,0 +1,44 @@
+package model
+
+import (
+	"shophub-backend/money"
+	"time"
+)
+
+type Promotion struct {
+	PromotionID uint   `gorm:"primaryKey" json:"promotion_id"`
+	Name        string `gorm:"size:150;not null" json:"name"`
+	Description string `gorm:"size:250" json:"description"`
+	Type        string `gorm:"size:30;not null" json:"type"`
+
+	// Higher priority promotions are evaluated first, exclusive promotions do not combine with others
+	Priority  int  `gorm:"not null;default:0" json:"priority"`
+	Exclusive bool `gorm:"not null;default:false" json:"exclusive"`
+
+	// Buy X get Y
+	BuyQuantity        int     `gorm:"not null;default:0" json:"buy_quantity"`
+	GetQuantity        int     `gorm:"not null;default:0" json:"get_quantity"`
+	GetDiscountPercent float64 `gorm:"type:decimal(5,2);not null;default:0" json:"get_discount_percent"`
+
+	// Bundle, the scoped products bought together for a fixed price
+	BundlePrice money.Money `gorm:"type:numeric(12,2);not null;default:0" json:"bundle_price"`
+
+	StartsAt  *time.Time `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at"`
+	IsActive  bool       `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	// Relationships
+	Products   []Product       `gorm:"many2many:promotion_products;joinForeignKey:PromotionID;joinReferences:ProductID" json:"products"`
+	Categories []Category      `gorm:"many2many:promotion_categories;joinForeignKey:PromotionID;joinReferences:CategoryID" json:"categories"`
+	Tiers      []PromotionTier `gorm:"foreignKey:PromotionID" json:"tiers"`
+}
+
+// PromotionTier is a spend threshold of a tiered promotion
+type PromotionTier struct {
+	TierID        uint        `gorm:"primaryKey" json:"tier_id"`
+	PromotionID   uint        `gorm:"not null;index" json:"promotion_id"`
+	MinSpend      money.Money `gorm:"type:numeric(12,2);not null" json:"min_spend"`
+	DiscountType  string      `gorm:"size:20;not null" json:"discount_type"`
+	DiscountValue float64     `gorm:"type:decimal(10,2);not null" json:"discount_value"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// CoPurchase is how often the related product was bought together with the product, the table is
+// rebuilt from the order history by the recommendation job
+type CoPurchase struct {
+	ID               uint      `gorm:"primaryKey" json:"-"`
+	ProductID        uint      `gorm:"not null;index" json:"product_id"`
+	RelatedProductID uint      `gorm:"not null" json:"related_product_id"`
+	Count            int       `gorm:"not null" json:"count"`
+	ComputedAt       time.Time `gorm:"not null" json:"computed_at"`
+}
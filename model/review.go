@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+const (
+	ReviewStatusPending  = "PENDING"
+	ReviewStatusApproved = "APPROVED"
+	ReviewStatusRejected = "REJECTED"
+)
+
+// Review of a product by a customer who received it. Reviews are published once approved,
+// only approved reviews count towards the product's rating.
+type Review struct {
+	ReviewID       uint      `gorm:"primaryKey" json:"review_id"`
+	ProductID      uint      `gorm:"not null;uniqueIndex:idx_review_product_user" json:"product_id"`
+	KeycloakUserID string    `gorm:"not null;uniqueIndex:idx_review_product_user" json:"keycloak_user_id"`
+	Rating         int       `gorm:"not null" json:"rating"`
+	Title          string    `gorm:"size:150;not null" json:"title"`
+	Body           string    `gorm:"type:text;not null" json:"body"`
+	Status         string    `gorm:"size:20;not null;default:'PENDING';index" json:"status"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
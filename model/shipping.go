@@ -0,0 +1,40 @@
+package model
+
+import (
+	"shophub-backend/money"
+	"time"
+)
+
+// ShippingZone groups the countries that share shipping methods. The default zone
+// is used for countries that are not in any other zone.
+type ShippingZone struct {
+	ShippingZoneID uint      `gorm:"primaryKey" json:"shipping_zone_id"`
+	Name           string    `gorm:"size:100;not null" json:"name"`
+	IsDefault      bool      `gorm:"not null;default:false" json:"is_default"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	Countries []ShippingZoneCountry `gorm:"foreignKey:ShippingZoneID" json:"countries"`
+	Methods   []ShippingMethod      `gorm:"foreignKey:ShippingZoneID" json:"methods"`
+}
+
+type ShippingZoneCountry struct {
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	ShippingZoneID uint   `gorm:"not null;index" json:"shipping_zone_id"`
+	Country        string `gorm:"size:100;not null;index" json:"country"`
+}
+
+// ShippingMethod amounts are in the default currency
+type ShippingMethod struct {
+	ShippingMethodID uint        `gorm:"primaryKey" json:"shipping_method_id"`
+	ShippingZoneID   uint        `gorm:"not null;index" json:"shipping_zone_id"`
+	Name             string      `gorm:"size:100;not null" json:"name"`
+	Type             string      `gorm:"size:30;not null" json:"type"`
+	BaseRate         money.Money `gorm:"type:numeric(12,2);not null;default:0" json:"base_rate"`
+	RatePerKg        money.Money `gorm:"type:numeric(12,2);not null;default:0" json:"rate_per_kg"`
+	FreeThreshold    money.Money `gorm:"type:numeric(12,2);not null;default:0" json:"free_threshold"`
+	MaxWeightKg      float64     `gorm:"type:decimal(10,3);not null;default:0" json:"max_weight_kg"`
+	MinDeliveryDays  int         `gorm:"not null;default:0" json:"min_delivery_days"`
+	MaxDeliveryDays  int         `gorm:"not null;default:0" json:"max_delivery_days"`
+	IsActive         bool        `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt        time.Time   `gorm:"autoCreateTime" json:"created_at"`
+}
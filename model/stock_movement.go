@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+const (
+	StockMovementOpening      = "OPENING"
+	StockMovementSale         = "SALE"
+	StockMovementCancellation = "CANCELLATION"
+	StockMovementAdjustment   = "ADJUSTMENT"
+	StockMovementReturn       = "RETURN"
+	StockMovementImport       = "IMPORT"
+)
+
+// StockMovement is a single change to the stock of a product in a warehouse. Movements are never
+// updated or deleted, the stock of a product is the sum of its movements. Quantity is negative
+// when stock leaves the warehouse.
+type StockMovement struct {
+	MovementID  uint      `gorm:"primaryKey" json:"movement_id"`
+	ProductID   uint      `gorm:"not null;index" json:"product_id"`
+	VariantID   uint      `gorm:"not null;default:0" json:"variant_id"`
+	WarehouseID *uint     `gorm:"index" json:"warehouse_id"`
+	Quantity    int       `gorm:"not null" json:"quantity"`
+	Reason      string    `gorm:"size:20;not null;index" json:"reason"`
+	Actor       string    `gorm:"size:100" json:"actor"`
+	Reference   string    `gorm:"size:100" json:"reference,omitempty"`
+	CreatedAt   time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
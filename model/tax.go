@@ -0,0 +1,44 @@
+package model
+
+import (
+	"shophub-backend/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TaxRate is a percentage charged on orders shipped to a country, optionally limited
+// to a region and a product category. Inclusive rates are already part of the product prices.
+type TaxRate struct {
+	TaxRateID  uint      `gorm:"primaryKey" json:"tax_rate_id"`
+	Name       string    `gorm:"size:100;not null" json:"name"`
+	Country    string    `gorm:"size:100;not null;index" json:"country"`
+	Region     string    `gorm:"size:100" json:"region"`
+	CategoryID *uint     `gorm:"index" json:"category_id"`
+	Rate       float64   `gorm:"type:decimal(7,4);not null" json:"rate"`
+	Inclusive  bool      `gorm:"not null;default:false" json:"inclusive"`
+	IsActive   bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// OrderTaxLine records the tax charged on an order, in the order currency
+type OrderTaxLine struct {
+	OrderTaxLineID uint        `gorm:"primaryKey" json:"order_tax_line_id"`
+	OrderId        uint        `gorm:"not null;index" json:"order_id"`
+	TaxRateID      uint        `json:"tax_rate_id"`
+	Name           string      `gorm:"size:100;not null" json:"name"`
+	Country        string      `gorm:"size:100" json:"country"`
+	Region         string      `gorm:"size:100" json:"region"`
+	Rate           float64     `gorm:"type:decimal(7,4);not null" json:"rate"`
+	Inclusive      bool        `gorm:"not null;default:false" json:"inclusive"`
+	TaxableAmount  money.Money `gorm:"type:numeric(12,2);not null" json:"taxable_amount"`
+	Amount         money.Money `gorm:"type:numeric(12,2);not null" json:"amount"`
+	Currency       string      `gorm:"size:3" json:"currency"`
+}
+
+// AfterFind tags the amounts with the order currency
+func (l *OrderTaxLine) AfterFind(tx *gorm.DB) error {
+	l.TaxableAmount = l.TaxableAmount.WithCurrency(l.Currency)
+	l.Amount = l.Amount.WithCurrency(l.Currency)
+	return nil
+}
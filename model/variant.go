@@ -0,0 +1,86 @@
+package model
+
+import (
+	"shophub-backend/money"
+	"strings"
+	"time"
+)
+
+// ProductOption is a choice offered on a product such as size or colour, with its values in display order
+type ProductOption struct {
+	OptionID  uint   `gorm:"primaryKey" json:"option_id"`
+	ProductID uint   `gorm:"not null;index" json:"product_id"`
+	Name      string `gorm:"size:50;not null" json:"name"`
+	Position  int    `gorm:"not null;default:0" json:"position"`
+
+	Values []ProductOptionValue `gorm:"foreignKey:OptionID" json:"values"`
+}
+
+type ProductOptionValue struct {
+	ValueID  uint   `gorm:"primaryKey" json:"value_id"`
+	OptionID uint   `gorm:"not null;index" json:"option_id"`
+	Value    string `gorm:"size:50;not null" json:"value"`
+	Position int    `gorm:"not null;default:0" json:"position"`
+}
+
+// ProductVariant is a sellable combination of option values, one value of each of the product's options.
+// A variant is sold at its PriceOverride when set and at the product price otherwise. Its stock is kept
+// per warehouse like the stock of a product without variants.
+type ProductVariant struct {
+	VariantID     uint         `gorm:"primaryKey" json:"variant_id"`
+	ProductID     uint         `gorm:"not null;index" json:"product_id"`
+	SKU           string       `gorm:"column:sku;size:100;not null;uniqueIndex" json:"sku"`
+	PriceOverride *money.Money `gorm:"type:numeric(12,2)" json:"price_override"`
+	IsActive      bool         `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt     time.Time    `gorm:"autoCreateTime" json:"created_at"`
+
+	OptionValues []ProductOptionValue  `gorm:"many2many:product_variant_option_values;joinForeignKey:VariantID;joinReferences:ValueID" json:"option_values"`
+	Images       []ProductVariantImage `gorm:"foreignKey:VariantID" json:"images"`
+}
+
+type ProductVariantImage struct {
+	ImageID   uint   `gorm:"primaryKey" json:"image_id"`
+	VariantID uint   `gorm:"not null;index" json:"variant_id"`
+	ImageURL  string `gorm:"size:500;not null" json:"image_url"`
+	Position  int    `gorm:"not null;default:0" json:"position"`
+}
+
+// Name describes the variant by its option values, such as "M / Red"
+func (v *ProductVariant) Name() string {
+	values := make([]string, 0, len(v.OptionValues))
+	for _, value := range v.OptionValues {
+		values = append(values, value.Value)
+	}
+	return strings.Join(values, " / ")
+}
+
+// ImageURL is the first image of the variant, empty when it has none
+func (v *ProductVariant) ImageURL() string {
+	if len(v.Images) == 0 {
+		return ""
+	}
+	return v.Images[0].ImageURL
+}
+
+// PriceFor is the price of the product, or of its variant when one is given
+func (p *Product) PriceFor(variant *ProductVariant) money.Money {
+	if variant == nil || variant.PriceOverride == nil {
+		return p.ProductPrice
+	}
+	return variant.PriceOverride.WithCurrency(p.ProductPrice.Currency)
+}
+
+// HasVariants is true when the product is sold as variants, it must be loaded with its variants
+func (p *Product) HasVariants() bool {
+	return len(p.Variants) > 0
+}
+
+// Variant returns the variant of the product, active or not, it must be loaded with its variants
+func (p *Product) Variant(variantId uint) *ProductVariant {
+	for i := range p.Variants {
+		if p.Variants[i].VariantID == variantId {
+			return &p.Variants[i]
+		}
+	}
+	return nil
+}
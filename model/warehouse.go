@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Warehouse is a location that holds stock, Country is used to ship from the nearest warehouse
+type Warehouse struct {
+	WarehouseID uint      `gorm:"primaryKey" json:"warehouse_id"`
+	Code        string    `gorm:"size:50;not null;uniqueIndex" json:"code"`
+	Name        string    `gorm:"size:100;not null" json:"name"`
+	Country     string    `gorm:"size:100" json:"country"`
+	IsActive    bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// StockLevel is the stock of a product in a warehouse. Product.ProductStock is kept as the
+// total over all warehouses and variants. VariantID is zero for a product without variants.
+type StockLevel struct {
+	StockLevelID uint      `gorm:"primaryKey" json:"stock_level_id"`
+	WarehouseID  uint      `gorm:"not null;uniqueIndex:idx_stock_level_warehouse_product_variant" json:"warehouse_id"`
+	ProductID    uint      `gorm:"not null;uniqueIndex:idx_stock_level_warehouse_product_variant;index" json:"product_id"`
+	VariantID    uint      `gorm:"not null;default:0;uniqueIndex:idx_stock_level_warehouse_product_variant" json:"variant_id"`
+	Quantity     int       `gorm:"not null;default:0" json:"quantity"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Warehouse *Warehouse `gorm:"foreignKey:WarehouseID" json:"warehouse,omitempty"`
+}
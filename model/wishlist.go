@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+type Wishlist struct {
+	WishlistID     uint      `gorm:"primaryKey" json:"wishlist_id"`
+	KeycloakUserID string    `gorm:"not null;index" json:"keycloak_user_id"`
+	Name           string    `gorm:"size:100;not null" json:"name"`
+	IsDefault      bool      `gorm:"not null;default:false" json:"is_default"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// Relationships
+	Items []WishlistItem `gorm:"foreignKey:WishlistID" json:"wishlist_items"`
+}
+
+type WishlistItem struct {
+	ID         uint `gorm:"primaryKey" json:"id"`
+	WishlistID uint `gorm:"not null;uniqueIndex:idx_wishlist_product" json:"wishlist_id"`
+	ProductID  uint `gorm:"not null;uniqueIndex:idx_wishlist_product" json:"product_id"`
+
+	// Stock state of the product, updated whenever its stock changes to flag items that come back in stock
+	OutOfStock  bool      `gorm:"not null;default:false" json:"out_of_stock"`
+	BackInStock bool      `gorm:"not null;default:false" json:"back_in_stock"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	Product Product `gorm:"foreignKey:ProductID" json:"product"`
+}
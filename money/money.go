@@ -0,0 +1,346 @@
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used for amounts read from the database and for amounts created without a currency
+var DefaultCurrency = "USD"
+
+// zeroDecimalCurrencies have no minor unit, their amounts are whole units of the currency
+var zeroDecimalCurrencies = map[string]bool{
+	"BIF": true, "CLP": true, "DJF": true, "GNF": true, "ISK": true, "JPY": true, "KMF": true, "KRW": true,
+	"PYG": true, "RWF": true, "UGX": true, "VND": true, "VUV": true, "XAF": true, "XOF": true, "XPF": true,
+}
+
+// Decimals is the number of decimal places of the currency, zero for currencies without a minor unit
+func Decimals(currency string) int {
+	if zeroDecimalCurrencies[resolve(currency)] {
+		return 0
+	}
+	return 2
+}
+
+// minorUnitsPerUnit is the number of minor units (such as cents) in one unit of the currency
+func minorUnitsPerUnit(currency string) int64 {
+	if Decimals(currency) == 0 {
+		return 1
+	}
+	return 100
+}
+
+func resolve(currency string) string {
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return currency
+}
+
+// Money is an amount in integer minor units of a currency, hundredths for most currencies and whole
+// units for currencies without a minor unit such as JPY. It is stored in numeric(12,2) columns and
+// serialized to JSON as a decimal number, so existing clients reading prices as numbers keep working.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// New creates an amount from minor units
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: resolve(currency)}
+}
+
+// Zero is a zero amount in the given currency
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// FromFloat converts a decimal amount to minor units, rounding half away from zero
+func FromFloat(amount float64, currency string) Money {
+	return New(int64(math.Round(amount*float64(minorUnitsPerUnit(currency)))), currency)
+}
+
+// Parse reads a decimal string such as "12.34" exactly, rounding extra decimals half away from zero
+func Parse(value string, currency string) (Money, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Money{}, fmt.Errorf("empty money amount")
+	}
+
+	negative := false
+	digits := value
+	if digits[0] == '-' || digits[0] == '+' {
+		negative = digits[0] == '-'
+		digits = digits[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(digits, ".")
+	if !isDigits(whole) || !isDigits(fraction) || whole+fraction == "" {
+		// Fall back to float parsing for exponents and other notations
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return Money{}, fmt.Errorf("invalid money amount %q", value)
+		}
+		return FromFloat(f, currency), nil
+	}
+
+	var units int64
+	if whole != "" {
+		parsed, err := strconv.ParseInt(whole, 10, 64)
+		if err != nil {
+			return Money{}, fmt.Errorf("invalid money amount %q", value)
+		}
+		units = parsed
+	}
+
+	// Keep the decimals of the currency and round on the next one
+	decimals := Decimals(currency)
+	padded := fraction + strings.Repeat("0", decimals+1)
+	var minor int64
+	if decimals > 0 {
+		minor, _ = strconv.ParseInt(padded[:decimals], 10, 64)
+	}
+	if padded[decimals] >= '5' {
+		minor++
+	}
+
+	amount := units*minorUnitsPerUnit(currency) + minor
+	if negative {
+		amount = -amount
+	}
+	return New(amount, currency), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (m Money) currencyWith(other Money) string {
+	if m.Currency != "" {
+		return m.Currency
+	}
+	return other.Currency
+}
+
+// WithCurrency is the same decimal amount in another currency. Amounts are read from the database in the
+// default currency, models tag them with the currency stored next to them.
+func (m Money) WithCurrency(currency string) Money {
+	currency = resolve(currency)
+	return Money{Amount: rescale(m.Amount, minorUnitsPerUnit(m.Currency), minorUnitsPerUnit(currency)), Currency: currency}
+}
+
+// Exchange converts the amount to another currency at the rate, the number of units of that currency for
+// one unit of this one, rounding half away from zero to the minor unit of that currency
+func (m Money) Exchange(rate float64, currency string) Money {
+	currency = resolve(currency)
+	factor := rate * float64(minorUnitsPerUnit(currency)) / float64(minorUnitsPerUnit(m.Currency))
+	return Money{Amount: int64(math.Round(float64(m.Amount) * factor)), Currency: currency}
+}
+
+// rescale converts minor units between currencies with different minor units, rounding half away from zero
+func rescale(amount int64, from int64, to int64) int64 {
+	if from == to {
+		return amount
+	}
+	if to > from {
+		return amount * (to / from)
+	}
+	divisor := from / to
+	rounded := (abs(amount) + divisor/2) / divisor
+	if amount < 0 {
+		return -rounded
+	}
+	return rounded
+}
+
+func abs(amount int64) int64 {
+	if amount < 0 {
+		return -amount
+	}
+	return amount
+}
+
+// Add returns m + other, both amounts are expected to be in the same currency
+func (m Money) Add(other Money) Money {
+	return Money{Amount: m.Amount + other.Amount, Currency: m.currencyWith(other)}
+}
+
+// Sub returns m - other, both amounts are expected to be in the same currency
+func (m Money) Sub(other Money) Money {
+	return Money{Amount: m.Amount - other.Amount, Currency: m.currencyWith(other)}
+}
+
+// Mul multiplies the amount by a quantity, which is exact
+func (m Money) Mul(quantity int) Money {
+	return Money{Amount: m.Amount * int64(quantity), Currency: m.Currency}
+}
+
+// MulFloat multiplies the amount by a factor, rounding half away from zero
+func (m Money) MulFloat(factor float64) Money {
+	return Money{Amount: int64(math.Round(float64(m.Amount) * factor)), Currency: m.Currency}
+}
+
+// Percent returns the given percentage of the amount, rounding half away from zero
+func (m Money) Percent(percent float64) Money {
+	return m.MulFloat(percent / 100)
+}
+
+// Allocate splits the amount proportionally to the weights. Shares are rounded down and
+// the remainder goes to the last non-zero weight, so the shares always add up to the amount.
+func (m Money) Allocate(weights []Money) []Money {
+	shares := make([]Money, len(weights))
+
+	var total uint64
+	last := -1
+	for i, weight := range weights {
+		shares[i] = Zero(m.Currency)
+		if weight.Amount > 0 {
+			total += uint64(weight.Amount)
+			last = i
+		}
+	}
+	if last == -1 {
+		return shares
+	}
+
+	negative := m.Amount < 0
+	amount := uint64(m.Amount)
+	if negative {
+		amount = uint64(-m.Amount)
+	}
+
+	var allocated uint64
+	for i, weight := range weights {
+		if weight.Amount <= 0 || i == last {
+			continue
+		}
+		// 128 bit multiplication so large amounts cannot overflow
+		hi, lo := bits.Mul64(amount, uint64(weight.Amount))
+		share, _ := bits.Div64(hi, lo, total)
+		allocated += share
+		shares[i].Amount = int64(share)
+	}
+	shares[last].Amount = int64(amount - allocated)
+
+	if negative {
+		for i := range shares {
+			shares[i].Amount = -shares[i].Amount
+		}
+	}
+	return shares
+}
+
+// Min returns the smaller of the two amounts
+func Min(a Money, b Money) Money {
+	if b.Amount < a.Amount {
+		return b
+	}
+	return a
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+func (m Money) GreaterThan(other Money) bool {
+	return m.Amount > other.Amount
+}
+
+func (m Money) LessThan(other Money) bool {
+	return m.Amount < other.Amount
+}
+
+func (m Money) Equal(other Money) bool {
+	return m.Amount == other.Amount
+}
+
+// Float64 is only meant for display and ratios, never for further money arithmetic
+func (m Money) Float64() float64 {
+	return float64(m.Amount) / float64(minorUnitsPerUnit(m.Currency))
+}
+
+// String formats the amount as a decimal with the places of its currency, such as "12.34" or "1234" for JPY
+func (m Money) String() string {
+	sign := ""
+	if m.Amount < 0 {
+		sign = "-"
+	}
+	amount := abs(m.Amount)
+	units := minorUnitsPerUnit(m.Currency)
+	if units == 1 {
+		return fmt.Sprintf("%s%d", sign, amount)
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/units, Decimals(m.Currency), amount%units)
+}
+
+// Value stores the amount as a decimal in a numeric column
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan reads numeric, float and integer columns in the default currency, models whose amounts can be in
+// another currency tag them with WithCurrency after loading
+func (m *Money) Scan(value interface{}) error {
+	var parsed Money
+	var err error
+
+	switch v := value.(type) {
+	case nil:
+		parsed = Zero(DefaultCurrency)
+	case []byte:
+		parsed, err = Parse(string(v), DefaultCurrency)
+	case string:
+		parsed, err = Parse(v, DefaultCurrency)
+	case float64:
+		parsed = FromFloat(v, DefaultCurrency)
+	case float32:
+		parsed = FromFloat(float64(v), DefaultCurrency)
+	case int64:
+		parsed = New(v*minorUnitsPerUnit(DefaultCurrency), DefaultCurrency)
+	default:
+		return fmt.Errorf("unsupported money value of type %T", value)
+	}
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// MarshalJSON writes the amount as a JSON number with the decimal places of its currency
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a decimal string, parsed without float rounding
+func (m *Money) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "null" {
+		return nil
+	}
+
+	parsed, err := Parse(value, DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,245 @@
+package money
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency string
+		want     int64
+		wantErr  bool
+	}{
+		{value: "12.34", currency: "USD", want: 1234},
+		{value: "12", currency: "USD", want: 1200},
+		{value: "12.3", currency: "USD", want: 1230},
+		{value: ".5", currency: "USD", want: 50},
+		{value: "7.", currency: "USD", want: 700},
+		{value: " 0.10 ", currency: "USD", want: 10},
+		{value: "+1.00", currency: "USD", want: 100},
+		{value: "-12.34", currency: "USD", want: -1234},
+		{value: "12.345", currency: "USD", want: 1235},
+		{value: "12.344", currency: "USD", want: 1234},
+		{value: "-12.345", currency: "USD", want: -1235},
+		{value: "0.999", currency: "USD", want: 100},
+		{value: "1e2", currency: "USD", want: 10000},
+		{value: "1500", currency: "JPY", want: 1500},
+		{value: "1500.49", currency: "JPY", want: 1500},
+		{value: "1500.5", currency: "JPY", want: 1501},
+		{value: "-1500.5", currency: "JPY", want: -1501},
+		{value: "", currency: "USD", wantErr: true},
+		{value: "-", currency: "USD", wantErr: true},
+		{value: "12.3.4", currency: "USD", wantErr: true},
+		{value: "abc", currency: "USD", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency+" "+tt.value, func(t *testing.T) {
+			got, err := Parse(tt.value, tt.currency)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %v, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.value, err)
+			}
+			if got.Amount != tt.want || got.Currency != tt.currency {
+				t.Errorf("Parse(%q) = %d %s, want %d %s", tt.value, got.Amount, got.Currency, tt.want, tt.currency)
+			}
+		})
+	}
+}
+
+func TestParseUsesDefaultCurrency(t *testing.T) {
+	got, err := Parse("1.00", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Currency != DefaultCurrency {
+		t.Errorf("currency = %q, want %q", got.Currency, DefaultCurrency)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{New(1234, "USD"), "12.34"},
+		{New(5, "USD"), "0.05"},
+		{New(-5, "USD"), "-0.05"},
+		{New(-1234, "EUR"), "-12.34"},
+		{New(0, "USD"), "0.00"},
+		{New(1500, "JPY"), "1500"},
+		{New(-1500, "JPY"), "-1500"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("%d %s = %q, want %q", tt.money.Amount, tt.money.Currency, got, tt.want)
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  int64
+		weights []int64
+		want    []int64
+	}{
+		{"even split", 1000, []int64{1, 1}, []int64{500, 500}},
+		{"remainder goes to the last weight", 100, []int64{1, 1, 1}, []int64{33, 33, 34}},
+		{"proportional", 1000, []int64{3000, 1000}, []int64{750, 250}},
+		{"zero weights get nothing", 100, []int64{0, 1, 0}, []int64{0, 100, 0}},
+		{"remainder skips trailing zero weights", 100, []int64{1, 2, 0}, []int64{33, 67, 0}},
+		{"negative weights get nothing", 100, []int64{-5, 1}, []int64{0, 100}},
+		{"no positive weight", 100, []int64{0, 0}, []int64{0, 0}},
+		{"negative amount", -100, []int64{1, 1, 1}, []int64{-33, -33, -34}},
+		{"zero amount", 0, []int64{1, 2}, []int64{0, 0}},
+		{"large amounts do not overflow", 900000000000000000, []int64{900000000000000000, 900000000000000000}, []int64{450000000000000000, 450000000000000000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weights := make([]Money, len(tt.weights))
+			for i, weight := range tt.weights {
+				weights[i] = New(weight, "USD")
+			}
+
+			shares := New(tt.amount, "USD").Allocate(weights)
+
+			if len(shares) != len(tt.want) {
+				t.Fatalf("got %d shares, want %d", len(shares), len(tt.want))
+			}
+			var total int64
+			for i, share := range shares {
+				if share.Amount != tt.want[i] {
+					t.Errorf("share %d = %d, want %d", i, share.Amount, tt.want[i])
+				}
+				if share.Currency != "USD" {
+					t.Errorf("share %d currency = %q, want USD", i, share.Currency)
+				}
+				total += share.Amount
+			}
+			if anyPositive(tt.weights) && total != tt.amount {
+				t.Errorf("shares add up to %d, want %d", total, tt.amount)
+			}
+		})
+	}
+}
+
+func anyPositive(weights []int64) bool {
+	for _, weight := range weights {
+		if weight > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    int64
+		wantErr bool
+	}{
+		{"numeric bytes", []byte("12.34"), 1234, false},
+		{"numeric string", "12.34", 1234, false},
+		{"numeric without decimals", "12", 1200, false},
+		{"float", float64(12.34), 1234, false},
+		{"float32", float32(0.5), 50, false},
+		{"integer", int64(12), 1200, false},
+		{"null", nil, 0, false},
+		{"invalid numeric", []byte("twelve"), 0, true},
+		{"unsupported type", true, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Money
+			err := m.Scan(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Scan(%v) = %v, want an error", tt.value, m)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan(%v) failed: %v", tt.value, err)
+			}
+			if m.Amount != tt.want || m.Currency != DefaultCurrency {
+				t.Errorf("Scan(%v) = %d %s, want %d %s", tt.value, m.Amount, m.Currency, tt.want, DefaultCurrency)
+			}
+		})
+	}
+}
+
+func TestScanValueRoundTrip(t *testing.T) {
+	for _, original := range []Money{New(1234, "USD"), New(-5, "EUR"), New(1500, "JPY")} {
+		value, err := original.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var scanned Money
+		if err := scanned.Scan(value); err != nil {
+			t.Fatal(err)
+		}
+		if got := scanned.WithCurrency(original.Currency); got != original {
+			t.Errorf("round trip of %v %s = %v %s", original.Amount, original.Currency, got.Amount, got.Currency)
+		}
+	}
+}
+
+func TestWithCurrency(t *testing.T) {
+	tests := []struct {
+		money Money
+		to    string
+		want  Money
+	}{
+		{New(1234, "USD"), "EUR", New(1234, "EUR")},
+		{New(150000, "USD"), "JPY", New(1500, "JPY")},
+		{New(150050, "USD"), "JPY", New(1501, "JPY")},
+		{New(-150050, "USD"), "JPY", New(-1501, "JPY")},
+		{New(1500, "JPY"), "USD", New(150000, "USD")},
+		{New(1500, "JPY"), "KRW", New(1500, "KRW")},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.WithCurrency(tt.to); got != tt.want {
+			t.Errorf("%d %s in %s = %d %s, want %d %s", tt.money.Amount, tt.money.Currency, tt.to, got.Amount, got.Currency, tt.want.Amount, tt.want.Currency)
+		}
+	}
+}
+
+func TestExchange(t *testing.T) {
+	tests := []struct {
+		money Money
+		rate  float64
+		to    string
+		want  Money
+	}{
+		{New(1000, "USD"), 0.92, "EUR", New(920, "EUR")},
+		{New(1000, "USD"), 149.5, "JPY", New(1495, "JPY")},
+		{New(1495, "JPY"), 1 / 149.5, "USD", New(1000, "USD")},
+		{New(333, "USD"), 0.5, "EUR", New(167, "EUR")},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.Exchange(tt.rate, tt.to); got != tt.want {
+			t.Errorf("%d %s at %v to %s = %d %s, want %d %s", tt.money.Amount, tt.money.Currency, tt.rate, tt.to, got.Amount, got.Currency, tt.want.Amount, tt.want.Currency)
+		}
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	if got := FromFloat(19.99, "USD"); got.Amount != 1999 {
+		t.Errorf("FromFloat(19.99, USD) = %d, want 1999", got.Amount)
+	}
+	if got := FromFloat(1500.6, "JPY"); got.Amount != 1501 {
+		t.Errorf("FromFloat(1500.6, JPY) = %d, want 1501", got.Amount)
+	}
+}
@@ -0,0 +1,41 @@
+package notification
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileNotifier appends each notification as a JSON line to a file
+type FileNotifier struct {
+	FilePath string
+	mutex    sync.Mutex
+}
+
+func NewFileNotifier(filePath string) Notifier {
+	return &FileNotifier{FilePath: filePath}
+}
+
+func (n *FileNotifier) Notify(notification Notification) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	line, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(n.FilePath), os.ModePerm); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(n.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,25 @@
+package notification
+
+import (
+	"shophub-backend/logger"
+
+	"go.uber.org/zap"
+)
+
+// LogNotifier writes notifications to the activity log, intended for local development
+type LogNotifier struct{}
+
+func NewLogNotifier() Notifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(notification Notification) error {
+	logger.ActInfo("Notification",
+		zap.String("type", notification.Type),
+		zap.String("recipient", notification.Recipient),
+		zap.String("subject", notification.Subject),
+		zap.String("message", notification.Message),
+		zap.Any("data", notification.Data),
+	)
+	return nil
+}
@@ -0,0 +1,35 @@
+package notification
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	NotifierTypeLog  = "log"
+	NotifierTypeFile = "file"
+)
+
+type Notification struct {
+	Type      string                 `json:"type"`
+	Recipient string                 `json:"recipient"`
+	Subject   string                 `json:"subject"`
+	Message   string                 `json:"message"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// Notifier delivers notifications, implementations can be swapped through configuration
+type Notifier interface {
+	Notify(notification Notification) error
+}
+
+// NewNotifier returns the notifier for the configured type, falling back to the log notifier
+func NewNotifier(notifierType string, filePath string) Notifier {
+	switch strings.ToLower(notifierType) {
+	case NotifierTypeFile:
+		return NewFileNotifier(filePath)
+	default:
+		return NewLogNotifier()
+	}
+}
@@ -0,0 +1,229 @@
+package postal
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// countriesFile lists every ISO 3166 country, with the address rules of the countries we know them for
+//
+//go:embed countries.json
+var countriesFile []byte
+
+const (
+	FieldLine1      = "line1"
+	FieldLine2      = "line2"
+	FieldCity       = "city"
+	FieldPostalCode = "postal_code"
+	FieldCountry    = "country"
+	FieldRegion     = "region"
+)
+
+// defaultRequiredFields are required for countries without their own rules
+var defaultRequiredFields = []string{FieldLine1, FieldCity}
+
+type Region struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// Country is an ISO 3166 country and its address rules. Regions, when listed, are the only
+// accepted states or provinces.
+type Country struct {
+	Code              string   `json:"code"`
+	Name              string   `json:"name"`
+	Aliases           []string `json:"aliases,omitempty"`
+	RequiredFields    []string `json:"required_fields,omitempty"`
+	PostalCodePattern string   `json:"postal_code_pattern,omitempty"`
+	PostalCodeExample string   `json:"postal_code_example,omitempty"`
+	RegionLabel       string   `json:"region_label,omitempty"`
+	Regions           []Region `json:"regions,omitempty"`
+
+	postalCode *regexp.Regexp
+}
+
+// Address is an address as entered by the user
+type Address struct {
+	Line1      string
+	Line2      string
+	City       string
+	PostalCode string
+	Country    string
+	Region     string
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every invalid field of an address
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Message
+	}
+	return "invalid address: " + strings.Join(messages, "; ")
+}
+
+// WithPrefix returns the errors with the fields nested under the prefix, as in billing_address.city
+func (e *ValidationError) WithPrefix(prefix string) *ValidationError {
+	fields := make([]FieldError, len(e.Fields))
+	for i, field := range e.Fields {
+		fields[i] = FieldError{Field: prefix + "." + field.Field, Message: strings.ReplaceAll(prefix, "_", " ") + " " + field.Message}
+	}
+	return &ValidationError{Fields: fields}
+}
+
+var (
+	countries = map[string]*Country{}
+	// names maps lower case names and aliases to country codes
+	names = map[string]string{}
+)
+
+func init() {
+	var file struct {
+		Countries []Country `json:"countries"`
+	}
+	if err := json.Unmarshal(countriesFile, &file); err != nil {
+		panic(fmt.Sprintf("invalid countries file: %v", err))
+	}
+	for i := range file.Countries {
+		country := &file.Countries[i]
+		if country.PostalCodePattern != "" {
+			country.postalCode = regexp.MustCompile(country.PostalCodePattern)
+		}
+		if len(country.RequiredFields) == 0 {
+			country.RequiredFields = defaultRequiredFields
+		}
+		countries[country.Code] = country
+		names[strings.ToLower(country.Name)] = country.Code
+		for _, alias := range country.Aliases {
+			names[strings.ToLower(alias)] = country.Code
+		}
+	}
+}
+
+// LookupCountry finds a country by its ISO 3166 code, its name or a common alias
+func LookupCountry(value string) (*Country, bool) {
+	value = collapse(value)
+	if country, ok := countries[strings.ToUpper(value)]; ok {
+		return country, true
+	}
+	if code, ok := names[strings.ToLower(value)]; ok {
+		return countries[code], true
+	}
+	return nil, false
+}
+
+// NormalizeCountry returns the ISO 3166 code of the country, unknown countries are only upper cased
+func NormalizeCountry(value string) string {
+	if country, ok := LookupCountry(value); ok {
+		return country.Code
+	}
+	return strings.ToUpper(collapse(value))
+}
+
+// Countries returns every country ordered by code
+func Countries() []Country {
+	list := make([]Country, 0, len(countries))
+	for _, country := range countries {
+		list = append(list, *country)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Code < list[j].Code
+	})
+	return list
+}
+
+// Normalize trims and collapses whitespace, turns the country into its ISO 3166 code,
+// upper cases the postal code and turns known region names into their codes
+func Normalize(address Address) Address {
+	normalized := Address{
+		Line1:      collapse(address.Line1),
+		Line2:      collapse(address.Line2),
+		City:       collapse(address.City),
+		PostalCode: strings.ToUpper(collapse(address.PostalCode)),
+		Country:    NormalizeCountry(address.Country),
+		Region:     collapse(address.Region),
+	}
+	if country, ok := countries[normalized.Country]; ok {
+		if region, ok := country.region(normalized.Region); ok {
+			normalized.Region = region.Code
+		}
+	}
+	return normalized
+}
+
+// Validate normalizes the address and checks it against the rules of its country.
+// The normalized address is returned even when it is invalid, the error is a *ValidationError.
+func Validate(address Address) (Address, error) {
+	normalized := Normalize(address)
+	var fields []FieldError
+
+	if normalized.Country == "" {
+		fields = append(fields, FieldError{Field: FieldCountry, Message: "country is required"})
+		return normalized, &ValidationError{Fields: fields}
+	}
+	country, ok := countries[normalized.Country]
+	if !ok {
+		fields = append(fields, FieldError{Field: FieldCountry, Message: fmt.Sprintf("unknown country %q, use an ISO 3166 country code", address.Country)})
+		return normalized, &ValidationError{Fields: fields}
+	}
+
+	values := map[string]string{
+		FieldLine1:      normalized.Line1,
+		FieldLine2:      normalized.Line2,
+		FieldCity:       normalized.City,
+		FieldPostalCode: normalized.PostalCode,
+		FieldRegion:     normalized.Region,
+	}
+	for _, field := range country.RequiredFields {
+		if values[field] == "" {
+			fields = append(fields, FieldError{Field: field, Message: fmt.Sprintf("%s is required for %s", country.fieldName(field), country.Name)})
+		}
+	}
+
+	if normalized.PostalCode != "" && country.postalCode != nil && !country.postalCode.MatchString(normalized.PostalCode) {
+		fields = append(fields, FieldError{Field: FieldPostalCode, Message: fmt.Sprintf("postal code %q is not valid for %s, for example %s", normalized.PostalCode, country.Name, country.PostalCodeExample)})
+	}
+	if normalized.Region != "" && len(country.Regions) > 0 {
+		if _, ok := country.region(normalized.Region); !ok {
+			fields = append(fields, FieldError{Field: FieldRegion, Message: fmt.Sprintf("%s %q is not valid for %s", country.fieldName(FieldRegion), normalized.Region, country.Name)})
+		}
+	}
+
+	if len(fields) > 0 {
+		return normalized, &ValidationError{Fields: fields}
+	}
+	return normalized, nil
+}
+
+func (c *Country) region(value string) (*Region, bool) {
+	for i := range c.Regions {
+		if strings.EqualFold(c.Regions[i].Code, value) || strings.EqualFold(c.Regions[i].Name, value) {
+			return &c.Regions[i], true
+		}
+	}
+	return nil, false
+}
+
+// fieldName is how the field is called in messages, regions are called by their local name
+func (c *Country) fieldName(field string) string {
+	if field == FieldRegion && c.RegionLabel != "" {
+		return c.RegionLabel
+	}
+	return strings.ReplaceAll(field, "_", " ")
+}
+
+func collapse(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
@@ -0,0 +1,142 @@
+package postal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		address Address
+		want    Address
+		fields  []string
+	}{
+		{
+			name:    "valid US address",
+			address: Address{Line1: "1 Market St", City: "San Francisco", PostalCode: "94105", Country: "US", Region: "CA"},
+			want:    Address{Line1: "1 Market St", City: "San Francisco", PostalCode: "94105", Country: "US", Region: "CA"},
+		},
+		{
+			name:    "country names, region names and whitespace are normalized",
+			address: Address{Line1: "  1   Market St ", City: " San  Francisco", PostalCode: "94105-1234", Country: "united states of america", Region: "california"},
+			want:    Address{Line1: "1 Market St", City: "San Francisco", PostalCode: "94105-1234", Country: "US", Region: "CA"},
+		},
+		{
+			name:    "postal codes are upper cased before matching",
+			address: Address{Line1: "10 Downing St", City: "London", PostalCode: "sw1a 2aa", Country: "UK"},
+			want:    Address{Line1: "10 Downing St", City: "London", PostalCode: "SW1A 2AA", Country: "GB"},
+		},
+		{
+			name:    "country without rules only needs a street and city",
+			address: Address{Line1: "Main Road 1", City: "Reykjavik", Country: "is"},
+			want:    Address{Line1: "Main Road 1", City: "Reykjavik", Country: "IS"},
+		},
+		{
+			name:    "postal code is optional where it is not required",
+			address: Address{Line1: "1 Grafton St", City: "Dublin", Country: "IE"},
+			want:    Address{Line1: "1 Grafton St", City: "Dublin", Country: "IE"},
+		},
+		{
+			name:    "every missing required field is reported",
+			address: Address{Country: "US"},
+			want:    Address{Country: "US"},
+			fields:  []string{FieldLine1, FieldCity, FieldPostalCode, FieldRegion},
+		},
+		{
+			name:    "postal code must match the country pattern",
+			address: Address{Line1: "Unter den Linden 1", City: "Berlin", PostalCode: "1011", Country: "DE"},
+			want:    Address{Line1: "Unter den Linden 1", City: "Berlin", PostalCode: "1011", Country: "DE"},
+			fields:  []string{FieldPostalCode},
+		},
+		{
+			name:    "optional postal code is still checked when given",
+			address: Address{Line1: "1 Grafton St", City: "Dublin", PostalCode: "12345", Country: "IE"},
+			want:    Address{Line1: "1 Grafton St", City: "Dublin", PostalCode: "12345", Country: "IE"},
+			fields:  []string{FieldPostalCode},
+		},
+		{
+			name:    "region must be one of the listed regions",
+			address: Address{Line1: "1 Main St", City: "Springfield", PostalCode: "62701", Country: "US", Region: "Narnia"},
+			want:    Address{Line1: "1 Main St", City: "Springfield", PostalCode: "62701", Country: "US", Region: "Narnia"},
+			fields:  []string{FieldRegion},
+		},
+		{
+			name:    "region is free text where no regions are listed",
+			address: Address{Line1: "Av Paulista 1", City: "Sao Paulo", PostalCode: "01310-100", Country: "BR", Region: "Sao Paulo"},
+			want:    Address{Line1: "Av Paulista 1", City: "Sao Paulo", PostalCode: "01310-100", Country: "BR", Region: "Sao Paulo"},
+		},
+		{
+			name:    "missing country",
+			address: Address{Line1: "1 Main St", City: "Springfield"},
+			want:    Address{Line1: "1 Main St", City: "Springfield"},
+			fields:  []string{FieldCountry},
+		},
+		{
+			name:    "unknown country",
+			address: Address{Line1: "1 Main St", City: "Springfield", Country: "Atlantis"},
+			want:    Address{Line1: "1 Main St", City: "Springfield", Country: "ATLANTIS"},
+			fields:  []string{FieldCountry},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Validate(tt.address)
+
+			if got != tt.want {
+				t.Errorf("normalized = %+v, want %+v", got, tt.want)
+			}
+
+			if len(tt.fields) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("error = %v, want a *ValidationError", err)
+			}
+			if len(validationErr.Fields) != len(tt.fields) {
+				t.Fatalf("invalid fields = %+v, want %v", validationErr.Fields, tt.fields)
+			}
+			for i, field := range validationErr.Fields {
+				if field.Field != tt.fields[i] {
+					t.Errorf("invalid field %d = %s, want %s", i, field.Field, tt.fields[i])
+				}
+				if field.Message == "" {
+					t.Errorf("invalid field %s has no message", field.Field)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateUsesLocalRegionLabel(t *testing.T) {
+	_, err := Validate(Address{Line1: "1 Main St", City: "Toronto", PostalCode: "M5V 3L9", Country: "CA"})
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Fields) != 1 {
+		t.Fatalf("error = %v, want a missing province", err)
+	}
+	if want := "province is required for Canada"; validationErr.Fields[0].Message != want {
+		t.Errorf("message = %q, want %q", validationErr.Fields[0].Message, want)
+	}
+}
+
+func TestValidationErrorWithPrefix(t *testing.T) {
+	err := &ValidationError{Fields: []FieldError{{Field: FieldCity, Message: "city is required for Germany"}}}
+
+	prefixed := err.WithPrefix("billing_address")
+
+	if got := prefixed.Fields[0].Field; got != "billing_address.city" {
+		t.Errorf("field = %q, want billing_address.city", got)
+	}
+	if got := prefixed.Error(); got != "invalid address: billing address city is required for Germany" {
+		t.Errorf("error = %q", got)
+	}
+	if err.Fields[0].Field != FieldCity {
+		t.Errorf("the original error was changed")
+	}
+}
@@ -0,0 +1,349 @@
+package promotion
+
+import (
+	"fmt"
+	"shophub-backend/model"
+	"shophub-backend/money"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	PromotionTypeBuyXGetY    = "BUY_X_GET_Y"
+	PromotionTypeBundle      = "BUNDLE"
+	PromotionTypeTieredSpend = "TIERED_SPEND"
+)
+
+// AppliedPromotion explains a promotion that was applied to the cart
+type AppliedPromotion struct {
+	PromotionID uint        `json:"promotion_id"`
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Amount      money.Money `json:"amount"`
+	Explanation string      `json:"explanation"`
+}
+
+// Result is the outcome of evaluating the automatic promotions on a cart
+type Result struct {
+	Discount
+	Applied []AppliedPromotion
+}
+
+// Evaluate applies the automatic promotions to the lines with these stacking rules:
+//   - promotions are evaluated by priority (highest first), then by promotion ID
+//   - buy X get Y and bundle promotions consume the units they discount, so a unit
+//     is discounted by at most one of them
+//   - tiered spend promotions apply to the cart total left after earlier promotions
+//   - an exclusive promotion only applies when nothing else has, and stops the evaluation
+func Evaluate(promotions []model.Promotion, lines []Line, now time.Time) *Result {
+	ordered := make([]model.Promotion, len(promotions))
+	copy(ordered, promotions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Priority != ordered[j].Priority {
+			return ordered[i].Priority > ordered[j].Priority
+		}
+		return ordered[i].PromotionID < ordered[j].PromotionID
+	})
+
+	state := newEvaluation(lines)
+	result := &Result{
+		Discount: Discount{Amount: money.Zero(linesCurrency(lines)), LineAmounts: map[uint]money.Money{}},
+		Applied:  []AppliedPromotion{},
+	}
+
+	for _, promo := range ordered {
+		if !isLive(promo, now) {
+			continue
+		}
+		if promo.Exclusive && len(result.Applied) > 0 {
+			continue
+		}
+
+		var applied *AppliedPromotion
+		switch promo.Type {
+		case PromotionTypeBuyXGetY:
+			applied = state.applyBuyXGetY(promo)
+		case PromotionTypeBundle:
+			applied = state.applyBundle(promo)
+		case PromotionTypeTieredSpend:
+			applied = state.applyTieredSpend(promo)
+		}
+		if applied == nil {
+			continue
+		}
+
+		result.Applied = append(result.Applied, *applied)
+		if promo.Exclusive {
+			break
+		}
+	}
+
+	for _, line := range lines {
+		amount, ok := state.discounts[line.ItemID]
+		if !ok || !amount.IsPositive() {
+			continue
+		}
+		result.LineAmounts[line.ItemID] = amount
+		result.Amount = result.Amount.Add(amount)
+	}
+
+	return result
+}
+
+// DiscountedLines returns the lines with their unit price reduced by the discount,
+// used to evaluate a coupon on what is left after the automatic promotions
+func DiscountedLines(lines []Line, discount Discount) []Line {
+	discounted := make([]Line, len(lines))
+	for i, line := range lines {
+		discounted[i] = line
+		if amount := discount.LineAmounts[line.ItemID]; amount.IsPositive() && line.Quantity > 0 {
+			// Fold the discount into a single line so no rounding is lost on the unit price
+			discounted[i].UnitPrice = line.Total().Sub(amount)
+			discounted[i].Quantity = 1
+		}
+	}
+	return discounted
+}
+
+func isLive(promo model.Promotion, now time.Time) bool {
+	if !promo.IsActive {
+		return false
+	}
+	if promo.StartsAt != nil && now.Before(*promo.StartsAt) {
+		return false
+	}
+	if promo.EndsAt != nil && now.After(*promo.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// evaluation tracks the units still available to item level promotions and the discount per line
+type evaluation struct {
+	lines     []Line
+	remaining map[uint]int
+	discounts map[uint]money.Money
+}
+
+func newEvaluation(lines []Line) *evaluation {
+	state := &evaluation{
+		lines:     lines,
+		remaining: make(map[uint]int, len(lines)),
+		discounts: make(map[uint]money.Money, len(lines)),
+	}
+	for _, line := range lines {
+		state.remaining[line.ItemID] = line.Quantity
+	}
+	return state
+}
+
+// lineTotal is the line total left after the discounts applied so far
+func (e *evaluation) lineTotal(line Line) money.Money {
+	return line.Total().Sub(e.discounts[line.ItemID])
+}
+
+func (e *evaluation) addDiscount(itemID uint, amount money.Money) {
+	e.discounts[itemID] = e.discounts[itemID].Add(amount)
+}
+
+type unit struct {
+	itemID uint
+	price  money.Money
+}
+
+// applyBuyXGetY groups the scoped units from most to least expensive into groups of
+// buy+get units and discounts the cheapest get units of every full group
+func (e *evaluation) applyBuyXGetY(promo model.Promotion) *AppliedPromotion {
+	if promo.BuyQuantity <= 0 || promo.GetQuantity <= 0 || promo.GetDiscountPercent <= 0 {
+		return nil
+	}
+
+	var units []unit
+	for _, line := range e.lines {
+		if !promotionInScope(promo, line) {
+			continue
+		}
+		for i := 0; i < e.remaining[line.ItemID]; i++ {
+			units = append(units, unit{itemID: line.ItemID, price: line.UnitPrice})
+		}
+	}
+	sort.SliceStable(units, func(i, j int) bool {
+		if !units[i].price.Equal(units[j].price) {
+			return units[i].price.GreaterThan(units[j].price)
+		}
+		return units[i].itemID < units[j].itemID
+	})
+
+	groupSize := promo.BuyQuantity + promo.GetQuantity
+	groups := len(units) / groupSize
+	if groups == 0 {
+		return nil
+	}
+
+	amount := money.Zero(units[0].price.Currency)
+	for g := 0; g < groups; g++ {
+		group := units[g*groupSize : (g+1)*groupSize]
+		for i, u := range group {
+			e.remaining[u.itemID]--
+			if i >= promo.BuyQuantity {
+				discount := u.price.Percent(promo.GetDiscountPercent)
+				e.addDiscount(u.itemID, discount)
+				amount = amount.Add(discount)
+			}
+		}
+	}
+
+	return &AppliedPromotion{
+		PromotionID: promo.PromotionID,
+		Name:        promo.Name,
+		Type:        promo.Type,
+		Amount:      amount,
+		Explanation: fmt.Sprintf("Buy %d get %d at %s%% off, applied %d time(s)",
+			promo.BuyQuantity, promo.GetQuantity, formatPercent(promo.GetDiscountPercent), groups),
+	}
+}
+
+// applyBundle sells one unit of every scoped product together for the bundle price
+func (e *evaluation) applyBundle(promo model.Promotion) *AppliedPromotion {
+	if len(promo.Products) == 0 {
+		return nil
+	}
+
+	var components []Line
+	bundles := -1
+	for _, product := range promo.Products {
+		line, ok := e.lineForProduct(product.ProductID)
+		if !ok {
+			return nil
+		}
+		components = append(components, line)
+		if bundles == -1 || e.remaining[line.ItemID] < bundles {
+			bundles = e.remaining[line.ItemID]
+		}
+	}
+	if bundles <= 0 {
+		return nil
+	}
+
+	var unitLines []Line
+	var names []string
+	for _, line := range components {
+		unitLines = append(unitLines, Line{ItemID: line.ItemID, Quantity: 1, UnitPrice: line.UnitPrice})
+		names = append(names, fmt.Sprintf("#%d", line.ProductID))
+	}
+	saving := linesTotal(unitLines).Sub(promo.BundlePrice)
+	if !saving.IsPositive() {
+		return nil
+	}
+
+	// Each bundle's saving is spread over its components by price
+	amount := money.Zero(saving.Currency)
+	for itemID, share := range allocate(saving, unitLines) {
+		discount := share.Mul(bundles)
+		e.addDiscount(itemID, discount)
+		e.remaining[itemID] -= bundles
+		amount = amount.Add(discount)
+	}
+
+	return &AppliedPromotion{
+		PromotionID: promo.PromotionID,
+		Name:        promo.Name,
+		Type:        promo.Type,
+		Amount:      amount,
+		Explanation: fmt.Sprintf("Bundle of products %s for %s, applied %d time(s)",
+			strings.Join(names, ", "), promo.BundlePrice, bundles),
+	}
+}
+
+// applyTieredSpend applies the highest tier reached by the scoped spend left after earlier promotions
+func (e *evaluation) applyTieredSpend(promo model.Promotion) *AppliedPromotion {
+	// What is left of the scoped lines after earlier promotions
+	var remainingLines []Line
+	for _, line := range e.lines {
+		if !promotionInScope(promo, line) {
+			continue
+		}
+		if total := e.lineTotal(line); total.IsPositive() {
+			remainingLines = append(remainingLines, Line{ItemID: line.ItemID, Quantity: 1, UnitPrice: total})
+		}
+	}
+	spend := linesTotal(remainingLines)
+	if !spend.IsPositive() {
+		return nil
+	}
+
+	var tier *model.PromotionTier
+	for i := range promo.Tiers {
+		candidate := &promo.Tiers[i]
+		if spend.LessThan(candidate.MinSpend) {
+			continue
+		}
+		if tier == nil || candidate.MinSpend.GreaterThan(tier.MinSpend) {
+			tier = candidate
+		}
+	}
+	if tier == nil {
+		return nil
+	}
+
+	var amount money.Money
+	var explanation string
+	switch tier.DiscountType {
+	case DiscountTypePercentage:
+		amount = spend.Percent(tier.DiscountValue)
+		explanation = fmt.Sprintf("Spend %s or more, get %s%% off", tier.MinSpend, formatPercent(tier.DiscountValue))
+	case DiscountTypeFixed:
+		amount = money.FromFloat(tier.DiscountValue, spend.Currency)
+		explanation = fmt.Sprintf("Spend %s or more, get %s off", tier.MinSpend, amount)
+	default:
+		return nil
+	}
+	amount = money.Min(amount, spend)
+	if !amount.IsPositive() {
+		return nil
+	}
+
+	// Spread over the scoped lines by what is left of their totals
+	for itemID, share := range allocate(amount, remainingLines) {
+		e.addDiscount(itemID, share)
+	}
+
+	return &AppliedPromotion{
+		PromotionID: promo.PromotionID,
+		Name:        promo.Name,
+		Type:        promo.Type,
+		Amount:      amount,
+		Explanation: explanation,
+	}
+}
+
+func (e *evaluation) lineForProduct(productID uint) (Line, bool) {
+	for _, line := range e.lines {
+		if line.ProductID == productID {
+			return line, true
+		}
+	}
+	return Line{}, false
+}
+
+func promotionInScope(promo model.Promotion, line Line) bool {
+	if len(promo.Products) == 0 && len(promo.Categories) == 0 {
+		return true
+	}
+	for _, product := range promo.Products {
+		if product.ProductID == line.ProductID {
+			return true
+		}
+	}
+	for _, category := range promo.Categories {
+		if category.CategoryID == line.CategoryID {
+			return true
+		}
+	}
+	return false
+}
+
+func formatPercent(percent float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", percent), "0"), ".")
+}
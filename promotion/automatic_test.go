@@ -0,0 +1,325 @@
+package promotion
+
+import (
+	"shophub-backend/model"
+	"shophub-backend/money"
+	"testing"
+	"time"
+)
+
+func usd(value string) money.Money {
+	amount, err := money.Parse(value, "USD")
+	if err != nil {
+		panic(err)
+	}
+	return amount
+}
+
+func line(itemID uint, productID uint, quantity int, price string) Line {
+	return Line{ItemID: itemID, ProductID: productID, CategoryID: 1, Quantity: quantity, UnitPrice: usd(price)}
+}
+
+func products(ids ...uint) []model.Product {
+	var scoped []model.Product
+	for _, id := range ids {
+		scoped = append(scoped, model.Product{ProductID: id})
+	}
+	return scoped
+}
+
+func buyXGetY(id uint, priority int, buy int, get int, percent float64, productIds ...uint) model.Promotion {
+	return model.Promotion{
+		PromotionID: id, Name: "buy x get y", Type: PromotionTypeBuyXGetY, Priority: priority, IsActive: true,
+		BuyQuantity: buy, GetQuantity: get, GetDiscountPercent: percent, Products: products(productIds...),
+	}
+}
+
+func bundle(id uint, priority int, price string, productIds ...uint) model.Promotion {
+	return model.Promotion{
+		PromotionID: id, Name: "bundle", Type: PromotionTypeBundle, Priority: priority, IsActive: true,
+		BundlePrice: usd(price), Products: products(productIds...),
+	}
+}
+
+func tiered(id uint, priority int, tiers ...model.PromotionTier) model.Promotion {
+	return model.Promotion{
+		PromotionID: id, Name: "tiered", Type: PromotionTypeTieredSpend, Priority: priority, IsActive: true,
+		Tiers: tiers,
+	}
+}
+
+func tier(minSpend string, discountType string, value float64) model.PromotionTier {
+	return model.PromotionTier{MinSpend: usd(minSpend), DiscountType: discountType, DiscountValue: value}
+}
+
+func exclusive(promo model.Promotion) model.Promotion {
+	promo.Exclusive = true
+	return promo
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		promotions  []model.Promotion
+		lines       []Line
+		amount      string
+		applied     []uint
+		lineAmounts map[uint]string
+	}{
+		{
+			name:        "no promotions",
+			lines:       []Line{line(1, 10, 2, "10.00")},
+			amount:      "0.00",
+			lineAmounts: map[uint]string{},
+		},
+		{
+			name:        "buy two get one free groups units from most to least expensive",
+			promotions:  []model.Promotion{buyXGetY(1, 0, 2, 1, 100)},
+			lines:       []Line{line(1, 10, 3, "10.00"), line(2, 20, 3, "5.00")},
+			amount:      "15.00",
+			applied:     []uint{1},
+			lineAmounts: map[uint]string{1: "10.00", 2: "5.00"},
+		},
+		{
+			name:        "buy one get one half off only discounts full groups",
+			promotions:  []model.Promotion{buyXGetY(1, 0, 1, 1, 50)},
+			lines:       []Line{line(1, 10, 3, "10.00")},
+			amount:      "5.00",
+			applied:     []uint{1},
+			lineAmounts: map[uint]string{1: "5.00"},
+		},
+		{
+			name:        "buy x get y discounts the cheapest unit of a mixed group",
+			promotions:  []model.Promotion{buyXGetY(1, 0, 1, 1, 100)},
+			lines:       []Line{line(1, 10, 1, "30.00"), line(2, 20, 1, "12.00")},
+			amount:      "12.00",
+			applied:     []uint{1},
+			lineAmounts: map[uint]string{2: "12.00"},
+		},
+		{
+			name:        "buy x get y only counts scoped products",
+			promotions:  []model.Promotion{buyXGetY(1, 0, 1, 1, 100, 10)},
+			lines:       []Line{line(1, 10, 1, "30.00"), line(2, 20, 1, "12.00")},
+			amount:      "0.00",
+			lineAmounts: map[uint]string{},
+		},
+		{
+			name:        "bundle spreads the saving over its components by price",
+			promotions:  []model.Promotion{bundle(1, 0, "40.00", 10, 20)},
+			lines:       []Line{line(1, 10, 2, "30.00"), line(2, 20, 2, "20.00")},
+			amount:      "20.00",
+			applied:     []uint{1},
+			lineAmounts: map[uint]string{1: "12.00", 2: "8.00"},
+		},
+		{
+			name:        "bundle is limited by the scarcest component",
+			promotions:  []model.Promotion{bundle(1, 0, "40.00", 10, 20)},
+			lines:       []Line{line(1, 10, 3, "30.00"), line(2, 20, 1, "20.00")},
+			amount:      "10.00",
+			applied:     []uint{1},
+			lineAmounts: map[uint]string{1: "6.00", 2: "4.00"},
+		},
+		{
+			name:        "bundle needs every component in the cart",
+			promotions:  []model.Promotion{bundle(1, 0, "40.00", 10, 20)},
+			lines:       []Line{line(1, 10, 2, "30.00")},
+			amount:      "0.00",
+			lineAmounts: map[uint]string{},
+		},
+		{
+			name:        "bundle that does not save anything is not applied",
+			promotions:  []model.Promotion{bundle(1, 0, "60.00", 10, 20)},
+			lines:       []Line{line(1, 10, 1, "30.00"), line(2, 20, 1, "20.00")},
+			amount:      "0.00",
+			lineAmounts: map[uint]string{},
+		},
+		{
+			name: "tiered spend applies the highest tier reached",
+			promotions: []model.Promotion{tiered(1, 0,
+				tier("50.00", DiscountTypePercentage, 5),
+				tier("100.00", DiscountTypePercentage, 10),
+				tier("200.00", DiscountTypePercentage, 20),
+			)},
+			lines:       []Line{line(1, 10, 2, "40.00"), line(2, 20, 1, "40.00")},
+			amount:      "12.00",
+			applied:     []uint{1},
+			lineAmounts: map[uint]string{1: "8.00", 2: "4.00"},
+		},
+		{
+			name:        "tiered spend with a fixed discount",
+			promotions:  []model.Promotion{tiered(1, 0, tier("50.00", DiscountTypeFixed, 15))},
+			lines:       []Line{line(1, 10, 1, "60.00")},
+			amount:      "15.00",
+			applied:     []uint{1},
+			lineAmounts: map[uint]string{1: "15.00"},
+		},
+		{
+			name:        "tiered spend below the lowest tier",
+			promotions:  []model.Promotion{tiered(1, 0, tier("50.00", DiscountTypeFixed, 15))},
+			lines:       []Line{line(1, 10, 1, "49.99")},
+			amount:      "0.00",
+			lineAmounts: map[uint]string{},
+		},
+		{
+			name:        "fixed tier is capped at the spend",
+			promotions:  []model.Promotion{tiered(1, 0, tier("0.00", DiscountTypeFixed, 15))},
+			lines:       []Line{line(1, 10, 1, "10.00")},
+			amount:      "10.00",
+			applied:     []uint{1},
+			lineAmounts: map[uint]string{1: "10.00"},
+		},
+		{
+			name: "higher priority item promotion runs first and tiered spend stacks on what is left",
+			promotions: []model.Promotion{
+				tiered(1, 1, tier("20.00", DiscountTypePercentage, 10)),
+				buyXGetY(2, 10, 1, 1, 100, 10),
+			},
+			lines:       []Line{line(1, 10, 2, "10.00"), line(2, 20, 1, "15.00")},
+			amount:      "12.50",
+			applied:     []uint{2, 1},
+			lineAmounts: map[uint]string{1: "11.00", 2: "1.50"},
+		},
+		{
+			name: "higher priority tiered spend runs on the full cart",
+			promotions: []model.Promotion{
+				tiered(1, 10, tier("20.00", DiscountTypePercentage, 10)),
+				buyXGetY(2, 1, 1, 1, 100, 10),
+			},
+			lines:       []Line{line(1, 10, 2, "10.00"), line(2, 20, 1, "15.00")},
+			amount:      "13.50",
+			applied:     []uint{1, 2},
+			lineAmounts: map[uint]string{1: "12.00", 2: "1.50"},
+		},
+		{
+			name: "same priority is evaluated by promotion id",
+			promotions: []model.Promotion{
+				bundle(2, 0, "15.00", 10, 20),
+				buyXGetY(1, 0, 1, 1, 100, 10),
+			},
+			lines:       []Line{line(1, 10, 2, "10.00"), line(2, 20, 1, "10.00")},
+			amount:      "10.00",
+			applied:     []uint{1},
+			lineAmounts: map[uint]string{1: "10.00"},
+		},
+		{
+			name: "units discounted by one item promotion are not discounted by another",
+			promotions: []model.Promotion{
+				buyXGetY(1, 10, 1, 1, 100, 10),
+				bundle(2, 0, "15.00", 10, 20),
+			},
+			lines:       []Line{line(1, 10, 3, "10.00"), line(2, 20, 1, "10.00")},
+			amount:      "15.00",
+			applied:     []uint{1, 2},
+			lineAmounts: map[uint]string{1: "12.50", 2: "2.50"},
+		},
+		{
+			name: "exclusive promotion first stops the evaluation",
+			promotions: []model.Promotion{
+				exclusive(buyXGetY(1, 10, 1, 1, 100)),
+				tiered(2, 0, tier("0.00", DiscountTypePercentage, 10)),
+			},
+			lines:       []Line{line(1, 10, 2, "10.00")},
+			amount:      "10.00",
+			applied:     []uint{1},
+			lineAmounts: map[uint]string{1: "10.00"},
+		},
+		{
+			name: "exclusive promotion is skipped once another applied",
+			promotions: []model.Promotion{
+				tiered(1, 10, tier("0.00", DiscountTypePercentage, 10)),
+				exclusive(buyXGetY(2, 0, 1, 1, 100)),
+			},
+			lines:       []Line{line(1, 10, 2, "10.00")},
+			amount:      "2.00",
+			applied:     []uint{1},
+			lineAmounts: map[uint]string{1: "2.00"},
+		},
+		{
+			name: "exclusive promotion that does not apply lets the others through",
+			promotions: []model.Promotion{
+				exclusive(bundle(1, 10, "15.00", 10, 20)),
+				tiered(2, 0, tier("0.00", DiscountTypePercentage, 10)),
+			},
+			lines:       []Line{line(1, 10, 2, "10.00")},
+			amount:      "2.00",
+			applied:     []uint{2},
+			lineAmounts: map[uint]string{1: "2.00"},
+		},
+		{
+			name: "inactive and out of window promotions are ignored",
+			promotions: []model.Promotion{
+				func() model.Promotion { p := buyXGetY(1, 0, 1, 1, 100); p.IsActive = false; return p }(),
+				func() model.Promotion { p := buyXGetY(2, 0, 1, 1, 100); p.StartsAt = &future; return p }(),
+				func() model.Promotion { p := buyXGetY(3, 0, 1, 1, 100); p.EndsAt = &past; return p }(),
+			},
+			lines:       []Line{line(1, 10, 2, "10.00")},
+			amount:      "0.00",
+			lineAmounts: map[uint]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Evaluate(tt.promotions, tt.lines, now)
+
+			if got := result.Amount.String(); got != tt.amount {
+				t.Errorf("amount = %s, want %s", got, tt.amount)
+			}
+
+			var applied []uint
+			for _, promo := range result.Applied {
+				applied = append(applied, promo.PromotionID)
+			}
+			if len(applied) != len(tt.applied) {
+				t.Fatalf("applied = %v, want %v", applied, tt.applied)
+			}
+			for i := range applied {
+				if applied[i] != tt.applied[i] {
+					t.Fatalf("applied = %v, want %v", applied, tt.applied)
+				}
+			}
+
+			if len(result.LineAmounts) != len(tt.lineAmounts) {
+				t.Errorf("line amounts = %v, want %v", result.LineAmounts, tt.lineAmounts)
+			}
+			for itemID, want := range tt.lineAmounts {
+				if got := result.LineAmounts[itemID].String(); got != want {
+					t.Errorf("line %d amount = %s, want %s", itemID, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateDoesNotReorderPromotions(t *testing.T) {
+	promotions := []model.Promotion{
+		tiered(1, 0, tier("0.00", DiscountTypePercentage, 10)),
+		buyXGetY(2, 10, 1, 1, 100),
+	}
+	Evaluate(promotions, []Line{line(1, 10, 2, "10.00")}, time.Now())
+
+	if promotions[0].PromotionID != 1 || promotions[1].PromotionID != 2 {
+		t.Errorf("promotions were reordered")
+	}
+}
+
+func TestDiscountedLines(t *testing.T) {
+	lines := []Line{line(1, 10, 3, "10.00"), line(2, 20, 1, "5.00")}
+	discount := Discount{Amount: usd("1.00"), LineAmounts: map[uint]money.Money{1: usd("1.00")}}
+
+	discounted := DiscountedLines(lines, discount)
+
+	if got := discounted[0]; got.Quantity != 1 || got.UnitPrice.String() != "29.00" {
+		t.Errorf("discounted line = %d x %s, want 1 x 29.00", got.Quantity, got.UnitPrice)
+	}
+	if got := discounted[1]; got.Quantity != 1 || got.UnitPrice.String() != "5.00" {
+		t.Errorf("undiscounted line = %d x %s, want 1 x 5.00", got.Quantity, got.UnitPrice)
+	}
+	if lines[0].Quantity != 3 {
+		t.Errorf("original lines were changed")
+	}
+}
@@ -0,0 +1,141 @@
+package promotion
+
+import (
+	"fmt"
+	"shophub-backend/model"
+	"shophub-backend/money"
+	"time"
+)
+
+const (
+	DiscountTypePercentage = "PERCENTAGE"
+	DiscountTypeFixed      = "FIXED"
+)
+
+// Line is a priced cart line that promotions are evaluated against
+type Line struct {
+	ItemID     uint
+	ProductID  uint
+	CategoryID uint
+	Quantity   int
+	UnitPrice  money.Money
+}
+
+func (l Line) Total() money.Money {
+	return l.UnitPrice.Mul(l.Quantity)
+}
+
+// Discount is the result of applying a promotion, LineAmounts is keyed by Line.ItemID
+type Discount struct {
+	Amount      money.Money
+	LineAmounts map[uint]money.Money
+}
+
+// linesTotal adds up the line totals
+func linesTotal(lines []Line) money.Money {
+	total := money.Zero(linesCurrency(lines))
+	for _, line := range lines {
+		total = total.Add(line.Total())
+	}
+	return total
+}
+
+// linesCurrency is the currency the lines are priced in
+func linesCurrency(lines []Line) string {
+	for _, line := range lines {
+		if line.UnitPrice.Currency != "" {
+			return line.UnitPrice.Currency
+		}
+	}
+	return money.DefaultCurrency
+}
+
+// ValidateCoupon checks the parts of a coupon that do not depend on the cart or the user
+func ValidateCoupon(coupon *model.Coupon, now time.Time) error {
+	if !coupon.IsActive {
+		return fmt.Errorf("coupon is not active")
+	}
+	if coupon.StartsAt != nil && now.Before(*coupon.StartsAt) {
+		return fmt.Errorf("coupon is not valid yet")
+	}
+	if coupon.EndsAt != nil && now.After(*coupon.EndsAt) {
+		return fmt.Errorf("coupon has expired")
+	}
+	if coupon.UsageLimit > 0 && coupon.UsedCount >= coupon.UsageLimit {
+		return fmt.Errorf("coupon usage limit reached")
+	}
+	return nil
+}
+
+// CouponDiscount calculates the discount of a coupon on the given lines.
+// The minimum order value is checked against the whole order, while the discount
+// only applies to the lines in the coupon's product or category scope.
+func CouponDiscount(coupon *model.Coupon, lines []Line) (*Discount, error) {
+	if linesTotal(lines).LessThan(coupon.MinOrderValue) {
+		return nil, fmt.Errorf("coupon requires a minimum order value of %s", coupon.MinOrderValue)
+	}
+
+	var eligible []Line
+	for _, line := range lines {
+		if inScope(coupon, line) {
+			eligible = append(eligible, line)
+		}
+	}
+
+	eligibleSubtotal := linesTotal(eligible)
+	if len(eligible) == 0 || !eligibleSubtotal.IsPositive() {
+		return nil, fmt.Errorf("coupon does not apply to any items in the cart")
+	}
+
+	var amount money.Money
+	switch coupon.DiscountType {
+	case DiscountTypePercentage:
+		amount = eligibleSubtotal.Percent(coupon.DiscountValue)
+	case DiscountTypeFixed:
+		amount = money.FromFloat(coupon.DiscountValue, eligibleSubtotal.Currency)
+	default:
+		return nil, fmt.Errorf("coupon has an unsupported discount type")
+	}
+
+	if coupon.MaxDiscountAmount.IsPositive() {
+		amount = money.Min(amount, coupon.MaxDiscountAmount)
+	}
+	amount = money.Min(amount, eligibleSubtotal)
+
+	return &Discount{
+		Amount:      amount,
+		LineAmounts: allocate(amount, eligible),
+	}, nil
+}
+
+func inScope(coupon *model.Coupon, line Line) bool {
+	if len(coupon.Products) == 0 && len(coupon.Categories) == 0 {
+		return true
+	}
+	for _, product := range coupon.Products {
+		if product.ProductID == line.ProductID {
+			return true
+		}
+	}
+	for _, category := range coupon.Categories {
+		if category.CategoryID == line.CategoryID {
+			return true
+		}
+	}
+	return false
+}
+
+// allocate spreads the amount over the lines proportionally to their totals,
+// the shares always add up to the amount exactly
+func allocate(amount money.Money, lines []Line) map[uint]money.Money {
+	weights := make([]money.Money, len(lines))
+	for i, line := range lines {
+		weights[i] = line.Total()
+	}
+
+	shares := make(map[uint]money.Money, len(lines))
+	for i, share := range amount.Allocate(weights) {
+		shares[lines[i].ItemID] = shares[lines[i].ItemID].Add(share)
+	}
+	return shares
+}
@@ -0,0 +1,46 @@
+package promotion
+
+import (
+	"shophub-backend/model"
+	"shophub-backend/money"
+)
+
+// Pricing is the combined result of the automatic promotions and the coupon on a set of lines.
+// Automatic promotions are applied first and the coupon is evaluated on what is left.
+type Pricing struct {
+	Subtotal          money.Money
+	AutomaticDiscount money.Money
+	CouponDiscount    money.Money
+	DiscountAmount    money.Money
+	LineDiscounts     map[uint]money.Money
+	Applied           []AppliedPromotion
+	Coupon            *model.Coupon
+	CouponError       string
+}
+
+// NewPricing combines the automatic promotion result with an optional coupon discount
+func NewPricing(lines []Line, automatic *Result, coupon *model.Coupon, couponDiscount *Discount) *Pricing {
+	currency := linesCurrency(lines)
+	pricing := &Pricing{
+		Subtotal:          linesTotal(lines),
+		LineDiscounts:     map[uint]money.Money{},
+		Applied:           automatic.Applied,
+		AutomaticDiscount: automatic.Amount,
+		CouponDiscount:    money.Zero(currency),
+		Coupon:            coupon,
+	}
+
+	for _, line := range lines {
+		pricing.LineDiscounts[line.ItemID] = money.Zero(currency).Add(automatic.LineAmounts[line.ItemID])
+	}
+
+	if couponDiscount != nil {
+		pricing.CouponDiscount = couponDiscount.Amount
+		for itemID, amount := range couponDiscount.LineAmounts {
+			pricing.LineDiscounts[itemID] = pricing.LineDiscounts[itemID].Add(amount)
+		}
+	}
+
+	pricing.DiscountAmount = pricing.AutomaticDiscount.Add(pricing.CouponDiscount)
+	return pricing
+}
@@ -0,0 +1,87 @@
+package recommendation
+
+import (
+	"sort"
+	"time"
+)
+
+// Purchase is a product bought by a customer
+type Purchase struct {
+	UserID    string
+	ProductID uint
+	At        time.Time
+}
+
+// Pair counts the baskets in which the related product was bought together with the product
+type Pair struct {
+	ProductID        uint
+	RelatedProductID uint
+	Count            int
+}
+
+// Baskets groups the purchases of each customer that follow each other within the window, a checkout
+// places one order per product so the orders of one checkout end up in the same basket
+func Baskets(purchases []Purchase, window time.Duration) [][]uint {
+	sorted := make([]Purchase, len(purchases))
+	copy(sorted, purchases)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].UserID != sorted[j].UserID {
+			return sorted[i].UserID < sorted[j].UserID
+		}
+		return sorted[i].At.Before(sorted[j].At)
+	})
+
+	var baskets [][]uint
+	var basket []uint
+	seen := map[uint]bool{}
+	for i, purchase := range sorted {
+		if i > 0 && (purchase.UserID != sorted[i-1].UserID || purchase.At.Sub(sorted[i-1].At) > window) {
+			baskets = append(baskets, basket)
+			basket = nil
+			seen = map[uint]bool{}
+		}
+		if !seen[purchase.ProductID] {
+			seen[purchase.ProductID] = true
+			basket = append(basket, purchase.ProductID)
+		}
+	}
+	if len(basket) > 0 {
+		baskets = append(baskets, basket)
+	}
+	return baskets
+}
+
+// CoPurchases counts for every product how often each other product was in the same basket, in both
+// directions. Pairs bought together fewer than minCount times are left out. The pairs of a product
+// come most bought together first.
+func CoPurchases(purchases []Purchase, window time.Duration, minCount int) []Pair {
+	type key struct{ product, related uint }
+	counts := map[key]int{}
+	for _, basket := range Baskets(purchases, window) {
+		for _, product := range basket {
+			for _, related := range basket {
+				if product != related {
+					counts[key{product, related}]++
+				}
+			}
+		}
+	}
+
+	pairs := make([]Pair, 0, len(counts))
+	for k, count := range counts {
+		if count < minCount {
+			continue
+		}
+		pairs = append(pairs, Pair{ProductID: k.product, RelatedProductID: k.related, Count: count})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].ProductID != pairs[j].ProductID {
+			return pairs[i].ProductID < pairs[j].ProductID
+		}
+		if pairs[i].Count != pairs[j].Count {
+			return pairs[i].Count > pairs[j].Count
+		}
+		return pairs[i].RelatedProductID < pairs[j].RelatedProductID
+	})
+	return pairs
+}
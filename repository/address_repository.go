@@ -8,8 +8,12 @@ import (
 
 type AddressRepository interface {
 	GetAddressesByUser(keycloakUserID string) ([]model.Address, error)
+	CountAddressesByUser(keycloakUserID string) (int64, error)
 	CreateAddress(address *model.Address) error
-	//GetAddressById(addressId uint) (*model.Address, error)
+	GetAddressById(addressId uint) (*model.Address, error)
+	UpdateAddress(address *model.Address) error
+	DeleteAddress(addressId uint) error
+	SetDefaultAddress(keycloakUserID string, addressId uint, column string) error
 }
 
 type AddressRepositoryImpl struct {
@@ -22,10 +26,17 @@ func NewAddressRepository(Db *gorm.DB) AddressRepository {
 
 func (r *AddressRepositoryImpl) GetAddressesByUser(keycloakUserID string) ([]model.Address, error) {
 	var addresses []model.Address
-	err := r.Db.Where("keycloak_user_id=?", keycloakUserID).Find(&addresses).Error
+	err := r.Db.Where("keycloak_user_id=? AND unlisted = ?", keycloakUserID, false).Order("address_id ASC").Find(&addresses).Error
 	return addresses, err
 }
 
+// Counting the addresses in the address book of the user, deleted and unlisted ones are not counted
+func (r *AddressRepositoryImpl) CountAddressesByUser(keycloakUserID string) (int64, error) {
+	var count int64
+	err := r.Db.Model(&model.Address{}).Where("keycloak_user_id=? AND unlisted = ?", keycloakUserID, false).Count(&count).Error
+	return count, err
+}
+
 func (r *AddressRepositoryImpl) CreateAddress(address *model.Address) error {
 	return r.Db.Create(address).Error
 }
@@ -35,3 +46,55 @@ func (r *AddressRepositoryImpl) GetAddressById(addressId uint) (*model.Address,
 	err := r.Db.First(&address, addressId).Error
 	return &address, err
 }
+
+func (r *AddressRepositoryImpl) UpdateAddress(address *model.Address) error {
+	return r.Db.Save(address).Error
+}
+
+// Soft deleting the address, it stops being a default address and the most recent remaining
+// address of the user becomes the default instead
+func (r *AddressRepositoryImpl) DeleteAddress(addressId uint) error {
+	return r.Db.Transaction(func(tx *gorm.DB) error {
+		var address model.Address
+		if err := tx.First(&address, addressId).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&address).
+			Updates(map[string]interface{}{"is_default_shipping": false, "is_default_billing": false}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.Address{}, addressId).Error; err != nil {
+			return err
+		}
+
+		for column, wasDefault := range map[string]bool{
+			"is_default_shipping": address.IsDefaultShipping,
+			"is_default_billing":  address.IsDefaultBilling,
+		} {
+			if !wasDefault {
+				continue
+			}
+			if err := tx.Model(&model.Address{}).
+				Where("address_id = (?)", tx.Model(&model.Address{}).
+					Select("address_id").
+					Where("keycloak_user_id=? AND unlisted = ?", address.KeycloakUserID, false).
+					Order("address_id DESC").
+					Limit(1)).
+				Update(column, true).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Making the address the only default of the user for the column, is_default_shipping or is_default_billing
+func (r *AddressRepositoryImpl) SetDefaultAddress(keycloakUserID string, addressId uint, column string) error {
+	return r.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Address{}).Where("keycloak_user_id=? AND "+column+" = ?", keycloakUserID, true).
+			Update(column, false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Address{}).Where("address_id=?", addressId).Update(column, true).Error
+	})
+}
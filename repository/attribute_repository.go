@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"shophub-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type AttributeRepository interface {
+	CategoryExists(categoryId uint) (bool, error)
+	CreateAttribute(attribute *model.AttributeDefinition) error
+	GetAttributesByCategory(categoryId uint) ([]model.AttributeDefinition, error)
+	GetFilterableAttributes(categoryId *uint) ([]model.AttributeDefinition, error)
+	ReplaceProductAttributes(productId uint, values []model.ProductAttributeValue) error
+	GetProductAttributes(productId uint) ([]model.ProductAttributeValue, error)
+}
+
+type AttributeRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+func NewAttributeRepository(Db *gorm.DB) AttributeRepository {
+	return &AttributeRepositoryImpl{Db: Db}
+}
+
+func (r *AttributeRepositoryImpl) CategoryExists(categoryId uint) (bool, error) {
+	var count int64
+	err := r.Db.Model(&model.Category{}).Where("category_id=?", categoryId).Count(&count).Error
+	return count > 0, err
+}
+
+// Creating the attribute together with its allowed values
+func (r *AttributeRepositoryImpl) CreateAttribute(attribute *model.AttributeDefinition) error {
+	return r.Db.Create(attribute).Error
+}
+
+func (r *AttributeRepositoryImpl) GetAttributesByCategory(categoryId uint) ([]model.AttributeDefinition, error) {
+	var attributes []model.AttributeDefinition
+	err := preloadAllowedValues(r.Db).
+		Where("category_id=?", categoryId).
+		Order("position ASC, attribute_id ASC").
+		Find(&attributes).Error
+	if err != nil {
+		return nil, err
+	}
+	return attributes, nil
+}
+
+// Getting the filterable attributes of the category, or of all categories when none is given
+func (r *AttributeRepositoryImpl) GetFilterableAttributes(categoryId *uint) ([]model.AttributeDefinition, error) {
+	var attributes []model.AttributeDefinition
+	query := preloadAllowedValues(r.Db).Where("is_filterable = ?", true)
+	if categoryId != nil {
+		query = query.Where("category_id=?", *categoryId)
+	}
+	err := query.Order("position ASC, attribute_id ASC").Find(&attributes).Error
+	if err != nil {
+		return nil, err
+	}
+	return attributes, nil
+}
+
+// Replacing all the attribute values of the product
+func (r *AttributeRepositoryImpl) ReplaceProductAttributes(productId uint, values []model.ProductAttributeValue) error {
+	return r.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id=?", productId).Delete(&model.ProductAttributeValue{}).Error; err != nil {
+			return err
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		return tx.Omit("Attribute").Create(&values).Error
+	})
+}
+
+func (r *AttributeRepositoryImpl) GetProductAttributes(productId uint) ([]model.ProductAttributeValue, error) {
+	var values []model.ProductAttributeValue
+	err := r.Db.Preload("Attribute").
+		Where("product_id=?", productId).
+		Order("attribute_id ASC").
+		Find(&values).Error
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func preloadAllowedValues(db *gorm.DB) *gorm.DB {
+	return db.Preload("AllowedValues", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC, id ASC")
+	})
+}
+
+// preloadAttributes loads the attribute values of products with their definitions
+func preloadAttributes(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Attributes", func(db *gorm.DB) *gorm.DB {
+			return db.Order("attribute_id ASC")
+		}).
+		Preload("Attributes.Attribute")
+}
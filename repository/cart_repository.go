@@ -3,6 +3,8 @@ package repository
 import (
 	"shophub-backend/logger"
 	"shophub-backend/model"
+	"shophub-backend/money"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -14,8 +16,16 @@ type CartRepository interface {
 	RemoveItemFromCart(itemId uint) error
 	ClearCart(keycloakUserID string) error
 	GetCartItemById(itemId uint) (*model.CartItem, error)
-	GetCartItemByProductId(cartID uint, productID uint) (*model.CartItem, error)
+	GetCartItemByProductId(cartID uint, productID uint, variantID *uint) (*model.CartItem, error)
 	UpdateCartItemQuantity(itemId uint, quantity int) error
+	UpdateCartItemSelection(itemId uint, isSelected bool) error
+	UpdateCartSelection(cartID uint, isSelected bool) error
+	RemoveCartItems(itemIds []uint) error
+	UpdateCartItemPrice(itemId uint, unitPrice money.Money) error
+	GetIdleCarts(idleSince time.Time) ([]model.Cart, error)
+	CountNonEmptyCarts() (int64, error)
+	MarkReminderSent(cartID uint, sentAt time.Time) error
+	UpdateCartCoupon(cartID uint, couponCode string) error
 }
 
 type CartRepositoryImpl struct {
@@ -28,7 +38,10 @@ func NewCartRepository(Db *gorm.DB) CartRepository {
 
 func (r *CartRepositoryImpl) AddItemToCart(item *model.CartItem) error {
 	logger.ActInfo("Adding new items to cart")
-	return r.Db.Create(item).Error
+	if err := r.Db.Create(item).Error; err != nil {
+		return err
+	}
+	return r.touchCart(item.CartID)
 }
 
 func (r *CartRepositoryImpl) GetUserCart(keycloakUserID string) (*model.Cart, error) {
@@ -37,8 +50,13 @@ func (r *CartRepositoryImpl) GetUserCart(keycloakUserID string) (*model.Cart, er
 		Preload("Items", func(db *gorm.DB) *gorm.DB {
 			return db.Order("id ASC")
 		}).
+		// Deleted products stay in the cart so the item can be flagged as unavailable
 		Preload("Items.Product", func(db *gorm.DB) *gorm.DB {
-			return db.Order("product_id ASC")
+			return db.Unscoped().Order("product_id ASC")
+		}).
+		Preload("Items.Variant.OptionValues").
+		Preload("Items.Variant.Images", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC, image_id ASC")
 		}).
 		Where("keycloak_user_id=?", keycloakUserID)
 
@@ -52,7 +70,7 @@ func (r *CartRepositoryImpl) GetUserCart(keycloakUserID string) (*model.Cart, er
 		if cart.Items[i].ProductID != 0 {
 			// Reload product to ensure correct matching
 			var product model.Product
-			if err := r.Db.First(&product, cart.Items[i].ProductID).Error; err == nil {
+			if err := r.Db.Unscoped().First(&product, cart.Items[i].ProductID).Error; err == nil {
 				cart.Items[i].Product = product
 			}
 		}
@@ -69,8 +87,13 @@ func (r *CartRepositoryImpl) GetOrCreateCart(keycloakUserID string) (*model.Cart
 		Preload("Items", func(db *gorm.DB) *gorm.DB {
 			return db.Order("id ASC")
 		}).
+		// Deleted products stay in the cart so the item can be flagged as unavailable
 		Preload("Items.Product", func(db *gorm.DB) *gorm.DB {
-			return db.Order("product_id ASC")
+			return db.Unscoped().Order("product_id ASC")
+		}).
+		Preload("Items.Variant.OptionValues").
+		Preload("Items.Variant.Images", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC, image_id ASC")
 		}).
 		Where("keycloak_user_id=?", keycloakUserID)
 
@@ -99,7 +122,7 @@ func (r *CartRepositoryImpl) GetOrCreateCart(keycloakUserID string) (*model.Cart
 		if cart.Items[i].ProductID != 0 {
 			// Reload product to ensure correct matching
 			var product model.Product
-			if err := r.Db.First(&product, cart.Items[i].ProductID).Error; err == nil {
+			if err := r.Db.Unscoped().First(&product, cart.Items[i].ProductID).Error; err == nil {
 				cart.Items[i].Product = product
 			}
 		}
@@ -110,7 +133,14 @@ func (r *CartRepositoryImpl) GetOrCreateCart(keycloakUserID string) (*model.Cart
 }
 
 func (r *CartRepositoryImpl) RemoveItemFromCart(itemId uint) error {
-	return r.Db.Delete(&model.CartItem{}, itemId).Error
+	var item model.CartItem
+	if err := r.Db.First(&item, itemId).Error; err != nil {
+		return err
+	}
+	if err := r.Db.Delete(&model.CartItem{}, itemId).Error; err != nil {
+		return err
+	}
+	return r.touchCart(item.CartID)
 }
 
 func (r *CartRepositoryImpl) ClearCart(keycloakUserID string) error {
@@ -119,20 +149,30 @@ func (r *CartRepositoryImpl) ClearCart(keycloakUserID string) error {
 		return err
 	}
 
-	return r.Db.Where("cart_id=?", cart.CartID).Delete(&model.CartItem{}).Error
+	if err := r.Db.Where("cart_id=?", cart.CartID).Delete(&model.CartItem{}).Error; err != nil {
+		return err
+	}
+	return r.touchCart(cart.CartID)
 }
 
 func (r *CartRepositoryImpl) GetCartItemById(itemId uint) (*model.CartItem, error) {
 	var item model.CartItem
-	if err := r.Db.First(&item, itemId).Error; err != nil {
+	if err := r.Db.Preload("Variant").First(&item, itemId).Error; err != nil {
 		return nil, err
 	}
 	return &item, nil
 }
 
-func (r *CartRepositoryImpl) GetCartItemByProductId(cartID uint, productID uint) (*model.CartItem, error) {
+// Getting the cart item of the product, or of the product's variant when one is given
+func (r *CartRepositoryImpl) GetCartItemByProductId(cartID uint, productID uint, variantID *uint) (*model.CartItem, error) {
 	var item model.CartItem
-	if err := r.Db.Where("cart_id = ? AND product_id = ?", cartID, productID).First(&item).Error; err != nil {
+	query := r.Db.Where("cart_id = ? AND product_id = ?", cartID, productID)
+	if variantID != nil {
+		query = query.Where("variant_id = ?", *variantID)
+	} else {
+		query = query.Where("variant_id IS NULL")
+	}
+	if err := query.First(&item).Error; err != nil {
 		return nil, err
 	}
 	return &item, nil
@@ -144,6 +184,105 @@ func (r *CartRepositoryImpl) UpdateCartItemQuantity(itemId uint, quantity int) e
 		return err
 	}
 	item.Quantity = quantity
-	item.TotalPrice = item.UnitPrice * float64(quantity)
-	return r.Db.Save(&item).Error
+	item.TotalPrice = item.UnitPrice.Mul(quantity)
+	if err := r.Db.Save(&item).Error; err != nil {
+		return err
+	}
+	return r.touchCart(item.CartID)
+}
+
+func (r *CartRepositoryImpl) UpdateCartItemSelection(itemId uint, isSelected bool) error {
+	var item model.CartItem
+	if err := r.Db.First(&item, itemId).Error; err != nil {
+		return err
+	}
+	if err := r.Db.Model(&item).Update("is_selected", isSelected).Error; err != nil {
+		return err
+	}
+	return r.touchCart(item.CartID)
+}
+
+// Selecting or deselecting every item in the cart at once
+func (r *CartRepositoryImpl) UpdateCartSelection(cartID uint, isSelected bool) error {
+	if err := r.Db.Model(&model.CartItem{}).
+		Where("cart_id=?", cartID).
+		Update("is_selected", isSelected).Error; err != nil {
+		return err
+	}
+	return r.touchCart(cartID)
+}
+
+// Removing only the given items, used after checking out the selected items
+func (r *CartRepositoryImpl) RemoveCartItems(itemIds []uint) error {
+	if len(itemIds) == 0 {
+		return nil
+	}
+	var item model.CartItem
+	if err := r.Db.First(&item, itemIds[0]).Error; err != nil {
+		return err
+	}
+	if err := r.Db.Where("id IN ?", itemIds).Delete(&model.CartItem{}).Error; err != nil {
+		return err
+	}
+	return r.touchCart(item.CartID)
+}
+
+// Updating the captured unit price once the user has acknowledged a price change
+func (r *CartRepositoryImpl) UpdateCartItemPrice(itemId uint, unitPrice money.Money) error {
+	var item model.CartItem
+	if err := r.Db.First(&item, itemId).Error; err != nil {
+		return err
+	}
+	item.UnitPrice = unitPrice
+	item.TotalPrice = unitPrice.Mul(item.Quantity)
+	if err := r.Db.Save(&item).Error; err != nil {
+		return err
+	}
+	return r.touchCart(item.CartID)
+}
+
+// Getting non-empty carts that have not changed since idleSince
+func (r *CartRepositoryImpl) GetIdleCarts(idleSince time.Time) ([]model.Cart, error) {
+	var carts []model.Cart
+	err := r.Db.Model(&model.Cart{}).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Items.Product", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Preload("Items.Variant").
+		Where("updated_at < ?", idleSince).
+		Where("EXISTS (SELECT 1 FROM cart_items WHERE cart_items.cart_id = carts.cart_id)").
+		Order("updated_at ASC").
+		Find(&carts).Error
+	return carts, err
+}
+
+func (r *CartRepositoryImpl) CountNonEmptyCarts() (int64, error) {
+	var count int64
+	err := r.Db.Model(&model.Cart{}).
+		Where("EXISTS (SELECT 1 FROM cart_items WHERE cart_items.cart_id = carts.cart_id)").
+		Count(&count).Error
+	return count, err
+}
+
+// UpdateColumn is used so that sending a reminder does not count as cart activity
+func (r *CartRepositoryImpl) MarkReminderSent(cartID uint, sentAt time.Time) error {
+	return r.Db.Model(&model.Cart{}).
+		Where("cart_id=?", cartID).
+		UpdateColumn("reminder_sent_at", sentAt).Error
+}
+
+func (r *CartRepositoryImpl) UpdateCartCoupon(cartID uint, couponCode string) error {
+	return r.Db.Model(&model.Cart{}).
+		Where("cart_id=?", cartID).
+		Update("coupon_code", couponCode).Error
+}
+
+// touchCart records activity on the cart so idle carts can be detected
+func (r *CartRepositoryImpl) touchCart(cartID uint) error {
+	return r.Db.Model(&model.Cart{}).
+		Where("cart_id=?", cartID).
+		Update("updated_at", time.Now()).Error
 }
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"shophub-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type CategoryRepository interface {
+	GetAllCategories() ([]model.Category, error)
+}
+
+type CategoryRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+func NewCategoryRepository(Db *gorm.DB) CategoryRepository {
+	return &CategoryRepositoryImpl{Db: Db}
+}
+
+func (r *CategoryRepositoryImpl) GetAllCategories() ([]model.Category, error) {
+	var categories []model.Category
+	err := r.Db.Order("category_id ASC").Find(&categories).Error
+	return categories, err
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"errors"
+	"shophub-backend/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCouponUsageLimit = errors.New("coupon usage limit reached")
+	ErrCouponUserLimit  = errors.New("coupon usage limit reached for this user")
+)
+
+type CouponRepository interface {
+	CreateCoupon(coupon *model.Coupon) error
+	GetAllCoupons() ([]model.Coupon, error)
+	GetCouponByCode(code string) (*model.Coupon, error)
+	CountRedemptionsByUser(couponID uint, keycloakUserID string) (int64, error)
+	RedeemCoupon(redemption *model.CouponRedemption) error
+	AttachRedemptionOrder(redemptionId uint, orderId uint) error
+	CancelRedemption(redemptionId uint) error
+}
+
+type CouponRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+func NewCouponRepository(Db *gorm.DB) CouponRepository {
+	return &CouponRepositoryImpl{Db: Db}
+}
+
+// Creating the coupon and its scope, without upserting the referenced products and categories
+func (r *CouponRepositoryImpl) CreateCoupon(coupon *model.Coupon) error {
+	return r.Db.Omit("Products.*", "Categories.*").Create(coupon).Error
+}
+
+func (r *CouponRepositoryImpl) GetAllCoupons() ([]model.Coupon, error) {
+	var coupons []model.Coupon
+	err := r.Db.Preload("Products").Preload("Categories").Order("coupon_id ASC").Find(&coupons).Error
+	return coupons, err
+}
+
+func (r *CouponRepositoryImpl) GetCouponByCode(code string) (*model.Coupon, error) {
+	var coupon model.Coupon
+	if err := r.Db.Preload("Products").Preload("Categories").
+		Where("code=?", code).First(&coupon).Error; err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+func (r *CouponRepositoryImpl) CountRedemptionsByUser(couponID uint, keycloakUserID string) (int64, error) {
+	var count int64
+	err := r.Db.Model(&model.CouponRedemption{}).
+		Where("coupon_id=? AND keycloak_user_id=?", couponID, keycloakUserID).
+		Count(&count).Error
+	return count, err
+}
+
+// Counting the use against the global and per user limits and recording the redemption in one transaction.
+// The coupon row is locked, so concurrent checkouts cannot both take the last use.
+func (r *CouponRepositoryImpl) RedeemCoupon(redemption *model.CouponRedemption) error {
+	return r.Db.Transaction(func(tx *gorm.DB) error {
+		var coupon model.Coupon
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&coupon, redemption.CouponID).Error; err != nil {
+			return err
+		}
+		if coupon.UsageLimit > 0 && coupon.UsedCount >= coupon.UsageLimit {
+			return ErrCouponUsageLimit
+		}
+		if coupon.PerUserLimit > 0 {
+			var used int64
+			if err := tx.Model(&model.CouponRedemption{}).
+				Where("coupon_id=? AND keycloak_user_id=?", redemption.CouponID, redemption.KeycloakUserID).
+				Count(&used).Error; err != nil {
+				return err
+			}
+			if used >= int64(coupon.PerUserLimit) {
+				return ErrCouponUserLimit
+			}
+		}
+
+		if err := tx.Model(&coupon).UpdateColumn("used_count", gorm.Expr("used_count + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Create(redemption).Error
+	})
+}
+
+func (r *CouponRepositoryImpl) AttachRedemptionOrder(redemptionId uint, orderId uint) error {
+	return r.Db.Model(&model.CouponRedemption{}).
+		Where("redemption_id=?", redemptionId).
+		Update("order_id", orderId).Error
+}
+
+// Removing the redemption of a checkout that failed and giving its use back to the coupon
+func (r *CouponRepositoryImpl) CancelRedemption(redemptionId uint) error {
+	return r.Db.Transaction(func(tx *gorm.DB) error {
+		var redemption model.CouponRedemption
+		if err := tx.First(&redemption, redemptionId).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&redemption).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Coupon{}).
+			Where("coupon_id=? AND used_count > 0", redemption.CouponID).
+			UpdateColumn("used_count", gorm.Expr("used_count - 1")).Error
+	})
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"shophub-backend/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExchangeRateRepository interface {
+	GetAllRates() ([]model.ExchangeRate, error)
+	SaveRates(rates []model.ExchangeRate) error
+}
+
+type ExchangeRateRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+func NewExchangeRateRepository(Db *gorm.DB) ExchangeRateRepository {
+	return &ExchangeRateRepositoryImpl{Db: Db}
+}
+
+func (r *ExchangeRateRepositoryImpl) GetAllRates() ([]model.ExchangeRate, error) {
+	var rates []model.ExchangeRate
+	err := r.Db.Order("currency ASC").Find(&rates).Error
+	return rates, err
+}
+
+// Inserting new currencies and updating the rate of existing ones
+func (r *ExchangeRateRepositoryImpl) SaveRates(rates []model.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	return r.Db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(&rates).Error
+}
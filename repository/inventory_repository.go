@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"shophub-backend/inventory"
+	"shophub-backend/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInsufficientStock  = errors.New("insufficient stock")
+	ErrReservationExpired = errors.New("reservation is no longer active")
+)
+
+type InventoryRepository interface {
+	Reserve(reservation model.InventoryReservation, country string) ([]model.InventoryReservation, error)
+	AttachOrder(reservationId uint, orderId uint) error
+	GetReservationsByOrder(orderId uint) ([]model.InventoryReservation, error)
+	GetExpiredReservations(now time.Time) ([]model.InventoryReservation, error)
+	GetAvailableQuantities(productIds []uint) (map[uint]int, error)
+	GetAvailableVariantQuantities(variantIds []uint) (map[uint]int, error)
+	Commit(reservationId uint) error
+	Release(reservationId uint, reason string) error
+}
+
+type InventoryRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+func NewInventoryRepository(Db *gorm.DB) InventoryRepository {
+	return &InventoryRepositoryImpl{Db: Db}
+}
+
+// Reserving the quantity from the warehouses that have it unreserved, split over several warehouses
+// when needed, with one reservation per warehouse. The product row is locked so concurrent checkouts
+// cannot reserve the same units.
+func (r *InventoryRepositoryImpl) Reserve(reservation model.InventoryReservation, country string) ([]model.InventoryReservation, error) {
+	var reservations []model.InventoryReservation
+	err := r.Db.Transaction(func(tx *gorm.DB) error {
+		var product model.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, reservation.ProductID).Error; err != nil {
+			return err
+		}
+
+		stock, err := warehouseStock(tx, reservation.ProductID, reservation.VariantID)
+		if err != nil {
+			return err
+		}
+		allocations, ok := inventory.Allocate(stock, reservation.Quantity, country)
+		if !ok {
+			return ErrInsufficientStock
+		}
+
+		for _, allocation := range allocations {
+			warehouseId := allocation.WarehouseID
+			allocated := reservation
+			allocated.WarehouseID = &warehouseId
+			allocated.Quantity = allocation.Quantity
+			allocated.Status = model.ReservationStatusActive
+			if err := tx.Create(&allocated).Error; err != nil {
+				return err
+			}
+			reservations = append(reservations, allocated)
+		}
+		return nil
+	})
+	return reservations, err
+}
+
+// warehouseStock is the unreserved stock of the product or its variant in every active warehouse
+func warehouseStock(tx *gorm.DB, productId uint, variantId uint) ([]inventory.Stock, error) {
+	var stock []inventory.Stock
+	err := tx.Table("stock_levels").
+		Select("stock_levels.warehouse_id, warehouses.country, stock_levels.quantity - COALESCE(("+
+			"SELECT SUM(r.quantity) FROM inventory_reservations r "+
+			"WHERE r.warehouse_id = stock_levels.warehouse_id AND r.product_id = stock_levels.product_id "+
+			"AND r.variant_id = stock_levels.variant_id AND r.status = ?"+
+			"), 0) AS available", model.ReservationStatusActive).
+		Joins("JOIN warehouses ON warehouses.warehouse_id = stock_levels.warehouse_id").
+		Where("stock_levels.product_id=? AND stock_levels.variant_id=? AND warehouses.is_active=?", productId, variantId, true).
+		Order("stock_levels.warehouse_id ASC").
+		Scan(&stock).Error
+	return stock, err
+}
+
+func (r *InventoryRepositoryImpl) AttachOrder(reservationId uint, orderId uint) error {
+	return r.Db.Model(&model.InventoryReservation{}).
+		Where("reservation_id=?", reservationId).
+		Update("order_id", orderId).Error
+}
+
+func (r *InventoryRepositoryImpl) GetReservationsByOrder(orderId uint) ([]model.InventoryReservation, error) {
+	var reservations []model.InventoryReservation
+	err := r.Db.Where("order_id=?", orderId).Order("reservation_id ASC").Find(&reservations).Error
+	return reservations, err
+}
+
+func (r *InventoryRepositoryImpl) GetExpiredReservations(now time.Time) ([]model.InventoryReservation, error) {
+	var reservations []model.InventoryReservation
+	err := r.Db.
+		Where("status=? AND expires_at < ?", model.ReservationStatusActive, now).
+		Order("reservation_id ASC").
+		Find(&reservations).Error
+	return reservations, err
+}
+
+// Summing the stock of the active warehouses less the active reservations per product,
+// products without stock are left out
+func (r *InventoryRepositoryImpl) GetAvailableQuantities(productIds []uint) (map[uint]int, error) {
+	available := make(map[uint]int, len(productIds))
+	if len(productIds) == 0 {
+		return available, nil
+	}
+
+	var stocked []struct {
+		ProductID uint
+		Quantity  int
+	}
+	err := r.Db.Table("stock_levels").
+		Select("stock_levels.product_id, SUM(stock_levels.quantity) AS quantity").
+		Joins("JOIN warehouses ON warehouses.warehouse_id = stock_levels.warehouse_id").
+		Where("stock_levels.product_id IN ? AND warehouses.is_active=?", productIds, true).
+		Group("stock_levels.product_id").
+		Scan(&stocked).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range stocked {
+		available[row.ProductID] = row.Quantity
+	}
+
+	var reserved []struct {
+		ProductID uint
+		Quantity  int
+	}
+	err = r.Db.Model(&model.InventoryReservation{}).
+		Select("inventory_reservations.product_id, SUM(inventory_reservations.quantity) AS quantity").
+		Joins("JOIN warehouses ON warehouses.warehouse_id = inventory_reservations.warehouse_id").
+		Where("inventory_reservations.product_id IN ? AND inventory_reservations.status=? AND warehouses.is_active=?", productIds, model.ReservationStatusActive, true).
+		Group("inventory_reservations.product_id").
+		Scan(&reserved).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range reserved {
+		available[row.ProductID] -= row.Quantity
+	}
+	return available, nil
+}
+
+// Summing the stock of the active warehouses less the active reservations per variant,
+// variants without stock are left out
+func (r *InventoryRepositoryImpl) GetAvailableVariantQuantities(variantIds []uint) (map[uint]int, error) {
+	available := make(map[uint]int, len(variantIds))
+	if len(variantIds) == 0 {
+		return available, nil
+	}
+
+	var stocked []struct {
+		VariantID uint
+		Quantity  int
+	}
+	err := r.Db.Table("stock_levels").
+		Select("stock_levels.variant_id, SUM(stock_levels.quantity) AS quantity").
+		Joins("JOIN warehouses ON warehouses.warehouse_id = stock_levels.warehouse_id").
+		Where("stock_levels.variant_id IN ? AND warehouses.is_active=?", variantIds, true).
+		Group("stock_levels.variant_id").
+		Scan(&stocked).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range stocked {
+		available[row.VariantID] = row.Quantity
+	}
+
+	var reserved []struct {
+		VariantID uint
+		Quantity  int
+	}
+	err = r.Db.Model(&model.InventoryReservation{}).
+		Select("inventory_reservations.variant_id, SUM(inventory_reservations.quantity) AS quantity").
+		Joins("JOIN warehouses ON warehouses.warehouse_id = inventory_reservations.warehouse_id").
+		Where("inventory_reservations.variant_id IN ? AND inventory_reservations.status=? AND warehouses.is_active=?", variantIds, model.ReservationStatusActive, true).
+		Group("inventory_reservations.variant_id").
+		Scan(&reserved).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range reserved {
+		available[row.VariantID] -= row.Quantity
+	}
+	return available, nil
+}
+
+// Taking the reserved quantity off the warehouse and product stock and recording the sale in the ledger,
+// only an active reservation can be committed
+func (r *InventoryRepositoryImpl) Commit(reservationId uint) error {
+	return r.Db.Transaction(func(tx *gorm.DB) error {
+		var reservation model.InventoryReservation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, reservationId).Error; err != nil {
+			return err
+		}
+		if reservation.Status != model.ReservationStatusActive {
+			return ErrReservationExpired
+		}
+
+		if reservation.WarehouseID != nil {
+			if err := tx.Model(&model.StockLevel{}).
+				Where("warehouse_id=? AND product_id=? AND variant_id=?", *reservation.WarehouseID, reservation.ProductID, reservation.VariantID).
+				UpdateColumn("quantity", gorm.Expr("quantity - ?", reservation.Quantity)).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&model.Product{}).
+			Where("product_id=?", reservation.ProductID).
+			UpdateColumn("product_stock", gorm.Expr("product_stock - ?", reservation.Quantity)).Error; err != nil {
+			return err
+		}
+		if err := updateWishlistStockFlags(tx, reservation.ProductID); err != nil {
+			return err
+		}
+		if err := recordMovement(tx, model.StockMovement{
+			ProductID:   reservation.ProductID,
+			VariantID:   reservation.VariantID,
+			WarehouseID: reservation.WarehouseID,
+			Quantity:    -reservation.Quantity,
+			Reason:      model.StockMovementSale,
+			Actor:       reservation.KeycloakUserID,
+			Reference:   reservationReference(reservation),
+		}); err != nil {
+			return err
+		}
+		now := time.Now()
+		return tx.Model(&reservation).Updates(map[string]interface{}{
+			"status":       model.ReservationStatusCommitted,
+			"committed_at": now,
+		}).Error
+	})
+}
+
+// Releasing a reservation, the quantity of a committed reservation is put back on the warehouse and product
+// stock and recorded in the ledger as a cancellation. Released reservations are left alone.
+func (r *InventoryRepositoryImpl) Release(reservationId uint, reason string) error {
+	return r.Db.Transaction(func(tx *gorm.DB) error {
+		var reservation model.InventoryReservation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, reservationId).Error; err != nil {
+			return err
+		}
+		switch reservation.Status {
+		case model.ReservationStatusActive:
+		case model.ReservationStatusCommitted:
+			if err := restock(tx, reservation); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+
+		return tx.Model(&reservation).Updates(map[string]interface{}{
+			"status":         model.ReservationStatusReleased,
+			"release_reason": reason,
+			"released_at":    time.Now(),
+		}).Error
+	})
+}
+
+// restock gives the stock taken by a committed reservation back and records it as a cancellation
+func restock(tx *gorm.DB, reservation model.InventoryReservation) error {
+	if reservation.WarehouseID != nil {
+		if err := tx.Model(&model.StockLevel{}).
+			Where("warehouse_id=? AND product_id=? AND variant_id=?", *reservation.WarehouseID, reservation.ProductID, reservation.VariantID).
+			UpdateColumn("quantity", gorm.Expr("quantity + ?", reservation.Quantity)).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(&model.Product{}).
+		Where("product_id=?", reservation.ProductID).
+		UpdateColumn("product_stock", gorm.Expr("product_stock + ?", reservation.Quantity)).Error; err != nil {
+		return err
+	}
+	if err := updateWishlistStockFlags(tx, reservation.ProductID); err != nil {
+		return err
+	}
+	return recordMovement(tx, model.StockMovement{
+		ProductID:   reservation.ProductID,
+		VariantID:   reservation.VariantID,
+		WarehouseID: reservation.WarehouseID,
+		Quantity:    reservation.Quantity,
+		Reason:      model.StockMovementCancellation,
+		Actor:       reservation.KeycloakUserID,
+		Reference:   reservationReference(reservation),
+	})
+}
+
+// reservationReference points a ledger entry at the order of the reservation, or the reservation itself
+// before it belongs to an order
+func reservationReference(reservation model.InventoryReservation) string {
+	if reservation.OrderID != nil {
+		return fmt.Sprintf("order:%d", *reservation.OrderID)
+	}
+	return fmt.Sprintf("reservation:%d", reservation.ReservationID)
+}
@@ -11,6 +11,7 @@ type OrderRepository interface {
 	GetOrderById(orderId uint) (*model.Order, error)
 	GetOrderByKeycloakUserID(keycloakUserID string) ([]model.Order, error)
 	UpdateOrderStatus(orderId uint, OrderStatus string) error
+	HasOrderWithStatus(keycloakUserID string, productId uint, status string) (bool, error)
 }
 
 type OrderRepositoryImpl struct {
@@ -28,17 +29,17 @@ func (r OrderRepositoryImpl) CreateOrder(order *model.Order) error {
 func (r OrderRepositoryImpl) GetOrderByKeycloakUserID(keycloakUserID string) ([]model.Order, error) {
 	var orders []model.Order
 	err := r.Db.
-		Preload("Product").
 		Preload("Payment").
+		Preload("TaxLines").
 		Where("keycloak_user_id=?", keycloakUserID).
 		Find(&orders).Error
 	return orders, err
 }
 
-// Getting order with the payment and products
+// Getting order with the payment, the product and addresses are read from the order's own copy
 func (r OrderRepositoryImpl) GetOrderById(orderId uint) (*model.Order, error) {
 	var order model.Order
-	err := r.Db.Preload("Product").Preload("Payment").First(&order, orderId).Error
+	err := r.Db.Preload("Payment").Preload("TaxLines").First(&order, orderId).Error
 	return &order, err
 }
 
@@ -47,3 +48,11 @@ func (r OrderRepositoryImpl) UpdateOrderStatus(orderId uint, OrderStatus string)
 		Where("order_id=?", orderId).
 		Update("order_status", OrderStatus).Error
 }
+
+func (r OrderRepositoryImpl) HasOrderWithStatus(keycloakUserID string, productId uint, status string) (bool, error) {
+	var count int64
+	err := r.Db.Model(&model.Order{}).
+		Where("keycloak_user_id=? AND product_id=? AND order_status=?", keycloakUserID, productId, status).
+		Count(&count).Error
+	return count > 0, err
+}
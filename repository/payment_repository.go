@@ -10,6 +10,7 @@ type PaymentRepository interface {
 	CreatePayment(payment *model.Payment) error
 	GetPaymentByOrder(orderId uint) (*model.Payment, error)
 	UpdatePaymentStatus(orderId uint, status string) error
+	UpdatePaymentStatusById(paymentId uint, status string) error
 	UpdatePaymentOrderId(paymentId uint, orderId uint) error
 }
 
@@ -35,7 +36,13 @@ func (r *PaymentRepositoryImpl) GetPaymentByOrder(orderId uint) (*model.Payment,
 func (r *PaymentRepositoryImpl) UpdatePaymentStatus(orderId uint, status string) error {
 	return r.Db.Model(&model.Payment{}).
 		Where("order_id=?", orderId).
-		Update("payment_status", status).Error
+		Update("status", status).Error
+}
+
+func (r *PaymentRepositoryImpl) UpdatePaymentStatusById(paymentId uint, status string) error {
+	return r.Db.Model(&model.Payment{}).
+		Where("payment_id=?", paymentId).
+		Update("status", status).Error
 }
 
 func (r *PaymentRepositoryImpl) UpdatePaymentOrderId(paymentId uint, orderId uint) error {
@@ -2,17 +2,25 @@ package repository
 
 import (
 	"shophub-backend/model"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductRepository interface {
 	CreateProduct(product *model.Product) error
 	GetAllProducts() ([]model.Product, error)
 	GetProductById(productId uint) (*model.Product, error)
+	GetProductsByIds(productIds []uint) ([]model.Product, error)
+	EachProductBatch(batchSize int, fn func(products []model.Product) error) error
 	UpdateProduct(product *model.Product) error
 	DeleteProduct(productID uint) error
 	GetProductBySlug(productSlug string) (*model.Product, error)
+	UpdateReorderThreshold(productId uint, threshold *int) error
+	UpdateStockAlert(productId uint, alert string) error
+	UpdateProductStatus(productId uint, status string, publishAt *time.Time, unpublishAt *time.Time) error
+	ApplyPublishingSchedule(now time.Time) (published int64, unpublished int64, err error)
 }
 
 type ProductRepositoryImpl struct {
@@ -29,7 +37,7 @@ func (r ProductRepositoryImpl) CreateProduct(product *model.Product) error {
 
 func (r ProductRepositoryImpl) GetAllProducts() ([]model.Product, error) {
 	var products []model.Product
-	err := r.Db.Find(&products).Error
+	err := preloadAttributes(preloadVariants(r.Db)).Find(&products).Error
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +46,7 @@ func (r ProductRepositoryImpl) GetAllProducts() ([]model.Product, error) {
 
 func (r ProductRepositoryImpl) GetProductById(productId uint) (*model.Product, error) {
 	var product model.Product
-	if err := r.Db.Preload("ProductImages", func(db *gorm.DB) *gorm.DB {
+	if err := preloadAttributes(preloadVariants(r.Db)).Preload("ProductImages", func(db *gorm.DB) *gorm.DB {
 		return db.Order("image_id ASC")
 	}).First(&product, productId).Error; err != nil {
 		return nil, err
@@ -46,17 +54,47 @@ func (r ProductRepositoryImpl) GetProductById(productId uint) (*model.Product, e
 	return &product, nil
 }
 
+func (r ProductRepositoryImpl) GetProductsByIds(productIds []uint) ([]model.Product, error) {
+	var products []model.Product
+	if len(productIds) == 0 {
+		return products, nil
+	}
+	err := preloadAttributes(preloadVariants(r.Db)).Where("product_id IN ?", productIds).Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// Reading all the products in batches ordered by id, so the whole catalogue is never held in memory
+func (r ProductRepositoryImpl) EachProductBatch(batchSize int, fn func(products []model.Product) error) error {
+	var products []model.Product
+	return r.Db.FindInBatches(&products, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(products)
+	}).Error
+}
+
+// Stock is left out, it only changes through stock movements so every change is in the ledger.
+// Loaded associations are left out too, they have their own repositories.
 func (r ProductRepositoryImpl) UpdateProduct(product *model.Product) error {
-	return r.Db.Save(product).Error
+	return r.Db.Omit(clause.Associations, "product_stock").Save(product).Error
 }
 
+// Archiving and soft deleting the product, orders and carts keep referring to it
 func (r ProductRepositoryImpl) DeleteProduct(productID uint) error {
-	return r.Db.Delete(&model.Product{}, productID).Error
+	return r.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Product{}).
+			Where("product_id=?", productID).
+			UpdateColumn("status", model.ProductStatusArchived).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Product{}, productID).Error
+	})
 }
 
 func (r ProductRepositoryImpl) GetProductBySlug(productSlug string) (*model.Product, error) {
 	var product model.Product
-	if err := r.Db.Preload("ProductImages", func(db *gorm.DB) *gorm.DB {
+	if err := preloadAttributes(preloadVariants(r.Db)).Preload("ProductImages", func(db *gorm.DB) *gorm.DB {
 		return db.Order("image_id ASC")
 	}).
 		Where("product_slug= ?", productSlug).First(&product).Error; err != nil {
@@ -64,3 +102,69 @@ func (r ProductRepositoryImpl) GetProductBySlug(productSlug string) (*model.Prod
 	}
 	return &product, nil
 }
+
+func (r ProductRepositoryImpl) UpdateReorderThreshold(productId uint, threshold *int) error {
+	return r.Db.Model(&model.Product{}).
+		Where("product_id=?", productId).
+		UpdateColumn("reorder_threshold", threshold).Error
+}
+
+func (r ProductRepositoryImpl) UpdateStockAlert(productId uint, alert string) error {
+	return r.Db.Model(&model.Product{}).
+		Where("product_id=?", productId).
+		UpdateColumn("stock_alert", alert).Error
+}
+
+// preloadVariants loads the options of the products and their variants, in display order
+func preloadVariants(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC, option_id ASC")
+		}).
+		Preload("Options.Values", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC, value_id ASC")
+		}).
+		Preload("Variants", func(db *gorm.DB) *gorm.DB {
+			return db.Order("variant_id ASC")
+		}).
+		Preload("Variants.OptionValues", func(db *gorm.DB) *gorm.DB {
+			return db.Order("product_option_values.option_id ASC")
+		}).
+		Preload("Variants.Images", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC, image_id ASC")
+		})
+}
+
+func (r ProductRepositoryImpl) UpdateProductStatus(productId uint, status string, publishAt *time.Time, unpublishAt *time.Time) error {
+	return r.Db.Model(&model.Product{}).
+		Where("product_id=?", productId).
+		UpdateColumns(map[string]interface{}{
+			"status":       status,
+			"publish_at":   publishAt,
+			"unpublish_at": unpublishAt,
+		}).Error
+}
+
+// Activating the drafts whose publish time has come and archiving the active products whose unpublish time has
+func (r ProductRepositoryImpl) ApplyPublishingSchedule(now time.Time) (published int64, unpublished int64, err error) {
+	err = r.Db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Product{}).
+			Where("status=? AND publish_at <= ?", model.ProductStatusDraft, now).
+			Where("unpublish_at IS NULL OR unpublish_at > ?", now).
+			UpdateColumn("status", model.ProductStatusActive)
+		if result.Error != nil {
+			return result.Error
+		}
+		published = result.RowsAffected
+
+		result = tx.Model(&model.Product{}).
+			Where("status=? AND unpublish_at <= ?", model.ProductStatusActive, now).
+			UpdateColumn("status", model.ProductStatusArchived)
+		if result.Error != nil {
+			return result.Error
+		}
+		unpublished = result.RowsAffected
+		return nil
+	})
+	return published, unpublished, err
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"shophub-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type PromotionRepository interface {
+	CreatePromotion(promotion *model.Promotion) error
+	GetAllPromotions() ([]model.Promotion, error)
+	GetActivePromotions() ([]model.Promotion, error)
+}
+
+type PromotionRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+func NewPromotionRepository(Db *gorm.DB) PromotionRepository {
+	return &PromotionRepositoryImpl{Db: Db}
+}
+
+// Creating the promotion with its tiers, without upserting the referenced products and categories
+func (r *PromotionRepositoryImpl) CreatePromotion(promotion *model.Promotion) error {
+	return r.Db.Omit("Products.*", "Categories.*").Create(promotion).Error
+}
+
+func (r *PromotionRepositoryImpl) GetAllPromotions() ([]model.Promotion, error) {
+	var promotions []model.Promotion
+	err := r.Db.Preload("Products").Preload("Categories").Preload("Tiers").
+		Order("priority DESC, promotion_id ASC").
+		Find(&promotions).Error
+	return promotions, err
+}
+
+// Date windows are checked by the evaluator, only the active flag is filtered here
+func (r *PromotionRepositoryImpl) GetActivePromotions() ([]model.Promotion, error) {
+	var promotions []model.Promotion
+	err := r.Db.Preload("Products").Preload("Categories").Preload("Tiers").
+		Where("is_active=?", true).
+		Order("priority DESC, promotion_id ASC").
+		Find(&promotions).Error
+	return promotions, err
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"shophub-backend/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RecommendationRepository interface {
+	GetPurchases(since time.Time, excludedStatus string) ([]model.Order, error)
+	ReplaceCoPurchases(pairs []model.CoPurchase) error
+	GetCoPurchases(productId uint, limit int) ([]model.CoPurchase, error)
+	GetCategoryBestsellers(categoryId uint, since time.Time, excludedStatus string, excluded []uint, limit int) ([]CategoryBestseller, error)
+}
+
+// CategoryBestseller is a product with the units of it sold
+type CategoryBestseller struct {
+	ProductID uint
+	Quantity  int
+}
+
+type RecommendationRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+func NewRecommendationRepository(Db *gorm.DB) RecommendationRepository {
+	return &RecommendationRepositoryImpl{Db: Db}
+}
+
+// Getting who bought which product when, leaving out orders with the excluded status
+func (r *RecommendationRepositoryImpl) GetPurchases(since time.Time, excludedStatus string) ([]model.Order, error) {
+	var orders []model.Order
+	err := r.Db.
+		Select("keycloak_user_id", "product_id", "created_at").
+		Where("created_at >= ? AND order_status <> ?", since, excludedStatus).
+		Find(&orders).Error
+	return orders, err
+}
+
+// Replacing all the co-purchases, so recommendations never mix two runs of the job
+func (r *RecommendationRepositoryImpl) ReplaceCoPurchases(pairs []model.CoPurchase) error {
+	return r.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&model.CoPurchase{}).Error; err != nil {
+			return err
+		}
+		if len(pairs) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&pairs, 500).Error
+	})
+}
+
+// Getting the products bought most often with the product, only products that are on sale are counted
+// towards the limit
+func (r *RecommendationRepositoryImpl) GetCoPurchases(productId uint, limit int) ([]model.CoPurchase, error) {
+	var pairs []model.CoPurchase
+	err := r.Db.
+		Joins("JOIN products ON products.product_id = co_purchases.related_product_id").
+		Where("co_purchases.product_id=?", productId).
+		Where("products.status = ? AND products.deleted_at IS NULL", model.ProductStatusActive).
+		Order("co_purchases.count DESC, co_purchases.related_product_id ASC").
+		Limit(limit).
+		Find(&pairs).Error
+	return pairs, err
+}
+
+// Getting the products of the category with the most units ordered since the given time
+func (r *RecommendationRepositoryImpl) GetCategoryBestsellers(categoryId uint, since time.Time, excludedStatus string, excluded []uint, limit int) ([]CategoryBestseller, error) {
+	var bestsellers []CategoryBestseller
+	query := r.Db.Model(&model.Order{}).
+		Select("orders.product_id, SUM(orders.quantity) AS quantity").
+		Joins("JOIN products ON products.product_id = orders.product_id").
+		Where("products.category_id=? AND orders.created_at >= ? AND orders.order_status <> ?", categoryId, since, excludedStatus).
+		Where("products.status = ? AND products.deleted_at IS NULL", model.ProductStatusActive)
+	if len(excluded) > 0 {
+		query = query.Where("orders.product_id NOT IN ?", excluded)
+	}
+	err := query.
+		Group("orders.product_id").
+		Order("quantity DESC, orders.product_id ASC").
+		Limit(limit).
+		Scan(&bestsellers).Error
+	return bestsellers, err
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"shophub-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type ReviewRepository interface {
+	CreateReview(review *model.Review) error
+	GetReviewById(reviewId uint) (*model.Review, error)
+	GetUserReview(productId uint, keycloakUserID string) (*model.Review, error)
+	GetReviewsByProduct(productId uint, status string) ([]model.Review, error)
+	GetReviewsByStatus(status string) ([]model.Review, error)
+	UpdateReviewStatus(reviewId uint, status string) error
+}
+
+type ReviewRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+func NewReviewRepository(Db *gorm.DB) ReviewRepository {
+	return &ReviewRepositoryImpl{Db: Db}
+}
+
+func (r *ReviewRepositoryImpl) CreateReview(review *model.Review) error {
+	return r.Db.Create(review).Error
+}
+
+func (r *ReviewRepositoryImpl) GetReviewById(reviewId uint) (*model.Review, error) {
+	var review model.Review
+	if err := r.Db.First(&review, reviewId).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *ReviewRepositoryImpl) GetUserReview(productId uint, keycloakUserID string) (*model.Review, error) {
+	var review model.Review
+	err := r.Db.Where("product_id=? AND keycloak_user_id=?", productId, keycloakUserID).First(&review).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// Getting the reviews of the product with the status, newest first
+func (r *ReviewRepositoryImpl) GetReviewsByProduct(productId uint, status string) ([]model.Review, error) {
+	var reviews []model.Review
+	err := r.Db.
+		Where("product_id=? AND status=?", productId, status).
+		Order("created_at DESC, review_id DESC").
+		Find(&reviews).Error
+	return reviews, err
+}
+
+// Getting the reviews with the status, oldest first so moderation follows submission order
+func (r *ReviewRepositoryImpl) GetReviewsByStatus(status string) ([]model.Review, error) {
+	var reviews []model.Review
+	err := r.Db.
+		Where("status=?", status).
+		Order("created_at ASC, review_id ASC").
+		Find(&reviews).Error
+	return reviews, err
+}
+
+// Updating the status of the review and recalculating the rating of its product from the approved reviews
+func (r *ReviewRepositoryImpl) UpdateReviewStatus(reviewId uint, status string) error {
+	return r.Db.Transaction(func(tx *gorm.DB) error {
+		var review model.Review
+		if err := tx.First(&review, reviewId).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&review).Update("status", status).Error; err != nil {
+			return err
+		}
+
+		var rating struct {
+			Average float64
+			Count   int
+		}
+		if err := tx.Model(&model.Review{}).
+			Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count").
+			Where("product_id=? AND status=?", review.ProductID, model.ReviewStatusApproved).
+			Scan(&rating).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Product{}).
+			Where("product_id=?", review.ProductID).
+			UpdateColumns(map[string]interface{}{
+				"rating_average": rating.Average,
+				"rating_count":   rating.Count,
+			}).Error
+	})
+}
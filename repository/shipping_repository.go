@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"shophub-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type ShippingRepository interface {
+	CreateZone(zone *model.ShippingZone) error
+	GetAllZones() ([]model.ShippingZone, error)
+	GetZoneById(zoneId uint) (*model.ShippingZone, error)
+	CreateMethod(method *model.ShippingMethod) error
+}
+
+type ShippingRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+func NewShippingRepository(Db *gorm.DB) ShippingRepository {
+	return &ShippingRepositoryImpl{Db: Db}
+}
+
+// Creating the zone together with its countries
+func (r *ShippingRepositoryImpl) CreateZone(zone *model.ShippingZone) error {
+	return r.Db.Create(zone).Error
+}
+
+func (r *ShippingRepositoryImpl) GetAllZones() ([]model.ShippingZone, error) {
+	var zones []model.ShippingZone
+	err := r.Db.Preload("Countries").
+		Preload("Methods", func(db *gorm.DB) *gorm.DB {
+			return db.Order("shipping_method_id ASC")
+		}).
+		Order("shipping_zone_id ASC").
+		Find(&zones).Error
+	return zones, err
+}
+
+func (r *ShippingRepositoryImpl) GetZoneById(zoneId uint) (*model.ShippingZone, error) {
+	var zone model.ShippingZone
+	err := r.Db.First(&zone, zoneId).Error
+	return &zone, err
+}
+
+func (r *ShippingRepositoryImpl) CreateMethod(method *model.ShippingMethod) error {
+	return r.Db.Create(method).Error
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"shophub-backend/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StockBalance is the stock of a product in a warehouse as recorded and as summed from the ledger,
+// WarehouseID is nil for the total stock of the product over all warehouses
+type StockBalance struct {
+	ProductID   uint
+	VariantID   uint
+	WarehouseID *uint
+	Recorded    int
+	Ledger      int
+}
+
+type StockMovementRepository interface {
+	GetMovementsByProduct(productId uint) ([]model.StockMovement, error)
+	GetWarehouseBalances() ([]StockBalance, error)
+	GetProductBalances() ([]StockBalance, error)
+	SetWarehouseStock(warehouseId uint, productId uint, variantId uint, quantity int) error
+	SetProductStock(productId uint, quantity int) error
+	GetUnitsSold(since time.Time) (map[uint]int, error)
+}
+
+type StockMovementRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+func NewStockMovementRepository(Db *gorm.DB) StockMovementRepository {
+	return &StockMovementRepositoryImpl{Db: Db}
+}
+
+// recordMovement adds a movement to the ledger, it is called in the transaction that changes the stock
+func recordMovement(tx *gorm.DB, movement model.StockMovement) error {
+	movement.MovementID = 0
+	return tx.Create(&movement).Error
+}
+
+func (r *StockMovementRepositoryImpl) GetMovementsByProduct(productId uint) ([]model.StockMovement, error) {
+	var movements []model.StockMovement
+	err := r.Db.Where("product_id=?", productId).Order("movement_id DESC").Find(&movements).Error
+	return movements, err
+}
+
+// Comparing the stock level of every warehouse with the sum of its movements, including
+// stock levels without movements and movements without a stock level
+func (r *StockMovementRepositoryImpl) GetWarehouseBalances() ([]StockBalance, error) {
+	var balances []StockBalance
+	err := r.Db.Raw(
+		`SELECT COALESCE(s.product_id, m.product_id) AS product_id,
+			COALESCE(s.variant_id, m.variant_id) AS variant_id,
+			COALESCE(s.warehouse_id, m.warehouse_id) AS warehouse_id,
+			COALESCE(s.quantity, 0) AS recorded,
+			COALESCE(m.quantity, 0) AS ledger
+		FROM stock_levels s
+		FULL OUTER JOIN (
+			SELECT product_id, variant_id, warehouse_id, SUM(quantity) AS quantity
+			FROM stock_movements
+			WHERE warehouse_id IS NOT NULL
+			GROUP BY product_id, variant_id, warehouse_id
+		) m ON m.product_id = s.product_id AND m.variant_id = s.variant_id AND m.warehouse_id = s.warehouse_id
+		ORDER BY 1, 2, 3`,
+	).Scan(&balances).Error
+	return balances, err
+}
+
+// Comparing the stock of every product with the sum of all its movements
+func (r *StockMovementRepositoryImpl) GetProductBalances() ([]StockBalance, error) {
+	var balances []StockBalance
+	err := r.Db.Raw(
+		`SELECT p.product_id, p.product_stock AS recorded, COALESCE(SUM(m.quantity), 0) AS ledger
+		FROM products p
+		LEFT JOIN stock_movements m ON m.product_id = p.product_id
+		GROUP BY p.product_id, p.product_stock
+		ORDER BY p.product_id`,
+	).Scan(&balances).Error
+	return balances, err
+}
+
+func (r *StockMovementRepositoryImpl) SetWarehouseStock(warehouseId uint, productId uint, variantId uint, quantity int) error {
+	level := model.StockLevel{WarehouseID: warehouseId, ProductID: productId, VariantID: variantId}
+	return r.Db.Where(map[string]interface{}{"warehouse_id": warehouseId, "product_id": productId, "variant_id": variantId}).
+		Assign(map[string]interface{}{"quantity": quantity}).
+		FirstOrCreate(&level).Error
+}
+
+func (r *StockMovementRepositoryImpl) SetProductStock(productId uint, quantity int) error {
+	return r.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Product{}).
+			Where("product_id=?", productId).
+			UpdateColumn("product_stock", quantity).Error; err != nil {
+			return err
+		}
+		return updateWishlistStockFlags(tx, productId)
+	})
+}
+
+// Summing the units sold per product since the given time, sales are negative movements in the ledger
+func (r *StockMovementRepositoryImpl) GetUnitsSold(since time.Time) (map[uint]int, error) {
+	var rows []struct {
+		ProductID uint
+		Quantity  int
+	}
+	err := r.Db.Model(&model.StockMovement{}).
+		Select("product_id, -SUM(quantity) AS quantity").
+		Where("reason=? AND created_at >= ?", model.StockMovementSale, since).
+		Group("product_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sold := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		sold[row.ProductID] = row.Quantity
+	}
+	return sold, nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"shophub-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type TaxRepository interface {
+	CreateTaxRate(rate *model.TaxRate) error
+	GetAllTaxRates() ([]model.TaxRate, error)
+	GetActiveTaxRates(country string) ([]model.TaxRate, error)
+}
+
+type TaxRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+func NewTaxRepository(Db *gorm.DB) TaxRepository {
+	return &TaxRepositoryImpl{Db: Db}
+}
+
+func (r *TaxRepositoryImpl) CreateTaxRate(rate *model.TaxRate) error {
+	return r.Db.Create(rate).Error
+}
+
+func (r *TaxRepositoryImpl) GetAllTaxRates() ([]model.TaxRate, error) {
+	var rates []model.TaxRate
+	err := r.Db.Order("country ASC, region ASC, tax_rate_id ASC").Find(&rates).Error
+	return rates, err
+}
+
+// Countries are matched case-insensitively, the region and category are matched by the calculator
+func (r *TaxRepositoryImpl) GetActiveTaxRates(country string) ([]model.TaxRate, error) {
+	var rates []model.TaxRate
+	err := r.Db.
+		Where("is_active=? AND LOWER(country)=LOWER(?)", true, country).
+		Order("tax_rate_id ASC").
+		Find(&rates).Error
+	return rates, err
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"shophub-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type VariantRepository interface {
+	CreateOption(option *model.ProductOption) error
+	CreateVariant(variant *model.ProductVariant) error
+	GetVariantById(variantId uint) (*model.ProductVariant, error)
+	GetVariantBySKU(sku string) (*model.ProductVariant, error)
+	UpdateVariant(variant *model.ProductVariant) error
+}
+
+type VariantRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+func NewVariantRepository(Db *gorm.DB) VariantRepository {
+	return &VariantRepositoryImpl{Db: Db}
+}
+
+// Creating the option together with its values
+func (r *VariantRepositoryImpl) CreateOption(option *model.ProductOption) error {
+	return r.Db.Create(option).Error
+}
+
+// Creating the variant with its images, the option values already exist and are only linked
+func (r *VariantRepositoryImpl) CreateVariant(variant *model.ProductVariant) error {
+	return r.Db.Omit("OptionValues.*").Create(variant).Error
+}
+
+func (r *VariantRepositoryImpl) GetVariantById(variantId uint) (*model.ProductVariant, error) {
+	var variant model.ProductVariant
+	err := r.Db.
+		Preload("OptionValues").
+		Preload("Images", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC, image_id ASC")
+		}).
+		First(&variant, variantId).Error
+	if err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+func (r *VariantRepositoryImpl) GetVariantBySKU(sku string) (*model.ProductVariant, error) {
+	var variant model.ProductVariant
+	if err := r.Db.Where("sku=?", sku).First(&variant).Error; err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// Updating the SKU, price and status of the variant and replacing its images,
+// the option values of a variant never change
+func (r *VariantRepositoryImpl) UpdateVariant(variant *model.ProductVariant) error {
+	return r.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(variant).
+			Select("sku", "price_override", "is_active").
+			Updates(map[string]interface{}{
+				"sku":            variant.SKU,
+				"price_override": variant.PriceOverride,
+				"is_active":      variant.IsActive,
+			}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("variant_id=?", variant.VariantID).Delete(&model.ProductVariantImage{}).Error; err != nil {
+			return err
+		}
+		for i := range variant.Images {
+			variant.Images[i].ImageID = 0
+			variant.Images[i].VariantID = variant.VariantID
+		}
+		if len(variant.Images) == 0 {
+			return nil
+		}
+		return tx.Create(&variant.Images).Error
+	})
+}
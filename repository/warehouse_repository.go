@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"errors"
+	"shophub-backend/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrStockBelowReserved = errors.New("stock cannot go below the reserved quantity")
+
+type WarehouseRepository interface {
+	CreateWarehouse(warehouse *model.Warehouse) error
+	GetAllWarehouses() ([]model.Warehouse, error)
+	GetWarehouseById(warehouseId uint) (*model.Warehouse, error)
+	GetStockLevels(warehouseId uint) ([]model.StockLevel, error)
+	AdjustStock(movement model.StockMovement) (*model.StockLevel, error)
+}
+
+type WarehouseRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+func NewWarehouseRepository(Db *gorm.DB) WarehouseRepository {
+	return &WarehouseRepositoryImpl{Db: Db}
+}
+
+func (r *WarehouseRepositoryImpl) CreateWarehouse(warehouse *model.Warehouse) error {
+	return r.Db.Create(warehouse).Error
+}
+
+func (r *WarehouseRepositoryImpl) GetAllWarehouses() ([]model.Warehouse, error) {
+	var warehouses []model.Warehouse
+	err := r.Db.Order("warehouse_id ASC").Find(&warehouses).Error
+	return warehouses, err
+}
+
+func (r *WarehouseRepositoryImpl) GetWarehouseById(warehouseId uint) (*model.Warehouse, error) {
+	var warehouse model.Warehouse
+	if err := r.Db.First(&warehouse, warehouseId).Error; err != nil {
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+func (r *WarehouseRepositoryImpl) GetStockLevels(warehouseId uint) ([]model.StockLevel, error) {
+	var levels []model.StockLevel
+	err := r.Db.Where("warehouse_id=?", warehouseId).Order("product_id ASC, variant_id ASC").Find(&levels).Error
+	return levels, err
+}
+
+// Changing the stock of the product in the warehouse by the quantity of the movement and recording it in
+// the ledger, the product's total stock changes with it. The stock cannot go below what is reserved for
+// unpaid orders.
+func (r *WarehouseRepositoryImpl) AdjustStock(movement model.StockMovement) (*model.StockLevel, error) {
+	warehouseId, productId, variantId, delta := *movement.WarehouseID, movement.ProductID, movement.VariantID, movement.Quantity
+
+	var level model.StockLevel
+	err := r.Db.Transaction(func(tx *gorm.DB) error {
+		var product model.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, productId).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where(map[string]interface{}{"warehouse_id": warehouseId, "product_id": productId, "variant_id": variantId}).
+			Attrs(model.StockLevel{WarehouseID: warehouseId, ProductID: productId, VariantID: variantId}).
+			FirstOrCreate(&level).Error; err != nil {
+			return err
+		}
+
+		var reserved int64
+		if err := tx.Model(&model.InventoryReservation{}).
+			Where("warehouse_id=? AND product_id=? AND variant_id=? AND status=?", warehouseId, productId, variantId, model.ReservationStatusActive).
+			Select("COALESCE(SUM(quantity), 0)").
+			Scan(&reserved).Error; err != nil {
+			return err
+		}
+		if int64(level.Quantity+delta) < reserved {
+			return ErrStockBelowReserved
+		}
+
+		level.Quantity += delta
+		if err := tx.Model(&level).Update("quantity", level.Quantity).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Product{}).
+			Where("product_id=?", productId).
+			UpdateColumn("product_stock", gorm.Expr("product_stock + ?", delta)).Error; err != nil {
+			return err
+		}
+		if err := updateWishlistStockFlags(tx, productId); err != nil {
+			return err
+		}
+		return recordMovement(tx, movement)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &level, nil
+}
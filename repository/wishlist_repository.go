@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"shophub-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type WishlistRepository interface {
+	GetWishlistsByUser(keycloakUserID string) ([]model.Wishlist, error)
+	GetWishlistById(wishlistId uint) (*model.Wishlist, error)
+	GetDefaultWishlist(keycloakUserID string) (*model.Wishlist, error)
+	CreateWishlist(wishlist *model.Wishlist) error
+	DeleteWishlist(wishlistId uint) error
+	AddItemToWishlist(item *model.WishlistItem) error
+	GetWishlistItemById(itemId uint) (*model.WishlistItem, error)
+	GetWishlistItemByProductId(wishlistId uint, productId uint) (*model.WishlistItem, error)
+	RemoveItemFromWishlist(itemId uint) error
+}
+
+type WishlistRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+func NewWishlistRepository(Db *gorm.DB) WishlistRepository {
+	return &WishlistRepositoryImpl{Db: Db}
+}
+
+func (r *WishlistRepositoryImpl) GetWishlistsByUser(keycloakUserID string) ([]model.Wishlist, error) {
+	var wishlists []model.Wishlist
+	err := r.Db.
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Items.Product", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Where("keycloak_user_id=?", keycloakUserID).
+		Order("wishlist_id ASC").
+		Find(&wishlists).Error
+	return wishlists, err
+}
+
+func (r *WishlistRepositoryImpl) GetWishlistById(wishlistId uint) (*model.Wishlist, error) {
+	var wishlist model.Wishlist
+	if err := r.Db.
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Items.Product", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		First(&wishlist, wishlistId).Error; err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+func (r *WishlistRepositoryImpl) GetDefaultWishlist(keycloakUserID string) (*model.Wishlist, error) {
+	var wishlist model.Wishlist
+	if err := r.Db.Where("keycloak_user_id=? AND is_default=?", keycloakUserID, true).First(&wishlist).Error; err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+func (r *WishlistRepositoryImpl) CreateWishlist(wishlist *model.Wishlist) error {
+	return r.Db.Create(wishlist).Error
+}
+
+// Deleting the wishlist together with its items
+func (r *WishlistRepositoryImpl) DeleteWishlist(wishlistId uint) error {
+	return r.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("wishlist_id=?", wishlistId).Delete(&model.WishlistItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Wishlist{}, wishlistId).Error
+	})
+}
+
+func (r *WishlistRepositoryImpl) AddItemToWishlist(item *model.WishlistItem) error {
+	return r.Db.Create(item).Error
+}
+
+func (r *WishlistRepositoryImpl) GetWishlistItemById(itemId uint) (*model.WishlistItem, error) {
+	var item model.WishlistItem
+	if err := r.Db.First(&item, itemId).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *WishlistRepositoryImpl) GetWishlistItemByProductId(wishlistId uint, productId uint) (*model.WishlistItem, error) {
+	var item model.WishlistItem
+	if err := r.Db.Where("wishlist_id = ? AND product_id = ?", wishlistId, productId).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *WishlistRepositoryImpl) RemoveItemFromWishlist(itemId uint) error {
+	return r.Db.Delete(&model.WishlistItem{}, itemId).Error
+}
+
+// updateWishlistStockFlags flags the wishlist items of the product from its current stock, items whose
+// product went from zero stock back to positive stock are flagged back in stock. It is called in the
+// transaction that changes the product stock.
+func updateWishlistStockFlags(tx *gorm.DB, productId uint) error {
+	var stock int
+	if err := tx.Model(&model.Product{}).Unscoped().
+		Where("product_id=?", productId).
+		Select("product_stock").
+		Scan(&stock).Error; err != nil {
+		return err
+	}
+
+	if stock <= 0 {
+		return tx.Model(&model.WishlistItem{}).
+			Where("product_id=? AND out_of_stock=?", productId, false).
+			Updates(map[string]interface{}{
+				"out_of_stock":  true,
+				"back_in_stock": false,
+			}).Error
+	}
+	return tx.Model(&model.WishlistItem{}).
+		Where("product_id=? AND out_of_stock=?", productId, true).
+		Updates(map[string]interface{}{
+			"out_of_stock":  false,
+			"back_in_stock": true,
+		}).Error
+}
@@ -9,6 +9,10 @@ import (
 type AddressControllerInterface interface {
 	GetUserAddresses(ctx *gin.Context)
 	CreateAddress(ctx *gin.Context)
+	UpdateAddress(ctx *gin.Context)
+	DeleteAddress(ctx *gin.Context)
+	SetDefaultShippingAddress(ctx *gin.Context)
+	SetDefaultBillingAddress(ctx *gin.Context)
 }
 
 func RegisterAddressRoutes(router *gin.Engine, controller AddressControllerInterface) {
@@ -19,5 +23,11 @@ func RegisterAddressRoutes(router *gin.Engine, controller AddressControllerInter
 		addressGroup.GET("/", controller.GetUserAddresses)
 		// Create a new address for the authenticated user
 		addressGroup.POST("/", controller.CreateAddress)
+		addressGroup.PUT("/:addressId", controller.UpdateAddress)
+		// Deleted addresses are kept for the orders that were shipped to them
+		addressGroup.DELETE("/:addressId", controller.DeleteAddress)
+
+		addressGroup.PUT("/:addressId/default-shipping", controller.SetDefaultShippingAddress)
+		addressGroup.PUT("/:addressId/default-billing", controller.SetDefaultBillingAddress)
 	}
 }
@@ -0,0 +1,22 @@
+package router
+
+import (
+	"shophub-backend/auth"
+	"shophub-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminReportControllerInterface interface {
+	GetAbandonmentReport(ctx *gin.Context)
+}
+
+func RegisterAdminReportRoutes(router *gin.Engine, controller AdminReportControllerInterface) {
+	authMiddleware := auth.AuthMiddleware()
+	adminMiddleware := auth.RequireRole(config.LoadConfig().AdminRole)
+	reportGroup := router.Group("/admin/reports", authMiddleware, adminMiddleware)
+	{
+		// Abandonment rate of carts idle beyond the threshold
+		reportGroup.GET("/abandoned-carts", controller.GetAbandonmentReport)
+	}
+}
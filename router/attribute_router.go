@@ -0,0 +1,35 @@
+package router
+
+import (
+	"shophub-backend/auth"
+	"shophub-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttributeControllerInterface interface {
+	CreateAttribute(ctx *gin.Context)
+	GetCategoryAttributes(ctx *gin.Context)
+	SetProductAttributes(ctx *gin.Context)
+}
+
+func RegisterAttributeRoutes(router *gin.Engine, controller AttributeControllerInterface) {
+	categoryGroup := router.Group("/categories")
+	{
+		// Attribute definitions of a category, used to render the product filters
+		categoryGroup.GET("/:categoryId/attributes", controller.GetCategoryAttributes)
+	}
+
+	authMiddleware := auth.AuthMiddleware()
+	adminMiddleware := auth.RequireRole(config.LoadConfig().AdminRole)
+	adminCategoryGroup := router.Group("/admin/categories", authMiddleware, adminMiddleware)
+	{
+		adminCategoryGroup.POST("/:categoryId/attributes", controller.CreateAttribute)
+	}
+
+	adminProductGroup := router.Group("/admin/products", authMiddleware, adminMiddleware)
+	{
+		// Replaces the attribute values of the product, by attribute code
+		adminProductGroup.PUT("/:id/attributes", controller.SetProductAttributes)
+	}
+}
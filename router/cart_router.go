@@ -12,6 +12,11 @@ type CartControllerInterface interface {
 	ClearCart(ctx *gin.Context)
 	RemoveItemFromCart(ctx *gin.Context)
 	UpdateCartItemQuantity(ctx *gin.Context)
+	SelectCartItem(ctx *gin.Context)
+	SelectAllCartItems(ctx *gin.Context)
+	AcknowledgePriceChanges(ctx *gin.Context)
+	ApplyCoupon(ctx *gin.Context)
+	RemoveCoupon(ctx *gin.Context)
 }
 
 func RegisterCartRoutes(router *gin.Engine, controller CartControllerInterface) {
@@ -24,5 +29,10 @@ func RegisterCartRoutes(router *gin.Engine, controller CartControllerInterface)
 		cartGroup.DELETE("/items", controller.ClearCart)
 		cartGroup.DELETE("/item/:itemId", controller.RemoveItemFromCart)
 		cartGroup.PATCH("/item/:itemId", controller.UpdateCartItemQuantity)
+		cartGroup.PATCH("/item/:itemId/select", controller.SelectCartItem)
+		cartGroup.PATCH("/items/select", controller.SelectAllCartItems)
+		cartGroup.POST("/acknowledge-prices", controller.AcknowledgePriceChanges)
+		cartGroup.POST("/coupon", controller.ApplyCoupon)
+		cartGroup.DELETE("/coupon", controller.RemoveCoupon)
 	}
 }
@@ -0,0 +1,25 @@
+package router
+
+import (
+	"shophub-backend/auth"
+	"shophub-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CatalogControllerInterface interface {
+	ImportProducts(ctx *gin.Context)
+	ExportProducts(ctx *gin.Context)
+}
+
+func RegisterCatalogRoutes(router *gin.Engine, controller CatalogControllerInterface) {
+	authMiddleware := auth.AuthMiddleware()
+	adminMiddleware := auth.RequireRole(config.LoadConfig().AdminRole)
+	catalogGroup := router.Group("/admin/products", authMiddleware, adminMiddleware)
+	{
+		// Creates or updates products from a CSV, matched by slug, ?dry_run=true only validates
+		catalogGroup.POST("/import", controller.ImportProducts)
+		// The whole catalogue as CSV, in the format the import reads
+		catalogGroup.GET("/export", controller.ExportProducts)
+	}
+}
@@ -8,6 +8,8 @@ import (
 
 type CheckoutControllerInterface interface {
 	CreateOrder(ctx *gin.Context)
+	GetShippingOptions(ctx *gin.Context)
+	Quote(ctx *gin.Context)
 }
 
 func RegisterCheckoutRoutes(router *gin.Engine, controller CheckoutControllerInterface) {
@@ -16,5 +18,9 @@ func RegisterCheckoutRoutes(router *gin.Engine, controller CheckoutControllerInt
 	{
 		// Route for placing an order during checkout
 		checkoutGroup.POST("/order", controller.CreateOrder)
+		// Route for the shipping methods available for the cart and address
+		checkoutGroup.GET("/shipping-options", controller.GetShippingOptions)
+		// Route for pricing the checkout without placing the order
+		checkoutGroup.POST("/quote", controller.Quote)
 	}
 }
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"shophub-backend/auth"
+	"shophub-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CouponControllerInterface interface {
+	GetAllCoupons(ctx *gin.Context)
+	CreateCoupon(ctx *gin.Context)
+}
+
+func RegisterCouponRoutes(router *gin.Engine, controller CouponControllerInterface) {
+	authMiddleware := auth.AuthMiddleware()
+	adminMiddleware := auth.RequireRole(config.LoadConfig().AdminRole)
+	couponGroup := router.Group("/admin/coupons", authMiddleware, adminMiddleware)
+	{
+		couponGroup.GET("/", controller.GetAllCoupons)
+		couponGroup.POST("/", controller.CreateCoupon)
+	}
+}
@@ -0,0 +1,26 @@
+package router
+
+import (
+	"shophub-backend/auth"
+	"shophub-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CurrencyControllerInterface interface {
+	GetExchangeRates(ctx *gin.Context)
+	UpdateExchangeRates(ctx *gin.Context)
+}
+
+func RegisterCurrencyRoutes(router *gin.Engine, controller CurrencyControllerInterface) {
+	// The supported currencies and their rates are public so clients can offer a currency selector
+	router.GET("/currencies", controller.GetExchangeRates)
+
+	authMiddleware := auth.AuthMiddleware()
+	adminMiddleware := auth.RequireRole(config.LoadConfig().AdminRole)
+	adminGroup := router.Group("/admin/exchange-rates", authMiddleware, adminMiddleware)
+	{
+		adminGroup.GET("/", controller.GetExchangeRates)
+		adminGroup.PUT("/", controller.UpdateExchangeRates)
+	}
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"shophub-backend/auth"
+	"shophub-backend/config"
 
 	"github.com/gin-gonic/gin"
 )
@@ -9,6 +10,7 @@ import (
 type OrderControllerInterface interface {
 	CreateOrder(ctx *gin.Context)
 	GetOrderByUser(ctx *gin.Context)
+	MarkDelivered(ctx *gin.Context)
 }
 
 // registering order route nested with payment route
@@ -23,4 +25,11 @@ func RegisterOrderRoutes(router *gin.Engine, controller OrderControllerInterface
 		orderGroup.GET("/user", controller.GetOrderByUser)
 	}
 
+	adminMiddleware := auth.RequireRole(config.LoadConfig().AdminRole)
+	adminOrderGroup := router.Group("/admin/orders", authMiddleware, adminMiddleware)
+	{
+		// Delivered orders let the customer review the product
+		adminOrderGroup.POST("/:orderId/deliver", controller.MarkDelivered)
+	}
+
 }
@@ -9,6 +9,7 @@ import (
 type PaymentControlInterface interface {
 	GetPaymentByOrderId(ctx *gin.Context)
 	ProcessPayment(ctx *gin.Context)
+	FailPayment(ctx *gin.Context)
 }
 
 func RegisterPaymentRoutes(router *gin.Engine, controller PaymentControlInterface) {
@@ -20,5 +21,8 @@ func RegisterPaymentRoutes(router *gin.Engine, controller PaymentControlInterfac
 
 		//Processing the payment for an order
 		paymentGroup.POST("/order/:orderId/process", controller.ProcessPayment)
+
+		//Recording a failed payment on the user's pending order, the order is cancelled and its stock given back
+		paymentGroup.POST("/order/:orderId/fail", controller.FailPayment)
 	}
 }
@@ -1,6 +1,9 @@
 package router
 
 import (
+	"shophub-backend/auth"
+	"shophub-backend/config"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -8,6 +11,9 @@ type ProductControllerInterface interface {
 	GetAllProducts(ctx *gin.Context)
 	GetProductById(ctx *gin.Context)
 	GetProductBySlug(ctx *gin.Context)
+	GetStockHistory(ctx *gin.Context)
+	UpdateProductStatus(ctx *gin.Context)
+	DeleteProduct(ctx *gin.Context)
 }
 
 func RegisterProductRoutes(router *gin.Engine, controller ProductControllerInterface) {
@@ -21,4 +27,16 @@ func RegisterProductRoutes(router *gin.Engine, controller ProductControllerInter
 		//Route foe getting product by product slug
 		productGroup.GET("/slug/:productSlug", controller.GetProductBySlug)
 	}
+
+	authMiddleware := auth.AuthMiddleware()
+	adminMiddleware := auth.RequireRole(config.LoadConfig().AdminRole)
+	adminProductGroup := router.Group("/admin/products", authMiddleware, adminMiddleware)
+	{
+		//Route for the ledger of stock movements of a product
+		adminProductGroup.GET("/:id/stock-history", controller.GetStockHistory)
+		// Status and publishing schedule, only active products are shown in the store
+		adminProductGroup.PUT("/:id/status", controller.UpdateProductStatus)
+		// Archives and soft deletes the product
+		adminProductGroup.DELETE("/:id", controller.DeleteProduct)
+	}
 }
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"shophub-backend/auth"
+	"shophub-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromotionControllerInterface interface {
+	GetAllPromotions(ctx *gin.Context)
+	CreatePromotion(ctx *gin.Context)
+}
+
+func RegisterPromotionRoutes(router *gin.Engine, controller PromotionControllerInterface) {
+	authMiddleware := auth.AuthMiddleware()
+	adminMiddleware := auth.RequireRole(config.LoadConfig().AdminRole)
+	promotionGroup := router.Group("/admin/promotions", authMiddleware, adminMiddleware)
+	{
+		promotionGroup.GET("/", controller.GetAllPromotions)
+		promotionGroup.POST("/", controller.CreatePromotion)
+	}
+}
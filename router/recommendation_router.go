@@ -0,0 +1,15 @@
+package router
+
+import "github.com/gin-gonic/gin"
+
+type RecommendationControllerInterface interface {
+	GetRecommendations(ctx *gin.Context)
+}
+
+func RegisterRecommendationRoutes(router *gin.Engine, controller RecommendationControllerInterface) {
+	recommendationGroup := router.Group("/products")
+	{
+		// Products bought together with the product, then bestsellers of its category, ?limit= caps the number
+		recommendationGroup.GET("/:id/recommendations", controller.GetRecommendations)
+	}
+}
@@ -0,0 +1,35 @@
+package router
+
+import (
+	"shophub-backend/auth"
+	"shophub-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewControllerInterface interface {
+	CreateReview(ctx *gin.Context)
+	GetProductReviews(ctx *gin.Context)
+	GetReviews(ctx *gin.Context)
+	ModerateReview(ctx *gin.Context)
+}
+
+func RegisterReviewRoutes(router *gin.Engine, controller ReviewControllerInterface) {
+	authMiddleware := auth.AuthMiddleware()
+
+	reviewGroup := router.Group("/products")
+	{
+		// Approved reviews of the product
+		reviewGroup.GET("/:id/reviews", controller.GetProductReviews)
+		// Customers with a delivered order of the product can review it once
+		reviewGroup.POST("/:id/reviews", authMiddleware, controller.CreateReview)
+	}
+
+	adminMiddleware := auth.RequireRole(config.LoadConfig().AdminRole)
+	adminReviewGroup := router.Group("/admin/reviews", authMiddleware, adminMiddleware)
+	{
+		// Reviews waiting for moderation, or with the status given by ?status=
+		adminReviewGroup.GET("/", controller.GetReviews)
+		adminReviewGroup.PUT("/:reviewId/status", controller.ModerateReview)
+	}
+}
@@ -0,0 +1,25 @@
+package router
+
+import (
+	"shophub-backend/auth"
+	"shophub-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShippingControllerInterface interface {
+	GetAllZones(ctx *gin.Context)
+	CreateZone(ctx *gin.Context)
+	CreateMethod(ctx *gin.Context)
+}
+
+func RegisterShippingRoutes(router *gin.Engine, controller ShippingControllerInterface) {
+	authMiddleware := auth.AuthMiddleware()
+	adminMiddleware := auth.RequireRole(config.LoadConfig().AdminRole)
+	shippingGroup := router.Group("/admin/shipping/zones", authMiddleware, adminMiddleware)
+	{
+		shippingGroup.GET("/", controller.GetAllZones)
+		shippingGroup.POST("/", controller.CreateZone)
+		shippingGroup.POST("/:zoneId/methods", controller.CreateMethod)
+	}
+}
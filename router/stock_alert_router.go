@@ -0,0 +1,24 @@
+package router
+
+import (
+	"shophub-backend/auth"
+	"shophub-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StockAlertControllerInterface interface {
+	GetLowStockReport(ctx *gin.Context)
+	SetReorderThreshold(ctx *gin.Context)
+}
+
+func RegisterStockAlertRoutes(router *gin.Engine, controller StockAlertControllerInterface) {
+	authMiddleware := auth.AuthMiddleware()
+	adminMiddleware := auth.RequireRole(config.LoadConfig().AdminRole)
+	stockAlertGroup := router.Group("/admin/products", authMiddleware, adminMiddleware)
+	{
+		// Products at or below their reorder threshold, fastest selling first
+		stockAlertGroup.GET("/low-stock", controller.GetLowStockReport)
+		stockAlertGroup.PUT("/:id/reorder-threshold", controller.SetReorderThreshold)
+	}
+}
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"shophub-backend/auth"
+	"shophub-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TaxControllerInterface interface {
+	GetAllTaxRates(ctx *gin.Context)
+	CreateTaxRate(ctx *gin.Context)
+}
+
+func RegisterTaxRoutes(router *gin.Engine, controller TaxControllerInterface) {
+	authMiddleware := auth.AuthMiddleware()
+	adminMiddleware := auth.RequireRole(config.LoadConfig().AdminRole)
+	taxGroup := router.Group("/admin/tax-rates", authMiddleware, adminMiddleware)
+	{
+		taxGroup.GET("/", controller.GetAllTaxRates)
+		taxGroup.POST("/", controller.CreateTaxRate)
+	}
+}
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"shophub-backend/auth"
+	"shophub-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VariantControllerInterface interface {
+	CreateOption(ctx *gin.Context)
+	CreateVariant(ctx *gin.Context)
+	UpdateVariant(ctx *gin.Context)
+}
+
+func RegisterVariantRoutes(router *gin.Engine, controller VariantControllerInterface) {
+	authMiddleware := auth.AuthMiddleware()
+	adminMiddleware := auth.RequireRole(config.LoadConfig().AdminRole)
+	variantGroup := router.Group("/admin/products", authMiddleware, adminMiddleware)
+	{
+		// Options such as size and colour, added before the variants
+		variantGroup.POST("/:id/options", controller.CreateOption)
+
+		variantGroup.POST("/:id/variants", controller.CreateVariant)
+		variantGroup.PUT("/:id/variants/:variantId", controller.UpdateVariant)
+	}
+}
@@ -0,0 +1,29 @@
+package router
+
+import (
+	"shophub-backend/auth"
+	"shophub-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WarehouseControllerInterface interface {
+	GetAllWarehouses(ctx *gin.Context)
+	CreateWarehouse(ctx *gin.Context)
+	GetWarehouseStock(ctx *gin.Context)
+	AdjustStock(ctx *gin.Context)
+}
+
+func RegisterWarehouseRoutes(router *gin.Engine, controller WarehouseControllerInterface) {
+	authMiddleware := auth.AuthMiddleware()
+	adminMiddleware := auth.RequireRole(config.LoadConfig().AdminRole)
+	warehouseGroup := router.Group("/admin/warehouses", authMiddleware, adminMiddleware)
+	{
+		warehouseGroup.GET("/", controller.GetAllWarehouses)
+		warehouseGroup.POST("/", controller.CreateWarehouse)
+
+		// Stock per product in the warehouse
+		warehouseGroup.GET("/:warehouseId/stock", controller.GetWarehouseStock)
+		warehouseGroup.POST("/:warehouseId/stock/:productId/adjust", controller.AdjustStock)
+	}
+}
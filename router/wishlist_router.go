@@ -0,0 +1,37 @@
+package router
+
+import (
+	"shophub-backend/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WishlistControllerInterface interface {
+	GetUserWishlists(ctx *gin.Context)
+	CreateWishlist(ctx *gin.Context)
+	DeleteWishlist(ctx *gin.Context)
+	AddToWishlist(ctx *gin.Context)
+	RemoveFromWishlist(ctx *gin.Context)
+	MoveToCart(ctx *gin.Context)
+	SaveForLater(ctx *gin.Context)
+}
+
+func RegisterWishlistRoutes(router *gin.Engine, controller WishlistControllerInterface) {
+	authMiddleware := auth.AuthMiddleware()
+	wishlistGroup := router.Group("/wishlists", authMiddleware)
+	{
+		// Get all wishlists for the authenticated user
+		wishlistGroup.GET("/", controller.GetUserWishlists)
+		// Create a new named wishlist
+		wishlistGroup.POST("/", controller.CreateWishlist)
+		wishlistGroup.DELETE("/:wishlistId", controller.DeleteWishlist)
+
+		wishlistGroup.POST("/:wishlistId/items", controller.AddToWishlist)
+		wishlistGroup.DELETE("/:wishlistId/items/:itemId", controller.RemoveFromWishlist)
+		// Move a wishlist item into the cart
+		wishlistGroup.POST("/:wishlistId/items/:itemId/move-to-cart", controller.MoveToCart)
+
+		// Move a cart item into a wishlist
+		wishlistGroup.POST("/save-for-later/:cartItemId", controller.SaveForLater)
+	}
+}
@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"shophub-backend/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var newTicker = time.NewTicker
+
+// Start runs the task every interval in a background goroutine until the returned stop function is called
+func Start(name string, interval time.Duration, task func() error) (stop func()) {
+	if interval <= 0 {
+		logger.AppWarn("Scheduled job disabled, interval must be positive", zap.String("job", name))
+		return func() {}
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		ticker := newTicker(interval)
+		defer ticker.Stop()
+
+		logger.AppInfo("Scheduled job started", zap.String("job", name), zap.Duration("interval", interval))
+		for {
+			select {
+			case <-ticker.C:
+				run(name, task)
+			case <-done:
+				logger.AppInfo("Scheduled job stopped", zap.String("job", name))
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
+
+// run executes a single task run, recovering from panics so the job keeps running
+func run(name string, task func() error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.AppError("Scheduled job panicked", zap.String("job", name), zap.Any("panic", r))
+		}
+	}()
+
+	if err := task(); err != nil {
+		logger.AppError("Scheduled job failed", zap.String("job", name), zap.Error(err))
+	}
+}
@@ -0,0 +1,126 @@
+package scheduler
+
+import (
+	"errors"
+	"os"
+	"shophub-backend/logger"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init()
+	os.Exit(m.Run())
+}
+
+// fakeTicker replaces newTicker with a ticker that only ticks when the test sends on the returned channel
+func fakeTicker(t *testing.T) (ticks chan time.Time, intervals chan time.Duration) {
+	t.Helper()
+	ticks = make(chan time.Time)
+	intervals = make(chan time.Duration, 1)
+
+	original := newTicker
+	newTicker = func(interval time.Duration) *time.Ticker {
+		intervals <- interval
+		return &time.Ticker{C: ticks}
+	}
+	t.Cleanup(func() { newTicker = original })
+	return ticks, intervals
+}
+
+func tick(t *testing.T, ticks chan time.Time) {
+	t.Helper()
+	select {
+	case ticks <- time.Now():
+	case <-time.After(time.Second):
+		t.Fatal("the job did not wait for the tick")
+	}
+}
+
+func waitForRun(t *testing.T, runs chan int) int {
+	t.Helper()
+	select {
+	case run := <-runs:
+		return run
+	case <-time.After(time.Second):
+		t.Fatal("the task did not run")
+		return 0
+	}
+}
+
+func TestStartRunsTaskOnEveryTick(t *testing.T) {
+	ticks, intervals := fakeTicker(t)
+
+	runs := make(chan int)
+	count := 0
+	stop := Start("test", time.Minute, func() error {
+		count++
+		runs <- count
+		return nil
+	})
+	defer stop()
+
+	if interval := <-intervals; interval != time.Minute {
+		t.Fatalf("ticker interval = %v, want %v", interval, time.Minute)
+	}
+	for want := 1; want <= 3; want++ {
+		tick(t, ticks)
+		if got := waitForRun(t, runs); got != want {
+			t.Fatalf("run = %d, want %d", got, want)
+		}
+	}
+}
+
+func TestStartKeepsRunningAfterFailures(t *testing.T) {
+	tests := []struct {
+		name string
+		fail func()
+	}{
+		{"error", func() {}},
+		{"panic", func() { panic("task panicked") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticks, _ := fakeTicker(t)
+
+			runs := make(chan int, 2)
+			count := 0
+			stop := Start("test", time.Minute, func() error {
+				count++
+				runs <- count
+				if count == 1 {
+					tt.fail()
+					return errors.New("task failed")
+				}
+				return nil
+			})
+			defer stop()
+
+			tick(t, ticks)
+			waitForRun(t, runs)
+			tick(t, ticks)
+			if got := waitForRun(t, runs); got != 2 {
+				t.Fatalf("run = %d, want 2", got)
+			}
+		})
+	}
+}
+
+func TestStartDisabledForNonPositiveInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Minute} {
+		_, intervals := fakeTicker(t)
+
+		stop := Start("test", interval, func() error {
+			t.Error("disabled job ran")
+			return nil
+		})
+		stop()
+
+		select {
+		case <-intervals:
+			t.Fatalf("ticker started for interval %v", interval)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
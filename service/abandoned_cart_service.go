@@ -0,0 +1,147 @@
+package service
+
+import (
+	"fmt"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/model"
+	"shophub-backend/money"
+	"shophub-backend/notification"
+	"shophub-backend/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const NotificationTypeAbandonedCart = "abandoned_cart_reminder"
+
+type AbandonedCartService interface {
+	SendReminders() (int, error)
+	GetAbandonmentReport(threshold time.Duration) (*data.AbandonedCartReport, error)
+}
+
+type AbandonedCartServiceImpl struct {
+	CartRepository  repository.CartRepository
+	CurrencyService CurrencyService
+	Notifier        notification.Notifier
+	Threshold       time.Duration
+}
+
+func NewAbandonedCartServiceImpl(CartRepository repository.CartRepository, CurrencyService CurrencyService, Notifier notification.Notifier, Threshold time.Duration) (service AbandonedCartService, err error) {
+	return &AbandonedCartServiceImpl{
+		CartRepository:  CartRepository,
+		CurrencyService: CurrencyService,
+		Notifier:        Notifier,
+		Threshold:       Threshold,
+	}, err
+}
+
+// SendReminders notifies the owners of idle carts, at most once per period of inactivity
+func (s *AbandonedCartServiceImpl) SendReminders() (int, error) {
+	carts, err := s.CartRepository.GetIdleCarts(time.Now().Add(-s.Threshold))
+	if err != nil {
+		logger.ActError("Error retrieving idle carts", zap.Error(err))
+		return 0, fmt.Errorf("failed to get idle carts")
+	}
+
+	sent := 0
+	for _, cart := range carts {
+		// Already reminded since the last change to the cart
+		if cart.ReminderSentAt != nil && cart.ReminderSentAt.After(cart.UpdatedAt) {
+			continue
+		}
+
+		itemCount, cartValue := s.cartTotals(cart)
+		err := s.Notifier.Notify(notification.Notification{
+			Type:      NotificationTypeAbandonedCart,
+			Recipient: cart.KeycloakUserID,
+			Subject:   "You left items in your cart",
+			Message:   fmt.Sprintf("You have %d item(s) waiting in your cart.", itemCount),
+			Data: map[string]interface{}{
+				"cart_id":    cart.CartID,
+				"item_count": itemCount,
+				"cart_value": cartValue,
+			},
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			logger.ActError("Unable to send abandoned cart reminder", zap.Uint("cart_id", cart.CartID), zap.Error(err))
+			continue
+		}
+
+		if err := s.CartRepository.MarkReminderSent(cart.CartID, time.Now()); err != nil {
+			logger.ActError("Unable to mark abandoned cart reminder as sent", zap.Uint("cart_id", cart.CartID), zap.Error(err))
+			continue
+		}
+		sent++
+	}
+
+	logger.ActInfo("Abandoned cart reminders sent", zap.Int("count", sent))
+	return sent, nil
+}
+
+// GetAbandonmentReport reports the non-empty carts idle beyond the threshold, using the configured threshold when zero
+func (s *AbandonedCartServiceImpl) GetAbandonmentReport(threshold time.Duration) (*data.AbandonedCartReport, error) {
+	if threshold <= 0 {
+		threshold = s.Threshold
+	}
+
+	nonEmptyCarts, err := s.CartRepository.CountNonEmptyCarts()
+	if err != nil {
+		logger.ActError("Error counting carts", zap.Error(err))
+		return nil, fmt.Errorf("failed to count carts")
+	}
+
+	carts, err := s.CartRepository.GetIdleCarts(time.Now().Add(-threshold))
+	if err != nil {
+		logger.ActError("Error retrieving idle carts", zap.Error(err))
+		return nil, fmt.Errorf("failed to get idle carts")
+	}
+
+	report := &data.AbandonedCartReport{
+		ThresholdMinutes: int(threshold.Minutes()),
+		NonEmptyCarts:    nonEmptyCarts,
+		AbandonedCarts:   len(carts),
+		GeneratedAt:      time.Now(),
+		Carts:            []data.AbandonedCartSummary{},
+	}
+
+	for _, cart := range carts {
+		itemCount, cartValue := s.cartTotals(cart)
+		report.AbandonedValue = report.AbandonedValue.Add(cartValue)
+		if cart.ReminderSentAt != nil {
+			report.RemindersSent++
+		}
+
+		report.Carts = append(report.Carts, data.AbandonedCartSummary{
+			CartID:         cart.CartID,
+			KeycloakUserID: cart.KeycloakUserID,
+			ItemCount:      itemCount,
+			CartValue:      cartValue,
+			LastActivityAt: cart.UpdatedAt,
+			ReminderSentAt: cart.ReminderSentAt,
+		})
+	}
+
+	if nonEmptyCarts > 0 {
+		report.AbandonmentRate = float64(len(carts)) / float64(nonEmptyCarts)
+	}
+
+	return report, nil
+}
+
+// cartTotals returns the item count and value of the cart at current product prices, in the default currency
+func (s *AbandonedCartServiceImpl) cartTotals(cart model.Cart) (int, money.Money) {
+	itemCount := 0
+	cartValue := money.Zero(money.DefaultCurrency)
+	for _, item := range cart.Items {
+		itemCount += item.Quantity
+		price, err := s.CurrencyService.Convert(item.CurrentPrice(), money.DefaultCurrency)
+		if err != nil {
+			logger.ActError("Unable to convert cart item price", zap.Uint("product_id", item.ProductID), zap.Error(err))
+			continue
+		}
+		cartValue = cartValue.Add(price.Mul(item.Quantity))
+	}
+	return itemCount, cartValue
+}
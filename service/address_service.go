@@ -1,22 +1,40 @@
 package service
 
 import (
+	"fmt"
+	"shophub-backend/data"
+	"shophub-backend/logger"
 	"shophub-backend/model"
+	"shophub-backend/postal"
 	"shophub-backend/repository"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	AddressDefaultShipping = "shipping"
+	AddressDefaultBilling  = "billing"
 )
 
 type AddressService interface {
 	GetAddressesByUser(keycloakUserID string) ([]model.Address, error)
 	CreateAddress(address *model.Address) error
+	UpdateAddress(keycloakUserID string, addressId uint, req data.CreateAddressRequest) (*model.Address, error)
+	DeleteAddress(keycloakUserID string, addressId uint) error
+	SetDefaultAddress(keycloakUserID string, addressId uint, kind string) (*model.Address, error)
 }
 
 type AddressServiceImpl struct {
 	AddressRepository repository.AddressRepository
+	// MaxAddresses is how many addresses a user can keep in the address book, zero is unlimited
+	MaxAddresses int
 }
 
-func NewAddressServiceImpl(AddressRepository repository.AddressRepository) (service AddressService, err error) {
+func NewAddressServiceImpl(AddressRepository repository.AddressRepository, MaxAddresses int) (service AddressService, err error) {
 	return &AddressServiceImpl{
 		AddressRepository: AddressRepository,
+		MaxAddresses:      MaxAddresses,
 	}, err
 }
 
@@ -24,6 +42,115 @@ func (s *AddressServiceImpl) GetAddressesByUser(keycloakUserID string) ([]model.
 	return s.AddressRepository.GetAddressesByUser(keycloakUserID)
 }
 
+// CreateAddress adds the address to the address book, the first address becomes the default
+// shipping and billing address
 func (s *AddressServiceImpl) CreateAddress(address *model.Address) error {
+	if err := normalizeAddress(address); err != nil {
+		return err
+	}
+
+	count, err := s.AddressRepository.CountAddressesByUser(address.KeycloakUserID)
+	if err != nil {
+		logger.ActError("Error counting the addresses", zap.Error(err))
+		return fmt.Errorf("failed to count addresses")
+	}
+	if s.MaxAddresses > 0 && count >= int64(s.MaxAddresses) {
+		return fmt.Errorf("address book is full, at most %d addresses can be saved", s.MaxAddresses)
+	}
+
+	if count == 0 {
+		address.IsDefaultShipping = true
+		address.IsDefaultBilling = true
+	}
 	return s.AddressRepository.CreateAddress(address)
 }
+
+func (s *AddressServiceImpl) UpdateAddress(keycloakUserID string, addressId uint, req data.CreateAddressRequest) (*model.Address, error) {
+	address, err := s.getUserAddress(keycloakUserID, addressId)
+	if err != nil {
+		return nil, err
+	}
+
+	address.Label = strings.TrimSpace(req.Label)
+	address.Line1 = req.Line1
+	address.Line2 = req.Line2
+	address.City = req.City
+	address.PostalCode = req.PostalCode
+	address.Country = req.Country
+	address.Region = req.Region
+	if err := normalizeAddress(address); err != nil {
+		return nil, err
+	}
+
+	if err := s.AddressRepository.UpdateAddress(address); err != nil {
+		logger.ActError("Error updating the address", zap.Error(err))
+		return nil, fmt.Errorf("failed to update address")
+	}
+	return address, nil
+}
+
+func (s *AddressServiceImpl) DeleteAddress(keycloakUserID string, addressId uint) error {
+	address, err := s.getUserAddress(keycloakUserID, addressId)
+	if err != nil {
+		return err
+	}
+
+	if err := s.AddressRepository.DeleteAddress(address.AddressId); err != nil {
+		logger.ActError("Error deleting the address", zap.Error(err))
+		return fmt.Errorf("failed to delete address")
+	}
+	return nil
+}
+
+// SetDefaultAddress makes the address the default shipping or billing address of the user
+func (s *AddressServiceImpl) SetDefaultAddress(keycloakUserID string, addressId uint, kind string) (*model.Address, error) {
+	var column string
+	switch kind {
+	case AddressDefaultShipping:
+		column = "is_default_shipping"
+	case AddressDefaultBilling:
+		column = "is_default_billing"
+	default:
+		return nil, fmt.Errorf("invalid default address type %q", kind)
+	}
+
+	address, err := s.getUserAddress(keycloakUserID, addressId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.AddressRepository.SetDefaultAddress(keycloakUserID, address.AddressId, column); err != nil {
+		logger.ActError("Error setting the default address", zap.Error(err))
+		return nil, fmt.Errorf("failed to set default address")
+	}
+	return s.AddressRepository.GetAddressById(address.AddressId)
+}
+
+// normalizeAddress validates the address against the rules of its country, the fields
+// are normalized even when the address is invalid
+func normalizeAddress(address *model.Address) error {
+	normalized, err := postal.Validate(postal.Address{
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+		Region:     address.Region,
+	})
+	address.Line1 = normalized.Line1
+	address.Line2 = normalized.Line2
+	address.City = normalized.City
+	address.PostalCode = normalized.PostalCode
+	address.Country = normalized.Country
+	address.Region = normalized.Region
+	return err
+}
+
+// getUserAddress returns an address from the user's address book, the address of another user is not found
+func (s *AddressServiceImpl) getUserAddress(keycloakUserID string, addressId uint) (*model.Address, error) {
+	address, err := s.AddressRepository.GetAddressById(addressId)
+	if err != nil || address.KeycloakUserID != keycloakUserID || address.Unlisted {
+		return nil, fmt.Errorf("address not found")
+	}
+	return address, nil
+}
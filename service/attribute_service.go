@@ -0,0 +1,171 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"shophub-backend/data"
+	"shophub-backend/facet"
+	"shophub-backend/logger"
+	"shophub-backend/model"
+	"shophub-backend/repository"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Attribute codes are used as filter names in the product listing query
+var attributeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+type AttributeService interface {
+	CreateAttribute(categoryId uint, req data.CreateAttributeRequest) (*model.AttributeDefinition, error)
+	GetCategoryAttributes(categoryId uint) ([]model.AttributeDefinition, error)
+	SetProductAttributes(productId uint, req data.SetProductAttributesRequest) ([]model.ProductAttributeValue, error)
+}
+
+type AttributeServiceImpl struct {
+	AttributeRepository repository.AttributeRepository
+	ProductRepository   repository.ProductRepository
+}
+
+func NewAttributeServiceImpl(AttributeRepository repository.AttributeRepository, ProductRepository repository.ProductRepository) (service AttributeService, err error) {
+	return &AttributeServiceImpl{
+		AttributeRepository: AttributeRepository,
+		ProductRepository:   ProductRepository,
+	}, err
+}
+
+func (s *AttributeServiceImpl) CreateAttribute(categoryId uint, req data.CreateAttributeRequest) (*model.AttributeDefinition, error) {
+	if err := s.checkCategory(categoryId); err != nil {
+		return nil, err
+	}
+
+	code := strings.ToLower(strings.TrimSpace(req.Code))
+	if !attributeCodePattern.MatchString(code) {
+		return nil, fmt.Errorf("code must start with a letter and contain only lowercase letters, digits and underscores")
+	}
+	if !facet.IsValidType(req.Type) {
+		return nil, fmt.Errorf("unsupported attribute type %s", req.Type)
+	}
+	if req.Type == facet.TypeEnum && len(req.Values) == 0 {
+		return nil, fmt.Errorf("an ENUM attribute needs at least one value")
+	}
+	if req.Type != facet.TypeEnum && len(req.Values) > 0 {
+		return nil, fmt.Errorf("only ENUM attributes have a list of values")
+	}
+
+	existing, err := s.AttributeRepository.GetAttributesByCategory(categoryId)
+	if err != nil {
+		logger.ActError("Unable to fetch category attributes", zap.Uint("category_id", categoryId), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch category attributes")
+	}
+	for _, attribute := range existing {
+		if attribute.Code == code {
+			return nil, fmt.Errorf("the category already has an attribute with code %s", code)
+		}
+	}
+
+	attribute := &model.AttributeDefinition{
+		CategoryID:   categoryId,
+		Code:         code,
+		Name:         strings.TrimSpace(req.Name),
+		Type:         req.Type,
+		Unit:         strings.TrimSpace(req.Unit),
+		IsFilterable: true,
+		Position:     req.Position,
+	}
+	if req.IsFilterable != nil {
+		attribute.IsFilterable = *req.IsFilterable
+	}
+	seen := map[string]bool{}
+	for _, value := range req.Values {
+		value = strings.TrimSpace(value)
+		if seen[strings.ToLower(value)] {
+			return nil, fmt.Errorf("value %s is listed more than once", value)
+		}
+		seen[strings.ToLower(value)] = true
+		attribute.AllowedValues = append(attribute.AllowedValues, model.AttributeAllowedValue{Value: value, Position: len(attribute.AllowedValues)})
+	}
+
+	if err := s.AttributeRepository.CreateAttribute(attribute); err != nil {
+		logger.ActError("Unable to create attribute", zap.Uint("category_id", categoryId), zap.Error(err))
+		return nil, fmt.Errorf("failed to create attribute")
+	}
+	return attribute, nil
+}
+
+func (s *AttributeServiceImpl) GetCategoryAttributes(categoryId uint) ([]model.AttributeDefinition, error) {
+	if err := s.checkCategory(categoryId); err != nil {
+		return nil, err
+	}
+	attributes, err := s.AttributeRepository.GetAttributesByCategory(categoryId)
+	if err != nil {
+		logger.ActError("Unable to fetch category attributes", zap.Uint("category_id", categoryId), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch category attributes")
+	}
+	return attributes, nil
+}
+
+// SetProductAttributes replaces the attribute values of the product. Every code must be an attribute
+// of the product's category and every value must fit the attribute type.
+func (s *AttributeServiceImpl) SetProductAttributes(productId uint, req data.SetProductAttributesRequest) ([]model.ProductAttributeValue, error) {
+	product, err := s.ProductRepository.GetProductById(productId)
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	attributes, err := s.AttributeRepository.GetAttributesByCategory(product.CategoryID)
+	if err != nil {
+		logger.ActError("Unable to fetch category attributes", zap.Uint("category_id", product.CategoryID), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch category attributes")
+	}
+	byCode := make(map[string]model.AttributeDefinition, len(attributes))
+	for _, attribute := range attributes {
+		byCode[attribute.Code] = attribute
+	}
+
+	values := make([]model.ProductAttributeValue, 0, len(req.Values))
+	for code, value := range req.Values {
+		attribute, ok := byCode[strings.ToLower(strings.TrimSpace(code))]
+		if !ok {
+			return nil, fmt.Errorf("the product's category has no attribute with code %s", code)
+		}
+		normalized, err := facet.Normalize(toFacetAttribute(attribute), value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, model.ProductAttributeValue{
+			ProductID:   productId,
+			AttributeID: attribute.AttributeID,
+			Value:       normalized,
+		})
+	}
+
+	if err := s.AttributeRepository.ReplaceProductAttributes(productId, values); err != nil {
+		logger.ActError("Unable to save product attributes", zap.Uint("product_id", productId), zap.Error(err))
+		return nil, fmt.Errorf("failed to save product attributes")
+	}
+	return s.AttributeRepository.GetProductAttributes(productId)
+}
+
+func (s *AttributeServiceImpl) checkCategory(categoryId uint) error {
+	exists, err := s.AttributeRepository.CategoryExists(categoryId)
+	if err != nil {
+		logger.ActError("Unable to fetch category", zap.Uint("category_id", categoryId), zap.Error(err))
+		return fmt.Errorf("failed to fetch category")
+	}
+	if !exists {
+		return fmt.Errorf("category not found")
+	}
+	return nil
+}
+
+func toFacetAttribute(attribute model.AttributeDefinition) facet.Attribute {
+	return facet.Attribute{
+		Code:          attribute.Code,
+		Name:          attribute.Name,
+		Type:          attribute.Type,
+		Unit:          attribute.Unit,
+		Position:      attribute.Position,
+		AllowedValues: attribute.Values(),
+	}
+}
@@ -2,38 +2,186 @@ package service
 
 import (
 	"fmt"
+	"shophub-backend/data"
 	"shophub-backend/logger"
 	"shophub-backend/model"
+	"shophub-backend/money"
 	"shophub-backend/repository"
+	"shophub-backend/tax"
+	"time"
 )
 
 type CartService interface {
-	GetUserCart(keycloakUserID string) (*model.Cart, error)
-	AddTOCart(keycloakUserID string, productID uint, quantity int) error
+	GetUserCart(keycloakUserID string, currency string, addressId *uint) (*data.CartResponse, error)
+	AddTOCart(keycloakUserID string, productID uint, variantID *uint, quantity int) error
 	ClearCart(keycloakUserID string) error
 	RemoveItemFromCart(itemId uint) error
 	UpdateCartItemQuantity(itemId uint, quantity int) error
+	SelectCartItem(keycloakUserID string, itemId uint, isSelected bool) error
+	SelectAllCartItems(keycloakUserID string, isSelected bool) error
+	AcknowledgePriceChanges(keycloakUserID string) error
+	ApplyCoupon(keycloakUserID string, code string) error
+	RemoveCoupon(keycloakUserID string) error
 }
 
 type CartServiceImpl struct {
 	CartRepository    repository.CartRepository
 	ProductRepository repository.ProductRepository
+	PromotionService  PromotionService
+	CurrencyService   CurrencyService
+	TaxService        TaxService
+	AddressRepository repository.AddressRepository
+	InventoryService  InventoryService
 }
 
-func NewCartServiceImpl(CartRepository repository.CartRepository, ProductRepository repository.ProductRepository) (service CartService, err error) {
+func NewCartServiceImpl(
+	CartRepository repository.CartRepository,
+	ProductRepository repository.ProductRepository,
+	PromotionService PromotionService,
+	CurrencyService CurrencyService,
+	TaxService TaxService,
+	AddressRepository repository.AddressRepository,
+	InventoryService InventoryService,
+) (service CartService, err error) {
 	return &CartServiceImpl{
 		CartRepository:    CartRepository,
 		ProductRepository: ProductRepository,
+		PromotionService:  PromotionService,
+		CurrencyService:   CurrencyService,
+		TaxService:        TaxService,
+		AddressRepository: AddressRepository,
+		InventoryService:  InventoryService,
 	}, err
 }
 
-func (s *CartServiceImpl) GetUserCart(keycloakUserID string) (*model.Cart, error) {
+func (s *CartServiceImpl) GetUserCart(keycloakUserID string, currency string, addressId *uint) (*data.CartResponse, error) {
+	currency, err := s.CurrencyService.ResolveCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
 	// Use GetOrCreateCart to ensure a cart always exists (even if empty)
-	return s.CartRepository.GetOrCreateCart(keycloakUserID)
+	cart, err := s.CartRepository.GetOrCreateCart(keycloakUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &data.CartResponse{
+		CartID:           cart.CartID,
+		KeycloakUserID:   cart.KeycloakUserID,
+		Currency:         currency,
+		Items:            []data.CartItemResponse{},
+		Subtotal:         money.Zero(currency),
+		SelectedSubtotal: money.Zero(currency),
+	}
+
+	// The stock of a variant is kept per variant, both less the stock reserved for unpaid orders
+	var products []model.Product
+	var variantIds []uint
+	for _, item := range cart.Items {
+		if item.VariantID != nil {
+			variantIds = append(variantIds, *item.VariantID)
+		} else {
+			products = append(products, item.Product)
+		}
+	}
+	productStock, err := s.InventoryService.AvailableStock(products)
+	if err != nil {
+		return nil, err
+	}
+	variantStock, err := s.InventoryService.AvailableVariantStock(variantIds)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every line is priced at the current product or variant price, which is what checkout charges
+	now := time.Now()
+	for _, item := range cart.Items {
+		currentPrice, err := s.CurrencyService.Convert(item.CurrentPrice(), currency)
+		if err != nil {
+			return nil, err
+		}
+		availableStock := productStock[item.ProductID]
+		if item.VariantID != nil {
+			availableStock = variantStock[*item.VariantID]
+		}
+		line := data.CartItemResponse{
+			CartItem:          item,
+			CurrentPrice:      currentPrice,
+			CurrentTotalPrice: currentPrice.Mul(item.Quantity),
+			AvailableStock:    availableStock,
+			PriceChanged:      !item.UnitPrice.Equal(item.CurrentPrice()),
+			InsufficientStock: availableStock < item.Quantity,
+			Unavailable:       !item.Product.IsAvailable(now),
+		}
+		response.Items = append(response.Items, line)
+
+		response.ItemCount += item.Quantity
+		response.Subtotal = response.Subtotal.Add(line.CurrentTotalPrice)
+
+		// Selected totals and flags only include the items selected for checkout
+		if !item.IsSelected {
+			continue
+		}
+		response.SelectedItemCount += item.Quantity
+		response.SelectedSubtotal = response.SelectedSubtotal.Add(line.CurrentTotalPrice)
+		if line.PriceChanged {
+			response.HasPriceChanges = true
+		}
+		if line.InsufficientStock {
+			response.HasStockIssues = true
+		}
+		if line.Unavailable {
+			response.HasUnavailableItems = true
+		}
+	}
+
+	// Automatic promotions and the coupon only apply to the selected items.
+	// A coupon that no longer applies stays on the cart, the reason is returned instead of a discount.
+	lines, err := selectedCartLines(cart, s.CurrencyService, currency)
+	if err != nil {
+		return nil, err
+	}
+	pricing, err := s.PromotionService.PriceLines(keycloakUserID, lines, cart.CouponCode)
+	if err != nil {
+		return nil, err
+	}
+	response.CouponCode = cart.CouponCode
+	response.CouponError = pricing.CouponError
+	response.AppliedPromotions = pricing.Applied
+	response.AutomaticDiscount = pricing.AutomaticDiscount
+	response.CouponDiscount = pricing.CouponDiscount
+	response.DiscountAmount = pricing.DiscountAmount
+	for i := range response.Items {
+		response.Items[i].DiscountAmount = pricing.LineDiscounts[response.Items[i].ID]
+	}
+	response.TaxLines = []tax.TaxLine{}
+	response.TaxAmount = money.Zero(currency)
+	response.Total = response.SelectedSubtotal.Sub(response.DiscountAmount)
+
+	// Tax preview for the chosen shipping address
+	if addressId != nil {
+		address, err := s.AddressRepository.GetAddressById(*addressId)
+		if err != nil || address.KeycloakUserID != keycloakUserID {
+			return nil, fmt.Errorf("address not found")
+		}
+		taxResult, err := s.TaxService.CalculateTax(tax.Address{Country: address.Country, Region: address.Region}, lines, pricing)
+		if err != nil {
+			return nil, err
+		}
+		response.AddressID = addressId
+		response.TaxCalculated = true
+		response.TaxLines = taxResult.Lines
+		response.TaxAmount = taxResult.Amount
+		response.Total = response.Total.Add(taxResult.ExclusiveAmount)
+	}
+
+	return response, nil
 }
 
-// AddTOCart adds items to cart with the details of the product
-func (s *CartServiceImpl) AddTOCart(keycloakUserID string, productID uint, quantity int) error {
+// AddTOCart adds items to cart with the details of the product. A product sold as variants
+// is added as the chosen variant, each variant is a separate line.
+func (s *CartServiceImpl) AddTOCart(keycloakUserID string, productID uint, variantID *uint, quantity int) error {
 	// Get or create cart for the user
 	cart, err := s.CartRepository.GetOrCreateCart(keycloakUserID)
 	if err != nil {
@@ -46,17 +194,29 @@ func (s *CartServiceImpl) AddTOCart(keycloakUserID string, productID uint, quant
 		logger.ActError("Product not found")
 		return fmt.Errorf("product not found")
 	}
+	if !product.IsAvailable(time.Now()) {
+		return fmt.Errorf("product is not available")
+	}
+
+	variant, err := chosenVariant(product, variantID)
+	if err != nil {
+		return err
+	}
+	stock, err := s.itemStock(product, variant)
+	if err != nil {
+		return err
+	}
 
 	// Check if item already exists in cart
-	existingItem, err := s.CartRepository.GetCartItemByProductId(cart.CartID, productID)
+	existingItem, err := s.CartRepository.GetCartItemByProductId(cart.CartID, productID, variantID)
 	if err == nil {
 		// Item exists, update quantity instead of creating duplicate
 		newQuantity := existingItem.Quantity + quantity
 
 		// Validate stock availability for the new total quantity
-		if product.ProductStock < newQuantity {
+		if stock < newQuantity {
 			logger.ActError("Not enough stock")
-			return fmt.Errorf("insufficient stock for the product. Only %d item(s) available", product.ProductStock)
+			return fmt.Errorf("insufficient stock for the product. Only %d item(s) available", stock)
 		}
 
 		// Update the existing item's quantity
@@ -71,21 +231,24 @@ func (s *CartServiceImpl) AddTOCart(keycloakUserID string, productID uint, quant
 	}
 
 	// Validate stock availability
-	if product.ProductStock < quantity {
+	if stock < quantity {
 		logger.ActError("Not enough stock")
 		return fmt.Errorf("insufficient stock for the product")
 	}
 
 	//Calculate the total price of the product with the quantity
-	itemPrice := product.ProductPrice * float64(quantity)
+	unitPrice := product.PriceFor(variant)
+	itemPrice := unitPrice.Mul(quantity)
 
 	//Adding product+price+quantity to the cart
 	item := &model.CartItem{
 		CartID:     cart.CartID,
 		ProductID:  productID,
+		VariantID:  variantID,
 		Quantity:   quantity,
-		UnitPrice:  product.ProductPrice,
+		UnitPrice:  unitPrice,
 		TotalPrice: itemPrice,
+		IsSelected: true,
 	}
 
 	//Adding item to the cart
@@ -128,11 +291,137 @@ func (s *CartServiceImpl) UpdateCartItemQuantity(itemId uint, quantity int) erro
 	}
 
 	// Validate stock availability
-	if product.ProductStock < quantity {
+	stock, err := s.itemStock(product, cartItem.Variant)
+	if err != nil {
+		return err
+	}
+	if stock < quantity {
 		logger.ActError("Not enough stock")
-		return fmt.Errorf("insufficient stock for the product. Only %d item(s) available", product.ProductStock)
+		return fmt.Errorf("insufficient stock for the product. Only %d item(s) available", stock)
 	}
 
 	// Update the quantity
 	return s.CartRepository.UpdateCartItemQuantity(itemId, quantity)
 }
+
+// SelectCartItem marks a single cart item as selected or deselected for checkout
+func (s *CartServiceImpl) SelectCartItem(keycloakUserID string, itemId uint, isSelected bool) error {
+	cart, err := s.CartRepository.GetOrCreateCart(keycloakUserID)
+	if err != nil {
+		logger.ActError("Error getting or creating cart")
+		return fmt.Errorf("failed to get or create cart")
+	}
+
+	cartItem, err := s.CartRepository.GetCartItemById(itemId)
+	if err != nil || cartItem.CartID != cart.CartID {
+		logger.ActError("Cart item not found")
+		return fmt.Errorf("cart item not found")
+	}
+
+	return s.CartRepository.UpdateCartItemSelection(itemId, isSelected)
+}
+
+// SelectAllCartItems marks every item in the user's cart as selected or deselected
+func (s *CartServiceImpl) SelectAllCartItems(keycloakUserID string, isSelected bool) error {
+	cart, err := s.CartRepository.GetOrCreateCart(keycloakUserID)
+	if err != nil {
+		logger.ActError("Error getting or creating cart")
+		return fmt.Errorf("failed to get or create cart")
+	}
+
+	return s.CartRepository.UpdateCartSelection(cart.CartID, isSelected)
+}
+
+// AcknowledgePriceChanges accepts the current product prices for every item in the user's cart
+func (s *CartServiceImpl) AcknowledgePriceChanges(keycloakUserID string) error {
+	cart, err := s.CartRepository.GetOrCreateCart(keycloakUserID)
+	if err != nil {
+		logger.ActError("Error getting or creating cart")
+		return fmt.Errorf("failed to get or create cart")
+	}
+
+	for _, item := range cart.Items {
+		if item.UnitPrice.Equal(item.CurrentPrice()) {
+			continue
+		}
+		if err := s.CartRepository.UpdateCartItemPrice(item.ID, item.CurrentPrice()); err != nil {
+			logger.ActError("Error updating cart item price")
+			return fmt.Errorf("failed to update cart item price")
+		}
+	}
+
+	logger.ActInfo("Cart price changes acknowledged")
+	return nil
+}
+
+// ApplyCoupon validates the coupon against the selected cart items and stores it on the cart
+func (s *CartServiceImpl) ApplyCoupon(keycloakUserID string, code string) error {
+	cart, err := s.CartRepository.GetOrCreateCart(keycloakUserID)
+	if err != nil {
+		logger.ActError("Error getting or creating cart")
+		return fmt.Errorf("failed to get or create cart")
+	}
+
+	// The coupon is validated in the default currency against what is left after the automatic promotions
+	lines, err := selectedCartLines(cart, s.CurrencyService, money.DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	pricing, err := s.PromotionService.PriceLines(keycloakUserID, lines, code)
+	if err != nil {
+		return err
+	}
+	if pricing.CouponError != "" {
+		return fmt.Errorf("%s", pricing.CouponError)
+	}
+
+	if err := s.CartRepository.UpdateCartCoupon(cart.CartID, pricing.Coupon.Code); err != nil {
+		logger.ActError("Error applying coupon to the cart")
+		return fmt.Errorf("failed to apply coupon")
+	}
+
+	logger.ActInfo("Coupon applied to the cart")
+	return nil
+}
+
+func (s *CartServiceImpl) RemoveCoupon(keycloakUserID string) error {
+	cart, err := s.CartRepository.GetOrCreateCart(keycloakUserID)
+	if err != nil {
+		logger.ActError("Error getting or creating cart")
+		return fmt.Errorf("failed to get or create cart")
+	}
+
+	return s.CartRepository.UpdateCartCoupon(cart.CartID, "")
+}
+
+// chosenVariant checks the variant chosen for the product, a product sold as variants needs an active variant
+// and a product without variants takes none
+func chosenVariant(product *model.Product, variantID *uint) (*model.ProductVariant, error) {
+	if variantID == nil {
+		if product.HasVariants() {
+			return nil, fmt.Errorf("variant is required for this product")
+		}
+		return nil, nil
+	}
+	variant := product.Variant(*variantID)
+	if variant == nil || !variant.IsActive {
+		return nil, fmt.Errorf("variant not found")
+	}
+	return variant, nil
+}
+
+// itemStock is the stock a cart line can take, the available stock of the product or of its variant
+func (s *CartServiceImpl) itemStock(product *model.Product, variant *model.ProductVariant) (int, error) {
+	if variant == nil {
+		available, err := s.InventoryService.AvailableStock([]model.Product{*product})
+		if err != nil {
+			return 0, err
+		}
+		return available[product.ProductID], nil
+	}
+	available, err := s.InventoryService.AvailableVariantStock([]uint{variant.VariantID})
+	if err != nil {
+		return 0, err
+	}
+	return available[variant.VariantID], nil
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"shophub-backend/catalog"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/model"
+	"shophub-backend/money"
+	"shophub-backend/repository"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// ProductImportReference marks the stock movements of CSV imports in the ledger
+	ProductImportReference = "csv import"
+
+	productExportBatchSize = 500
+)
+
+type CatalogService interface {
+	ImportProducts(r io.Reader, dryRun bool, actor string) (*data.ProductImportReport, error)
+	ExportProducts(w io.Writer) error
+}
+
+// CatalogServiceImpl imports and exports the product catalogue as CSV, see the catalog package for the columns
+type CatalogServiceImpl struct {
+	ProductRepository   repository.ProductRepository
+	CategoryRepository  repository.CategoryRepository
+	WarehouseRepository repository.WarehouseRepository
+	CurrencyService     CurrencyService
+}
+
+func NewCatalogServiceImpl(ProductRepository repository.ProductRepository, CategoryRepository repository.CategoryRepository, WarehouseRepository repository.WarehouseRepository, CurrencyService CurrencyService) (service CatalogService, err error) {
+	return &CatalogServiceImpl{
+		ProductRepository:   ProductRepository,
+		CategoryRepository:  CategoryRepository,
+		WarehouseRepository: WarehouseRepository,
+		CurrencyService:     CurrencyService,
+	}, err
+}
+
+// importedProduct is a valid row with the product it creates or updates
+type importedProduct struct {
+	row        catalog.Row
+	product    *model.Product
+	isNew      bool
+	stockDelta int
+}
+
+// ImportProducts creates or updates a product for every row, matched by slug. All rows are validated
+// first and nothing is written when any row is invalid or on a dry run. The stock column sets the total
+// stock of the product, the difference is booked as an IMPORT movement in the first active warehouse.
+func (s *CatalogServiceImpl) ImportProducts(r io.Reader, dryRun bool, actor string) (*data.ProductImportReport, error) {
+	rows, rowErrors, err := catalog.Read(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv: %v", err)
+	}
+	report := &data.ProductImportReport{
+		DryRun: dryRun,
+		Rows:   len(rows) + len(rowErrors),
+		Errors: rowErrors,
+	}
+
+	categories, err := s.CategoryRepository.GetAllCategories()
+	if err != nil {
+		logger.ActError("Unable to fetch categories", zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch categories")
+	}
+	categoryIds := make(map[string]uint, len(categories))
+	for _, category := range categories {
+		categoryIds[category.CategorySlug] = category.CategoryID
+	}
+	warehouse, err := s.importWarehouse()
+	if err != nil {
+		return nil, err
+	}
+
+	var imports []importedProduct
+	lines := map[string]int{}
+	for _, row := range rows {
+		imported, err := s.prepareRow(row, categoryIds, warehouse, lines)
+		if err != nil {
+			report.Errors = append(report.Errors, catalog.RowError{Line: row.Line, Slug: row.Slug, Error: err.Error()})
+			continue
+		}
+		imports = append(imports, *imported)
+	}
+
+	if len(report.Errors) > 0 {
+		sort.SliceStable(report.Errors, func(i, j int) bool {
+			return report.Errors[i].Line < report.Errors[j].Line
+		})
+		return report, nil
+	}
+	if dryRun {
+		for _, imported := range imports {
+			countImported(report, imported)
+		}
+		return report, nil
+	}
+
+	report.Applied = true
+	for _, imported := range imports {
+		if err := s.saveProduct(imported, warehouse, actor); err != nil {
+			logger.ActError("Unable to import product", zap.String("product_slug", imported.row.Slug), zap.Error(err))
+			report.Errors = append(report.Errors, catalog.RowError{Line: imported.row.Line, Slug: imported.row.Slug, Error: err.Error()})
+			continue
+		}
+		countImported(report, imported)
+	}
+	logger.ActInfo("Products imported", zap.Int("created", report.Created), zap.Int("updated", report.Updated), zap.Int("failed", len(report.Errors)))
+	return report, nil
+}
+
+// ExportProducts writes every product as CSV in the format ImportProducts reads
+func (s *CatalogServiceImpl) ExportProducts(w io.Writer) error {
+	categories, err := s.CategoryRepository.GetAllCategories()
+	if err != nil {
+		logger.ActError("Unable to fetch categories", zap.Error(err))
+		return fmt.Errorf("failed to fetch categories")
+	}
+	categorySlugs := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		categorySlugs[category.CategoryID] = category.CategorySlug
+	}
+
+	writer := catalog.NewWriter(w)
+	if err := writer.WriteHeader(); err != nil {
+		return fmt.Errorf("failed to write export: %v", err)
+	}
+	err = s.ProductRepository.EachProductBatch(productExportBatchSize, func(products []model.Product) error {
+		for i := range products {
+			product := &products[i]
+			if err := writer.Write(catalog.Row{
+				Slug:             product.ProductSlug,
+				Name:             product.ProductName,
+				Price:            product.ProductPrice,
+				Currency:         product.Currency,
+				CategorySlug:     categorySlugs[product.CategoryID],
+				Stock:            &product.ProductStock,
+				ImageURL:         &product.ImgUrlMain,
+				WeightKg:         &product.WeightKg,
+				LengthCm:         &product.LengthCm,
+				WidthCm:          &product.WidthCm,
+				HeightCm:         &product.HeightCm,
+				ReorderThreshold: product.ReorderThreshold,
+			}); err != nil {
+				return err
+			}
+		}
+		// Sending every batch as it is written
+		return writer.Flush()
+	})
+	if err != nil {
+		logger.ActError("Unable to export products", zap.Error(err))
+		return fmt.Errorf("failed to export products: %v", err)
+	}
+	return writer.Flush()
+}
+
+// prepareRow checks the row and applies it to the product with its slug, or to a new product
+func (s *CatalogServiceImpl) prepareRow(row catalog.Row, categoryIds map[string]uint, warehouse *model.Warehouse, lines map[string]int) (*importedProduct, error) {
+	if line, ok := lines[row.Slug]; ok {
+		return nil, fmt.Errorf("slug %s is also on line %d", row.Slug, line)
+	}
+	lines[row.Slug] = row.Line
+
+	categoryId, ok := categoryIds[row.CategorySlug]
+	if !ok {
+		return nil, fmt.Errorf("category %s does not exist", row.CategorySlug)
+	}
+
+	imported := &importedProduct{row: row}
+	product, err := s.ProductRepository.GetProductBySlug(row.Slug)
+	if err != nil {
+		imported.isNew = true
+		product = &model.Product{ProductSlug: row.Slug, Currency: money.DefaultCurrency}
+	}
+	imported.product = product
+
+	if row.Currency != "" {
+		code, err := s.CurrencyService.ResolveCurrency(row.Currency)
+		if err != nil {
+			return nil, err
+		}
+		product.Currency = code
+	}
+	product.ProductName = row.Name
+	product.CategoryID = categoryId
+	product.ProductPrice = row.Price.WithCurrency(product.Currency)
+	if row.ImageURL != nil {
+		product.ImgUrlMain = *row.ImageURL
+	}
+	if row.WeightKg != nil {
+		product.WeightKg = *row.WeightKg
+	}
+	if row.LengthCm != nil {
+		product.LengthCm = *row.LengthCm
+	}
+	if row.WidthCm != nil {
+		product.WidthCm = *row.WidthCm
+	}
+	if row.HeightCm != nil {
+		product.HeightCm = *row.HeightCm
+	}
+	if row.ReorderThreshold != nil {
+		product.ReorderThreshold = row.ReorderThreshold
+	}
+
+	if row.Stock != nil {
+		if product.HasVariants() {
+			return nil, fmt.Errorf("the product is sold as variants, their stock is set per variant")
+		}
+		imported.stockDelta = *row.Stock - product.ProductStock
+		if imported.stockDelta != 0 && warehouse == nil {
+			return nil, fmt.Errorf("there is no active warehouse to put the stock in")
+		}
+	}
+	return imported, nil
+}
+
+// saveProduct writes the product and books the change of its stock
+func (s *CatalogServiceImpl) saveProduct(imported importedProduct, warehouse *model.Warehouse, actor string) error {
+	product := imported.product
+	if imported.isNew {
+		if err := s.ProductRepository.CreateProduct(product); err != nil {
+			return fmt.Errorf("failed to create product: %v", err)
+		}
+	} else if err := s.ProductRepository.UpdateProduct(product); err != nil {
+		return fmt.Errorf("failed to update product: %v", err)
+	}
+
+	if imported.stockDelta == 0 {
+		return nil
+	}
+	_, err := s.WarehouseRepository.AdjustStock(model.StockMovement{
+		ProductID:   product.ProductID,
+		WarehouseID: &warehouse.WarehouseID,
+		Quantity:    imported.stockDelta,
+		Reason:      model.StockMovementImport,
+		Actor:       actor,
+		Reference:   ProductImportReference,
+	})
+	if errors.Is(err, repository.ErrStockBelowReserved) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set stock: %v", err)
+	}
+	return nil
+}
+
+// importWarehouse is the first active warehouse, imported stock is booked there
+func (s *CatalogServiceImpl) importWarehouse() (*model.Warehouse, error) {
+	warehouses, err := s.WarehouseRepository.GetAllWarehouses()
+	if err != nil {
+		logger.ActError("Unable to fetch warehouses", zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch warehouses")
+	}
+	for i := range warehouses {
+		if warehouses[i].IsActive {
+			return &warehouses[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func countImported(report *data.ProductImportReport, imported importedProduct) {
+	if imported.isNew {
+		report.Created++
+	} else {
+		report.Updated++
+	}
+}
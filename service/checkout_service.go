@@ -5,14 +5,20 @@ import (
 	"shophub-backend/data"
 	"shophub-backend/logger"
 	"shophub-backend/model"
+	"shophub-backend/money"
+	"shophub-backend/postal"
+	"shophub-backend/promotion"
 	"shophub-backend/repository"
+	"shophub-backend/shipping"
+	"shophub-backend/tax"
 	"time"
 
 	"go.uber.org/zap"
 )
 
 type CheckoutService interface {
-	PlaceOrder(keycloakUserID string, paymentMethod string, address data.CreateAddressRequest) (*model.Order, error)
+	PlaceOrder(keycloakUserID string, req data.PlaceOrderRequest, currency string) (*model.Order, error)
+	QuoteOrder(keycloakUserID string, req data.PlaceOrderRequest, currency string) (*data.CheckoutQuoteResponse, error)
 }
 
 type CheckoutServiceImpl struct {
@@ -22,6 +28,12 @@ type CheckoutServiceImpl struct {
 	PaymentRepository repository.PaymentRepository
 	AddressRepository repository.AddressRepository
 	UserRepository    repository.UserRepository
+	AddressService    AddressService
+	PromotionService  PromotionService
+	CurrencyService   CurrencyService
+	TaxService        TaxService
+	ShippingService   ShippingService
+	InventoryService  InventoryService
 }
 
 func NewCheckoutServiceImpl(
@@ -31,6 +43,12 @@ func NewCheckoutServiceImpl(
 	PaymentRepository repository.PaymentRepository,
 	AddressRepository repository.AddressRepository,
 	UserRepository repository.UserRepository,
+	AddressService AddressService,
+	PromotionService PromotionService,
+	CurrencyService CurrencyService,
+	TaxService TaxService,
+	ShippingService ShippingService,
+	InventoryService InventoryService,
 ) (CheckoutService, error) {
 	return &CheckoutServiceImpl{
 		OrderRepository:   OrderRepository,
@@ -39,31 +57,41 @@ func NewCheckoutServiceImpl(
 		PaymentRepository: PaymentRepository,
 		AddressRepository: AddressRepository,
 		UserRepository:    UserRepository,
+		AddressService:    AddressService,
+		PromotionService:  PromotionService,
+		CurrencyService:   CurrencyService,
+		TaxService:        TaxService,
+		ShippingService:   ShippingService,
+		InventoryService:  InventoryService,
 	}, nil
 }
 
-func (s *CheckoutServiceImpl) PlaceOrder(keycloakUserID string, paymentMethod string, addressReq data.CreateAddressRequest) (*model.Order, error) {
-	// Get user's cart
-	cart, err := s.CartRepository.GetUserCart(keycloakUserID)
-	if err != nil || cart == nil {
-		logger.ActError("Cart not found")
-		return nil, errors.New("cart not found")
+func (s *CheckoutServiceImpl) PlaceOrder(keycloakUserID string, req data.PlaceOrderRequest, currency string) (*model.Order, error) {
+	quote, err := s.buildQuote(keycloakUserID, req, currency)
+	if err != nil {
+		return nil, err
 	}
-
-	if len(cart.Items) == 0 {
-		logger.ActError("cart is empty")
-		return nil, errors.New("cart is empty")
+	if len(quote.issues) > 0 {
+		return nil, quote.issues[0]
 	}
+	currency = quote.currency
+	coupon := quote.pricing.Coupon
 
-	// Validate stock for all items before processing any orders
-	for _, item := range cart.Items {
-		product, err := s.ProductRepository.GetProductById(item.ProductID)
+	// Billing goes to the shipping address unless another one is given
+	billingAddress := quote.shippingAddress
+	if req.BillingAddressID != nil || req.BillingAddress != nil {
+		billingAddress, err = s.resolveAddress(keycloakUserID, req.BillingAddressID, req.BillingAddress)
 		if err != nil {
-			return nil, err
+			return nil, errors.New("billing " + err.Error())
 		}
-
-		if product.ProductStock < item.Quantity {
-			return nil, errors.New("Insufficient stock for " + product.ProductName)
+		if billingAddress.AddressId == 0 {
+			if err := normalizeAddress(billingAddress); err != nil {
+				var validationErr *postal.ValidationError
+				if errors.As(err, &validationErr) {
+					return nil, validationErr.WithPrefix("billing_address")
+				}
+				return nil, err
+			}
 		}
 	}
 
@@ -75,52 +103,125 @@ func (s *CheckoutServiceImpl) PlaceOrder(keycloakUserID string, paymentMethod st
 		return nil, errors.New("failed to ensure user exists: " + err.Error())
 	}
 
-	// Create address for the order
-	address := &model.Address{
-		KeycloakUserID: keycloakUserID,
-		Line1:          addressReq.Line1,
-		Line2:          addressReq.Line2,
-		City:           addressReq.City,
-		PostalCode:     addressReq.PostalCode,
-		Country:        addressReq.Country,
-	}
-
-	if err := s.AddressRepository.CreateAddress(address); err != nil {
-		logger.ActError("Unable to create address for order", zap.Error(err))
-		return nil, errors.New("failed to create address: " + err.Error())
-	}
+	// New addresses are created for the order, and only listed in the address book when asked to.
+	// Listed addresses go through the address book, so its limit and default address apply.
+	for _, address := range []*model.Address{quote.shippingAddress, billingAddress} {
+		if address.AddressId != 0 {
+			continue
+		}
+		address.Unlisted = !req.SaveAddress
+		if req.SaveAddress {
+			if err := s.AddressService.CreateAddress(address); err != nil {
+				logger.ActError("Unable to save address for order", zap.Error(err))
+				return nil, err
+			}
+		} else if err := s.AddressRepository.CreateAddress(address); err != nil {
+			logger.ActError("Unable to create address for order", zap.Error(err))
+			return nil, errors.New("failed to create address: " + err.Error())
+		}
 
-	// Verify address ID was populated
-	if address.AddressId == 0 {
-		logger.ActError("Address ID not populated after creation")
-		return nil, errors.New("address ID not populated after creation")
+		// Verify address ID was populated
+		if address.AddressId == 0 {
+			logger.ActError("Address ID not populated after creation")
+			return nil, errors.New("address ID not populated after creation")
+		}
 	}
 
 	// Normalize payment method (convert to uppercase for consistency)
-	normalizedPaymentMethod := paymentMethod
+	normalizedPaymentMethod := req.PaymentMethod
 	if normalizedPaymentMethod == "Cash on Delivery" || normalizedPaymentMethod == "cash on delivery" {
 		normalizedPaymentMethod = "CASH"
 	} else if normalizedPaymentMethod == "Credit/Debit Card" || normalizedPaymentMethod == "credit/debit card" {
 		normalizedPaymentMethod = "CARD"
 	}
 
-	// Create one order per product
-	var userOrder *model.Order
-	for _, item := range cart.Items {
-		product, err := s.ProductRepository.GetProductById(item.ProductID)
+	// Hold the stock of every line before any order is created. Card payments keep the stock
+	// reserved until they succeed, fail or expire, cash orders take it off the stock right away.
+	// When the checkout fails part way, the orders and payments created so far are cancelled
+	// together with the stock and coupon use they hold.
+	reservations := make(map[uint][]model.InventoryReservation, len(quote.lines))
+	var redemption *model.CouponRedemption
+	var paymentIds, orderIds []uint
+	placed := false
+	defer func() {
+		if placed {
+			return
+		}
+		for _, orderId := range orderIds {
+			if err := s.OrderRepository.UpdateOrderStatus(orderId, OrderStatusCancelled); err != nil {
+				logger.ActError("Unable to cancel order of failed checkout", zap.Uint("order_id", orderId), zap.Error(err))
+			}
+		}
+		for _, paymentId := range paymentIds {
+			if err := s.PaymentRepository.UpdatePaymentStatusById(paymentId, PaymentStatusFailed); err != nil {
+				logger.ActError("Unable to fail payment of failed checkout", zap.Uint("payment_id", paymentId), zap.Error(err))
+			}
+		}
+		if redemption != nil {
+			if err := s.PromotionService.CancelRedemption(redemption.RedemptionID); err != nil {
+				logger.ActError("Unable to cancel coupon redemption", zap.Error(err))
+			}
+		}
+		for _, lineReservations := range reservations {
+			for _, reservation := range lineReservations {
+				if err := s.InventoryService.ReleaseReservation(reservation.ReservationID, ReleaseReasonCheckoutFailed); err != nil {
+					logger.ActError("Unable to release stock reservation", zap.Error(err))
+				}
+			}
+		}
+	}()
+	for _, line := range quote.lines {
+		lineReservations, err := s.InventoryService.ReserveStock(keycloakUserID, line.product.ProductID, line.item.StockVariantID(), line.item.Quantity, quote.shippingAddress.Country)
 		if err != nil {
+			if errors.Is(err, repository.ErrInsufficientStock) {
+				return nil, errors.New("Insufficient stock for " + line.product.ProductName)
+			}
 			return nil, err
 		}
+		reservations[line.item.ID] = lineReservations
+	}
+
+	// Count the coupon use against its limits before any order is created, it is given back if the checkout fails
+	if coupon != nil {
+		redemption, err = s.PromotionService.RedeemCoupon(coupon, keycloakUserID, quote.pricing.CouponDiscount)
+		if err != nil {
+			return nil, errors.New("coupon " + coupon.Code + " can no longer be applied: " + err.Error())
+		}
+	}
+
+	// Create one order per product
+	var userOrder *model.Order
+	var orderedItemIds []uint
+	for _, line := range quote.lines {
+		item := line.item
+		product := line.product
 
-		// Calculate price for this item
-		itemTotalPrice := float64(item.Quantity) * product.ProductPrice
+		var taxLines []model.OrderTaxLine
+		for _, taxLine := range line.taxLines {
+			taxLines = append(taxLines, model.OrderTaxLine{
+				TaxRateID:     taxLine.TaxRateID,
+				Name:          taxLine.Name,
+				Country:       taxLine.Country,
+				Region:        taxLine.Region,
+				Rate:          taxLine.Rate,
+				Inclusive:     taxLine.Inclusive,
+				TaxableAmount: taxLine.TaxableAmount,
+				Amount:        taxLine.Amount,
+				Currency:      currency,
+			})
+		}
+		couponCode := ""
+		if coupon != nil {
+			couponCode = coupon.Code
+		}
 
 		// Create payment without OrderId (will be updated after order creation)
 		payment := &model.Payment{
 			OrderId:        nil, // Set to nil initially, will be updated after order creation
 			KeycloakUserID: keycloakUserID,
 			PaymentMethod:  normalizedPaymentMethod,
-			PaymentAmount:  itemTotalPrice,
+			PaymentAmount:  line.total,
+			Currency:       currency,
 			Status:         "UNPAID",
 		}
 
@@ -129,24 +230,41 @@ func (s *CheckoutServiceImpl) PlaceOrder(keycloakUserID string, paymentMethod st
 			logger.ActError("Unable to create payment for order", zap.Error(err))
 			return nil, errors.New("failed to create payment: " + err.Error())
 		}
+		paymentIds = append(paymentIds, payment.PaymentId)
 
 		// Create order with all required fields including address
 		order := &model.Order{
-			KeycloakUserID: keycloakUserID,
-			ProductId:      item.ProductID,
-			PaymentId:      payment.PaymentId,
-			ProductPrice:   product.ProductPrice,
-			Quantity:       uint(item.Quantity),
-			TotalPrice:     itemTotalPrice,
-			AddressId:      &address.AddressId,
-			OrderStatus:    "Pending",
-			CreatedAt:      time.Now(),
+			KeycloakUserID:   keycloakUserID,
+			ProductId:        item.ProductID,
+			PaymentId:        payment.PaymentId,
+			ProductPrice:     line.unitPrice,
+			Quantity:         uint(item.Quantity),
+			DiscountAmount:   line.discount,
+			CouponCode:       couponCode,
+			TaxAmount:        line.tax,
+			ShippingCost:     line.shippingCost,
+			TotalPrice:       line.total,
+			Currency:         currency,
+			ExchangeRate:     line.exchangeRate,
+			TaxLines:         taxLines,
+			AddressId:        &quote.shippingAddress.AddressId,
+			BillingAddressId: &billingAddress.AddressId,
+			ShippingAddress:  model.NewOrderAddress(quote.shippingAddress),
+			BillingAddress:   model.NewOrderAddress(billingAddress),
+			OrderStatus:      "Pending",
+			CreatedAt:        time.Now(),
+		}
+		order.SetProduct(product, line.variant)
+		if quote.shippingOption != nil {
+			order.ShippingMethodID = &quote.shippingOption.ShippingMethodID
+			order.ShippingMethodName = quote.shippingOption.Name
 		}
 
 		if err := s.OrderRepository.CreateOrder(order); err != nil {
 			logger.ActError("Unable to create the order", zap.Error(err))
 			return nil, errors.New("failed to create order: " + err.Error())
 		}
+		orderIds = append(orderIds, order.OrderId)
 
 		// Update payment with the actual OrderId
 		if err := s.PaymentRepository.UpdatePaymentOrderId(payment.PaymentId, order.OrderId); err != nil {
@@ -154,25 +272,360 @@ func (s *CheckoutServiceImpl) PlaceOrder(keycloakUserID string, paymentMethod st
 			return nil, errors.New("failed to update payment order ID: " + err.Error())
 		}
 
-		// IMPORTANT: Reduce stock after order is successfully created
-		// This ensures stock is only reduced when the order is confirmed
-		product.ProductStock -= item.Quantity
-		if err := s.ProductRepository.UpdateProduct(product); err != nil {
-			logger.ActError("Unable to update product stock", zap.Error(err))
-			return nil, errors.New("failed to update product stock: " + err.Error())
+		// The reservation now belongs to the order, the stock is only reduced once the order is paid
+		for _, reservation := range reservations[item.ID] {
+			if err := s.InventoryService.AttachOrder(reservation.ReservationID, order.OrderId); err != nil {
+				return nil, err
+			}
+		}
+		if normalizedPaymentMethod == "CASH" {
+			if err := s.InventoryService.CommitOrder(order.OrderId); err != nil {
+				logger.ActError("Unable to commit stock for cash order", zap.Error(err))
+				return nil, errors.New("failed to update product stock: " + err.Error())
+			}
 		}
 
-		// Store first order to return
+		orderedItemIds = append(orderedItemIds, item.ID)
+
+		// Store first order to return, the redemption is recorded once per checkout against it
 		if userOrder == nil {
 			userOrder = order
+			if redemption != nil {
+				if err := s.PromotionService.AttachRedemptionOrder(redemption.RedemptionID, order.OrderId); err != nil {
+					return nil, err
+				}
+			}
 		}
 	}
+	placed = true
 
-	// Clear cart after all orders are created
-	if err := s.CartRepository.ClearCart(keycloakUserID); err != nil {
-		logger.ActError("Unable to clear cart after order creation", zap.Error(err))
+	if coupon != nil {
+		if err := s.CartRepository.UpdateCartCoupon(quote.cart.CartID, ""); err != nil {
+			logger.ActError("Unable to remove coupon from cart", zap.Error(err))
+		}
+	}
+
+	// Remove the ordered items from the cart after all orders are created
+	if err := s.CartRepository.RemoveCartItems(orderedItemIds); err != nil {
+		logger.ActError("Unable to remove ordered items from cart", zap.Error(err))
 		return nil, errors.New("failed to clear cart: " + err.Error())
 	}
 
 	return userOrder, nil
 }
+
+// QuoteOrder prices the checkout exactly as PlaceOrder would charge it, without creating anything.
+// Problems that would stop the order from being placed are returned as issues instead of errors.
+func (s *CheckoutServiceImpl) QuoteOrder(keycloakUserID string, req data.PlaceOrderRequest, currency string) (*data.CheckoutQuoteResponse, error) {
+	quote, err := s.buildQuote(keycloakUserID, req, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &data.CheckoutQuoteResponse{
+		Currency:          quote.currency,
+		Lines:             []data.CheckoutQuoteLine{},
+		Subtotal:          quote.pricing.Subtotal,
+		AppliedPromotions: quote.pricing.Applied,
+		AutomaticDiscount: quote.pricing.AutomaticDiscount,
+		CouponDiscount:    quote.pricing.CouponDiscount,
+		DiscountAmount:    quote.pricing.DiscountAmount,
+		ShippingMethod:    quote.shippingOption,
+		ShippingOptions:   quote.shippingOptions,
+		ShippingCost:      money.Zero(quote.currency),
+		TaxLines:          quote.taxResult.Lines,
+		TaxAmount:         quote.taxResult.Amount,
+		IncludedTaxAmount: quote.taxResult.Amount.Sub(quote.taxResult.ExclusiveAmount),
+		GrandTotal:        money.Zero(quote.currency),
+		CanPlaceOrder:     len(quote.issues) == 0,
+		Issues:            []string{},
+	}
+	if quote.pricing.Coupon != nil {
+		response.CouponCode = quote.pricing.Coupon.Code
+	}
+	if quote.shippingOption != nil {
+		response.ShippingCost = quote.shippingOption.Cost
+	}
+
+	for _, line := range quote.lines {
+		quoteLine := data.CheckoutQuoteLine{
+			CartItemID:     line.item.ID,
+			ProductID:      line.item.ProductID,
+			ProductName:    line.product.ProductName,
+			Quantity:       line.item.Quantity,
+			UnitPrice:      line.unitPrice,
+			Subtotal:       line.unitPrice.Mul(line.item.Quantity),
+			DiscountAmount: line.discount,
+			TaxAmount:      line.tax,
+			ShippingCost:   line.shippingCost,
+			Total:          line.total,
+		}
+		if line.variant != nil {
+			quoteLine.VariantID = &line.variant.VariantID
+			quoteLine.VariantName = line.variant.Name()
+		}
+		response.Lines = append(response.Lines, quoteLine)
+		response.GrandTotal = response.GrandTotal.Add(line.total)
+	}
+	for _, issue := range quote.issues {
+		response.Issues = append(response.Issues, issue.Error())
+	}
+
+	return response, nil
+}
+
+// checkoutQuote is a fully priced checkout. PlaceOrder and QuoteOrder both build it with
+// buildQuote, so a quote always matches what the order is charged.
+type checkoutQuote struct {
+	currency        string
+	cart            *model.Cart
+	shippingAddress *model.Address
+	pricing         *promotion.Pricing
+	taxResult       *tax.Result
+	shippingOptions []shipping.Option
+	shippingOption  *shipping.Option
+	lines           []quoteLine
+	// issues stop the order from being placed, in the order they are reported
+	issues []error
+}
+
+// quoteLine is a selected cart item priced in the checkout currency, it becomes one order
+type quoteLine struct {
+	item         model.CartItem
+	product      *model.Product
+	variant      *model.ProductVariant
+	exchangeRate float64
+	unitPrice    money.Money
+	discount     money.Money
+	tax          money.Money
+	taxLines     []tax.TaxLine
+	shippingCost money.Money
+	total        money.Money
+}
+
+func (s *CheckoutServiceImpl) buildQuote(keycloakUserID string, req data.PlaceOrderRequest, currency string) (*checkoutQuote, error) {
+	// The order is charged in the requested currency
+	currency, err := s.CurrencyService.ResolveCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get user's cart
+	cart, err := s.CartRepository.GetUserCart(keycloakUserID)
+	if err != nil || cart == nil {
+		logger.ActError("Cart not found")
+		return nil, errors.New("cart not found")
+	}
+
+	if len(cart.Items) == 0 {
+		logger.ActError("cart is empty")
+		return nil, errors.New("cart is empty")
+	}
+
+	// Only the selected items are checked out, the rest stay in the cart
+	var selectedItems []model.CartItem
+	for _, item := range cart.Items {
+		if item.IsSelected {
+			selectedItems = append(selectedItems, item)
+		}
+	}
+
+	if len(selectedItems) == 0 {
+		logger.ActError("no items selected for checkout")
+		return nil, errors.New("no items selected for checkout")
+	}
+
+	shippingAddress, err := s.resolveAddress(keycloakUserID, req.AddressID, req.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &checkoutQuote{currency: currency, cart: cart, shippingAddress: shippingAddress}
+
+	// A new address must be complete to place the order, a quote only needs its country
+	if shippingAddress.AddressId == 0 {
+		if err := normalizeAddress(shippingAddress); err != nil {
+			quote.issues = append(quote.issues, err)
+		}
+	}
+
+	// Validate stock for all items before processing any orders, stock reserved for unpaid orders is not available.
+	// The stock of a variant is checked per variant.
+	products := make(map[uint]*model.Product, len(selectedItems))
+	variants := make(map[uint]*model.ProductVariant, len(selectedItems))
+	productList := make([]model.Product, 0, len(selectedItems))
+	var variantIds []uint
+	now := time.Now()
+	for _, item := range selectedItems {
+		// Archived, unpublished and deleted products stay in the cart but cannot be ordered,
+		// the cart's copy of the product is enough to quote them
+		if !item.Product.IsAvailable(now) {
+			quote.issues = append(quote.issues, errors.New(item.Product.ProductName+" is no longer available, please remove it from the cart"))
+			product := item.Product
+			products[item.ID] = &product
+			productList = append(productList, product)
+			if item.Variant != nil {
+				variants[item.ID] = item.Variant
+				variantIds = append(variantIds, item.Variant.VariantID)
+			}
+			continue
+		}
+
+		product, err := s.ProductRepository.GetProductById(item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		products[item.ID] = product
+		productList = append(productList, *product)
+
+		variant, err := chosenVariant(product, item.VariantID)
+		if err != nil {
+			quote.issues = append(quote.issues, errors.New(product.ProductName+": "+err.Error()))
+		}
+		if variant != nil {
+			variants[item.ID] = variant
+			variantIds = append(variantIds, variant.VariantID)
+		}
+	}
+	available, err := s.InventoryService.AvailableStock(productList)
+	if err != nil {
+		return nil, err
+	}
+	variantAvailable, err := s.InventoryService.AvailableVariantStock(variantIds)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range selectedItems {
+		product := products[item.ID]
+		variant := variants[item.ID]
+
+		stock := available[product.ProductID]
+		name := product.ProductName
+		if variant != nil {
+			stock = variantAvailable[variant.VariantID]
+			name = product.ProductName + " (" + variant.Name() + ")"
+		}
+		if stock < item.Quantity {
+			quote.issues = append(quote.issues, errors.New("Insufficient stock for "+name))
+		}
+
+		// The user must acknowledge price changes before being charged a different price
+		if !item.UnitPrice.Equal(product.PriceFor(variant)) {
+			logger.ActError("Cart price changed", zap.Uint("product_id", item.ProductID))
+			quote.issues = append(quote.issues, errors.New("cart prices have changed, please review and acknowledge the price changes"))
+		}
+	}
+
+	// Apply the automatic promotions and the coupon, the same way the cart is priced
+	lines, err := selectedCartLines(cart, s.CurrencyService, currency)
+	if err != nil {
+		return nil, err
+	}
+	pricing, err := s.PromotionService.PriceLines(keycloakUserID, lines, cart.CouponCode)
+	if err != nil {
+		return nil, err
+	}
+	if pricing.CouponError != "" {
+		logger.ActError("Applied coupon is no longer valid", zap.String("reason", pricing.CouponError))
+		quote.issues = append(quote.issues, errors.New("coupon "+cart.CouponCode+" can no longer be applied: "+pricing.CouponError))
+	}
+	quote.pricing = pricing
+
+	// Tax depends on the shipping address and is charged on the discounted prices
+	quote.taxResult, err = s.TaxService.CalculateTax(tax.Address{Country: shippingAddress.Country, Region: shippingAddress.Region}, lines, pricing)
+	if err != nil {
+		return nil, err
+	}
+
+	// A shipping method is required once shipping is configured for the country
+	quote.shippingOptions, err = s.ShippingService.ShippingOptions(shippingAddress.Country, cartParcel(cart, pricing))
+	if err != nil {
+		return nil, err
+	}
+	for i := range quote.shippingOptions {
+		if quote.shippingOptions[i].ShippingMethodID == req.ShippingMethodID {
+			quote.shippingOption = &quote.shippingOptions[i]
+		}
+	}
+	if req.ShippingMethodID == 0 && len(quote.shippingOptions) > 0 {
+		quote.issues = append(quote.issues, errors.New("shipping method is required"))
+	}
+	if req.ShippingMethodID != 0 && quote.shippingOption == nil {
+		quote.issues = append(quote.issues, errors.New("shipping method is not available for this address"))
+	}
+
+	// The shipping cost is split over the orders by line value
+	shippingShares := map[uint]money.Money{}
+	if quote.shippingOption != nil {
+		weights := make([]money.Money, len(lines))
+		for i, line := range lines {
+			weights[i] = line.Total()
+		}
+		for i, share := range quote.shippingOption.Cost.Allocate(weights) {
+			shippingShares[lines[i].ItemID] = share
+		}
+	}
+
+	for _, item := range selectedItems {
+		product := products[item.ID]
+		variant := variants[item.ID]
+
+		// Calculate price for this item in the order currency, less its share of the promotion and coupon discounts
+		exchangeRate, err := s.CurrencyService.Rate(product.Currency, currency)
+		if err != nil {
+			return nil, err
+		}
+		unitPrice, err := s.CurrencyService.Convert(product.PriceFor(variant), currency)
+		if err != nil {
+			return nil, err
+		}
+
+		line := quoteLine{
+			item:         item,
+			product:      product,
+			variant:      variant,
+			exchangeRate: exchangeRate,
+			unitPrice:    unitPrice,
+			discount:     money.Zero(currency).Add(pricing.LineDiscounts[item.ID]),
+			tax:          money.Zero(currency),
+			taxLines:     quote.taxResult.LinesFor(item.ID),
+			shippingCost: money.Zero(currency).Add(shippingShares[item.ID]),
+		}
+		for _, taxLine := range line.taxLines {
+			line.tax = line.tax.Add(taxLine.Amount)
+		}
+		line.total = unitPrice.Mul(item.Quantity).
+			Sub(line.discount).
+			Add(quote.taxResult.ExclusiveAmountFor(item.ID)).
+			Add(line.shippingCost)
+
+		quote.lines = append(quote.lines, line)
+	}
+
+	return quote, nil
+}
+
+// resolveAddress returns the saved address of the user, or the new address from the request.
+// A new address is not created here, its AddressId is zero until the order is placed.
+func (s *CheckoutServiceImpl) resolveAddress(keycloakUserID string, addressId *uint, addressReq *data.CreateAddressRequest) (*model.Address, error) {
+	if addressId != nil {
+		// Unlisted addresses were entered for a single order and are not in the address book
+		address, err := s.AddressRepository.GetAddressById(*addressId)
+		if err != nil || address.KeycloakUserID != keycloakUserID || address.Unlisted {
+			return nil, errors.New("address not found")
+		}
+		return address, nil
+	}
+	if addressReq == nil {
+		return nil, errors.New("address is required")
+	}
+	return &model.Address{
+		KeycloakUserID: keycloakUserID,
+		Line1:          addressReq.Line1,
+		Line2:          addressReq.Line2,
+		City:           addressReq.City,
+		PostalCode:     addressReq.PostalCode,
+		Country:        addressReq.Country,
+		Region:         addressReq.Region,
+	}, nil
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"fmt"
+	"shophub-backend/currency"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/model"
+	"shophub-backend/money"
+	"shophub-backend/repository"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+type CurrencyService interface {
+	ResolveCurrency(requested string) (string, error)
+	Rate(from string, to string) (float64, error)
+	Convert(amount money.Money, to string) (money.Money, error)
+	GetRates() *data.ExchangeRatesResponse
+	UpdateRates(req data.UpdateExchangeRatesRequest) (*data.ExchangeRatesResponse, error)
+	LoadRatesFile(path string) error
+}
+
+// CurrencyServiceImpl keeps the rates in memory, they are stored relative to the default currency
+// so they survive restarts and are reloaded when the service starts
+type CurrencyServiceImpl struct {
+	ExchangeRateRepository repository.ExchangeRateRepository
+
+	mu    sync.RWMutex
+	table *currency.Table
+}
+
+func NewCurrencyServiceImpl(ExchangeRateRepository repository.ExchangeRateRepository) (service CurrencyService, err error) {
+	impl := &CurrencyServiceImpl{
+		ExchangeRateRepository: ExchangeRateRepository,
+		table:                  currency.NewTable(money.DefaultCurrency, nil),
+	}
+
+	stored, err := ExchangeRateRepository.GetAllRates()
+	if err != nil {
+		logger.ActError("Unable to load exchange rates", zap.Error(err))
+		return impl, nil
+	}
+	rates := make(map[string]float64, len(stored))
+	for _, rate := range stored {
+		rates[rate.Currency] = rate.Rate
+	}
+	impl.table = currency.NewTable(money.DefaultCurrency, rates)
+	return impl, nil
+}
+
+// ResolveCurrency returns the requested currency, or the default currency when none was requested
+func (s *CurrencyServiceImpl) ResolveCurrency(requested string) (string, error) {
+	code := currency.Normalize(requested)
+	if code == "" {
+		return money.DefaultCurrency, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.table.Supports(code) {
+		return "", fmt.Errorf("unsupported currency %s", code)
+	}
+	return code, nil
+}
+
+func (s *CurrencyServiceImpl) Rate(from string, to string) (float64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.table.Rate(from, to)
+}
+
+func (s *CurrencyServiceImpl) Convert(amount money.Money, to string) (money.Money, error) {
+	if amount.Currency == to {
+		return amount, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	converted, _, err := s.table.Convert(amount, to)
+	return converted, err
+}
+
+func (s *CurrencyServiceImpl) GetRates() *data.ExchangeRatesResponse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &data.ExchangeRatesResponse{
+		Base:       s.table.Base,
+		Currencies: s.table.Codes(),
+		Rates:      s.table.Rates(),
+	}
+}
+
+// UpdateRates adds or updates the given rates, currencies that are not in the request keep their rate
+func (s *CurrencyServiceImpl) UpdateRates(req data.UpdateExchangeRatesRequest) (*data.ExchangeRatesResponse, error) {
+	rates := make(map[string]float64, len(req.Rates))
+	for code, rate := range req.Rates {
+		code = currency.Normalize(code)
+		if !currency.IsValidCode(code) {
+			return nil, fmt.Errorf("invalid currency code %q", code)
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("exchange rate for %s must be positive", code)
+		}
+		if code == money.DefaultCurrency && rate != 1 {
+			return nil, fmt.Errorf("exchange rate for the default currency %s must be 1", code)
+		}
+		rates[code] = rate
+	}
+
+	if err := s.saveRates(rates); err != nil {
+		return nil, err
+	}
+	return s.GetRates(), nil
+}
+
+// LoadRatesFile loads rates from a JSON file, the file may use any base currency
+// as long as it includes the default currency
+func (s *CurrencyServiceImpl) LoadRatesFile(path string) error {
+	table, err := currency.LoadFile(path)
+	if err != nil {
+		return err
+	}
+	rebased, err := table.Rebase(money.DefaultCurrency)
+	if err != nil {
+		return fmt.Errorf("exchange rate file %s does not include the default currency: %w", path, err)
+	}
+	return s.saveRates(rebased.Rates())
+}
+
+func (s *CurrencyServiceImpl) saveRates(rates map[string]float64) error {
+	var records []model.ExchangeRate
+	for code, rate := range rates {
+		if code == money.DefaultCurrency {
+			continue
+		}
+		records = append(records, model.ExchangeRate{Currency: code, Rate: rate})
+	}
+	if err := s.ExchangeRateRepository.SaveRates(records); err != nil {
+		logger.ActError("Unable to save exchange rates", zap.Error(err))
+		return fmt.Errorf("failed to save exchange rates")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	merged := s.table.Rates()
+	for code, rate := range rates {
+		merged[code] = rate
+	}
+	s.table = currency.NewTable(money.DefaultCurrency, merged)
+	return nil
+}
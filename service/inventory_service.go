@@ -0,0 +1,284 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/model"
+	"shophub-backend/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	OrderStatusPending   = "Pending"
+	OrderStatusConfirmed = "CONFIRMED"
+	OrderStatusDelivered = "DELIVERED"
+	OrderStatusCancelled = "CANCELLED"
+
+	PaymentStatusFailed  = "FAILED"
+	PaymentStatusExpired = "EXPIRED"
+
+	ReleaseReasonPaymentFailed  = "payment failed"
+	ReleaseReasonExpired        = "expired"
+	ReleaseReasonCheckoutFailed = "checkout failed"
+)
+
+type InventoryService interface {
+	ReserveStock(keycloakUserID string, productID uint, variantID uint, quantity int, country string) ([]model.InventoryReservation, error)
+	AttachOrder(reservationId uint, orderId uint) error
+	CommitOrder(orderId uint) error
+	ReleaseOrder(orderId uint, reason string) error
+	ReleaseReservation(reservationId uint, reason string) error
+	ReleaseExpired() (int, error)
+	AvailableStock(products []model.Product) (map[uint]int, error)
+	AvailableVariantStock(variantIds []uint) (map[uint]int, error)
+	GetStockHistory(productId uint) ([]model.StockMovement, error)
+	Reconcile(apply bool) ([]data.StockDiscrepancy, error)
+}
+
+// InventoryServiceImpl reserves stock for orders while their payment is pending. The available
+// stock of a product is its stock in the active warehouses less the active reservations.
+type InventoryServiceImpl struct {
+	InventoryRepository     repository.InventoryRepository
+	StockMovementRepository repository.StockMovementRepository
+	OrderRepository         repository.OrderRepository
+	PaymentRepository       repository.PaymentRepository
+	// ReservationTTL is how long stock is held for an unpaid order
+	ReservationTTL time.Duration
+}
+
+func NewInventoryServiceImpl(InventoryRepository repository.InventoryRepository, StockMovementRepository repository.StockMovementRepository, OrderRepository repository.OrderRepository, PaymentRepository repository.PaymentRepository, ReservationTTL time.Duration) (service InventoryService, err error) {
+	return &InventoryServiceImpl{
+		InventoryRepository:     InventoryRepository,
+		StockMovementRepository: StockMovementRepository,
+		OrderRepository:         OrderRepository,
+		PaymentRepository:       PaymentRepository,
+		ReservationTTL:          ReservationTTL,
+	}, err
+}
+
+// ReserveStock holds the quantity of the product, or of its variant when variantID is not zero, for an order
+// shipping to the country. It is taken from the nearest warehouses first and split over several warehouses
+// when one does not have enough.
+func (s *InventoryServiceImpl) ReserveStock(keycloakUserID string, productID uint, variantID uint, quantity int, country string) ([]model.InventoryReservation, error) {
+	if quantity <= 0 {
+		return nil, fmt.Errorf("quantity must be positive")
+	}
+
+	reservation := model.InventoryReservation{
+		ProductID:      productID,
+		VariantID:      variantID,
+		KeycloakUserID: keycloakUserID,
+		Quantity:       quantity,
+		ExpiresAt:      time.Now().Add(s.ReservationTTL),
+	}
+	reservations, err := s.InventoryRepository.Reserve(reservation, country)
+	if err != nil {
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			return nil, err
+		}
+		logger.ActError("Unable to reserve stock", zap.Uint("product_id", productID), zap.Error(err))
+		return nil, fmt.Errorf("failed to reserve stock")
+	}
+	return reservations, nil
+}
+
+func (s *InventoryServiceImpl) AttachOrder(reservationId uint, orderId uint) error {
+	if err := s.InventoryRepository.AttachOrder(reservationId, orderId); err != nil {
+		logger.ActError("Unable to attach the reservation to the order", zap.Error(err))
+		return fmt.Errorf("failed to attach reservation to order")
+	}
+	return nil
+}
+
+// CommitOrder takes the reserved stock of a paid order off the product stock. Orders placed
+// before stock was reserved have no reservations and nothing to commit.
+func (s *InventoryServiceImpl) CommitOrder(orderId uint) error {
+	reservations, err := s.InventoryRepository.GetReservationsByOrder(orderId)
+	if err != nil {
+		logger.ActError("Unable to load the order reservations", zap.Error(err))
+		return fmt.Errorf("failed to load reservations")
+	}
+
+	for _, reservation := range reservations {
+		switch reservation.Status {
+		case model.ReservationStatusCommitted:
+			continue
+		case model.ReservationStatusReleased:
+			return fmt.Errorf("stock reservation for order %d has %s", orderId, releasedMessage(reservation.ReleaseReason))
+		}
+
+		if err := s.InventoryRepository.Commit(reservation.ReservationID); err != nil {
+			if errors.Is(err, repository.ErrReservationExpired) {
+				return fmt.Errorf("stock reservation for order %d has expired", orderId)
+			}
+			logger.ActError("Unable to commit the reservation", zap.Uint("reservation_id", reservation.ReservationID), zap.Error(err))
+			return fmt.Errorf("failed to commit reservation")
+		}
+	}
+	return nil
+}
+
+// ReleaseOrder gives back the stock of a cancelled order. Active reservations are released and the
+// stock of committed ones is put back and recorded in the stock ledger as a cancellation.
+func (s *InventoryServiceImpl) ReleaseOrder(orderId uint, reason string) error {
+	reservations, err := s.InventoryRepository.GetReservationsByOrder(orderId)
+	if err != nil {
+		logger.ActError("Unable to load the order reservations", zap.Error(err))
+		return fmt.Errorf("failed to load reservations")
+	}
+
+	for _, reservation := range reservations {
+		if reservation.Status == model.ReservationStatusReleased {
+			continue
+		}
+		if err := s.ReleaseReservation(reservation.ReservationID, reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseReservation releases an active reservation or gives back the stock of a committed one
+func (s *InventoryServiceImpl) ReleaseReservation(reservationId uint, reason string) error {
+	if err := s.InventoryRepository.Release(reservationId, reason); err != nil {
+		logger.ActError("Unable to release the reservation", zap.Uint("reservation_id", reservationId), zap.Error(err))
+		return fmt.Errorf("failed to release reservation")
+	}
+	return nil
+}
+
+// ReleaseExpired releases the reservations of unpaid orders that ran out of time and cancels
+// those orders, giving back any of their stock that was already committed. It returns how many
+// expired reservations were released.
+func (s *InventoryServiceImpl) ReleaseExpired() (int, error) {
+	reservations, err := s.InventoryRepository.GetExpiredReservations(time.Now())
+	if err != nil {
+		logger.ActError("Unable to load expired reservations", zap.Error(err))
+		return 0, fmt.Errorf("failed to load expired reservations")
+	}
+
+	released := 0
+	cancelled := make(map[uint]bool)
+	for _, reservation := range reservations {
+		if reservation.OrderID == nil {
+			if err := s.ReleaseReservation(reservation.ReservationID, ReleaseReasonExpired); err != nil {
+				return released, err
+			}
+			released++
+			continue
+		}
+
+		orderId := *reservation.OrderID
+		if !cancelled[orderId] {
+			if err := s.ReleaseOrder(orderId, ReleaseReasonExpired); err != nil {
+				return released, err
+			}
+			cancelled[orderId] = true
+
+			if err := s.OrderRepository.UpdateOrderStatus(orderId, OrderStatusCancelled); err != nil {
+				logger.ActError("Unable to cancel the expired order", zap.Uint("order_id", orderId), zap.Error(err))
+			}
+			if err := s.PaymentRepository.UpdatePaymentStatus(orderId, PaymentStatusExpired); err != nil {
+				logger.ActError("Unable to expire the payment", zap.Uint("order_id", orderId), zap.Error(err))
+			}
+		}
+		released++
+	}
+
+	if released > 0 {
+		logger.ActInfo("Expired stock reservations released", zap.Int("count", released))
+	}
+	return released, nil
+}
+
+// AvailableStock is the stock of each product over all active warehouses less its active reservations
+func (s *InventoryServiceImpl) AvailableStock(products []model.Product) (map[uint]int, error) {
+	productIds := make([]uint, 0, len(products))
+	for _, product := range products {
+		productIds = append(productIds, product.ProductID)
+	}
+
+	available, err := s.InventoryRepository.GetAvailableQuantities(productIds)
+	if err != nil {
+		logger.ActError("Unable to load available stock", zap.Error(err))
+		return nil, fmt.Errorf("failed to load available stock")
+	}
+	return available, nil
+}
+
+// AvailableVariantStock is the stock of each variant over all active warehouses less its active reservations
+func (s *InventoryServiceImpl) AvailableVariantStock(variantIds []uint) (map[uint]int, error) {
+	available, err := s.InventoryRepository.GetAvailableVariantQuantities(variantIds)
+	if err != nil {
+		logger.ActError("Unable to load available variant stock", zap.Error(err))
+		return nil, fmt.Errorf("failed to load available stock")
+	}
+	return available, nil
+}
+
+// GetStockHistory is the ledger of the product, newest movement first
+func (s *InventoryServiceImpl) GetStockHistory(productId uint) ([]model.StockMovement, error) {
+	movements, err := s.StockMovementRepository.GetMovementsByProduct(productId)
+	if err != nil {
+		logger.ActError("Unable to load the stock history", zap.Uint("product_id", productId), zap.Error(err))
+		return nil, fmt.Errorf("failed to load stock history")
+	}
+	return movements, nil
+}
+
+// Reconcile recomputes the stock of every warehouse and product from the ledger and returns where it
+// differs from the recorded stock. With apply the recorded stock is overwritten with the ledger's.
+func (s *InventoryServiceImpl) Reconcile(apply bool) ([]data.StockDiscrepancy, error) {
+	warehouseBalances, err := s.StockMovementRepository.GetWarehouseBalances()
+	if err != nil {
+		logger.ActError("Unable to load the warehouse stock balances", zap.Error(err))
+		return nil, fmt.Errorf("failed to load warehouse stock balances")
+	}
+	productBalances, err := s.StockMovementRepository.GetProductBalances()
+	if err != nil {
+		logger.ActError("Unable to load the product stock balances", zap.Error(err))
+		return nil, fmt.Errorf("failed to load product stock balances")
+	}
+
+	discrepancies := []data.StockDiscrepancy{}
+	for _, balance := range append(warehouseBalances, productBalances...) {
+		if balance.Recorded == balance.Ledger {
+			continue
+		}
+		discrepancies = append(discrepancies, data.StockDiscrepancy{
+			ProductID:   balance.ProductID,
+			VariantID:   balance.VariantID,
+			WarehouseID: balance.WarehouseID,
+			Recorded:    balance.Recorded,
+			Ledger:      balance.Ledger,
+			Difference:  balance.Ledger - balance.Recorded,
+		})
+		if !apply {
+			continue
+		}
+
+		if balance.WarehouseID != nil {
+			err = s.StockMovementRepository.SetWarehouseStock(*balance.WarehouseID, balance.ProductID, balance.VariantID, balance.Ledger)
+		} else {
+			err = s.StockMovementRepository.SetProductStock(balance.ProductID, balance.Ledger)
+		}
+		if err != nil {
+			logger.ActError("Unable to correct the stock", zap.Uint("product_id", balance.ProductID), zap.Error(err))
+			return discrepancies, fmt.Errorf("failed to correct stock of product %d", balance.ProductID)
+		}
+	}
+
+	logger.ActInfo("Stock reconciled against the ledger", zap.Int("discrepancies", len(discrepancies)), zap.Bool("applied", apply))
+	return discrepancies, nil
+}
+
+func releasedMessage(reason string) string {
+	if reason == ReleaseReasonExpired {
+		return "expired"
+	}
+	return "been released"
+}
@@ -11,6 +11,7 @@ import (
 type OrderService interface {
 	CreateOrder(keycloakUserID string) (*model.Order, error)
 	GetOrderByUser(keycloakUserID string) ([]model.Order, error)
+	MarkDelivered(orderId uint) (*model.Order, error)
 }
 
 type OrderServiceImpl struct {
@@ -18,14 +19,16 @@ type OrderServiceImpl struct {
 	ProductRepository repository.ProductRepository
 	CartRepository    repository.CartRepository
 	PaymentRepository repository.PaymentRepository
+	InventoryService  InventoryService
 }
 
-func NewOrderServiceImpl(OrderRepository repository.OrderRepository, ProductRepository repository.ProductRepository, CartRepository repository.CartRepository, PaymentRepository repository.PaymentRepository) (service OrderService, err error) {
+func NewOrderServiceImpl(OrderRepository repository.OrderRepository, ProductRepository repository.ProductRepository, CartRepository repository.CartRepository, PaymentRepository repository.PaymentRepository, InventoryService InventoryService) (service OrderService, err error) {
 	return &OrderServiceImpl{
 		OrderRepository:   OrderRepository,
 		CartRepository:    CartRepository,
 		ProductRepository: ProductRepository,
 		PaymentRepository: PaymentRepository,
+		InventoryService:  InventoryService,
 	}, err
 }
 
@@ -61,8 +64,22 @@ func (s *OrderServiceImpl) CreateOrder(keycloakUserID string) (*model.Order, err
 			return nil, err
 		}
 
+		// Holding the stock until the order exists, it is committed once the order is created
+		variant, err := chosenVariant(product, item.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		reservations, err := s.InventoryService.ReserveStock(keycloakUserID, item.ProductID, item.StockVariantID(), item.Quantity, "")
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			return nil, errors.New("Insufficient stock for " + product.ProductName)
+		}
+		if err != nil {
+			return nil, err
+		}
+
 		// Calculate price for this item
-		itemTotalPrice := float64(item.Quantity) * product.ProductPrice
+		unitPrice := product.PriceFor(variant)
+		itemTotalPrice := unitPrice.Mul(item.Quantity)
 
 		// Create payment without OrderId (will be updated after order creation)
 		payment := &model.Payment{
@@ -70,6 +87,7 @@ func (s *OrderServiceImpl) CreateOrder(keycloakUserID string) (*model.Order, err
 			KeycloakUserID: keycloakUserID,
 			PaymentMethod:  "CASH",
 			PaymentAmount:  itemTotalPrice,
+			Currency:       product.Currency,
 			Status:         "UNPAID",
 		}
 
@@ -84,12 +102,15 @@ func (s *OrderServiceImpl) CreateOrder(keycloakUserID string) (*model.Order, err
 			KeycloakUserID: keycloakUserID,
 			ProductId:      item.ProductID,
 			PaymentId:      payment.PaymentId,
-			ProductPrice:   product.ProductPrice,
+			ProductPrice:   unitPrice,
 			Quantity:       uint(item.Quantity),
 			TotalPrice:     itemTotalPrice,
+			Currency:       product.Currency,
+			ExchangeRate:   1,
 			OrderStatus:    "Pending",
 			CreatedAt:      time.Now(),
 		}
+		order.SetProduct(product, variant)
 
 		if err := s.OrderRepository.CreateOrder(order); err != nil {
 			logger.ActError("Unable to create the order")
@@ -102,9 +123,13 @@ func (s *OrderServiceImpl) CreateOrder(keycloakUserID string) (*model.Order, err
 			return nil, err
 		}
 
-		// Update stock after order is created
-		product.ProductStock -= item.Quantity
-		if err := s.ProductRepository.UpdateProduct(product); err != nil {
+		// Update stock after order is created, the sale is recorded in the stock ledger
+		for _, reservation := range reservations {
+			if err := s.InventoryService.AttachOrder(reservation.ReservationID, order.OrderId); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.InventoryService.CommitOrder(order.OrderId); err != nil {
 			logger.ActError("Unable to update product stock")
 			return nil, err
 		}
@@ -127,3 +152,21 @@ func (s *OrderServiceImpl) CreateOrder(keycloakUserID string) (*model.Order, err
 func (s *OrderServiceImpl) GetOrderByUser(keycloakUserID string) ([]model.Order, error) {
 	return s.OrderRepository.GetOrderByKeycloakUserID(keycloakUserID)
 }
+
+// MarkDelivered records that a confirmed order reached the customer, who can then review the product
+func (s *OrderServiceImpl) MarkDelivered(orderId uint) (*model.Order, error) {
+	order, err := s.OrderRepository.GetOrderById(orderId)
+	if err != nil {
+		return nil, errors.New("order not found")
+	}
+	if order.OrderStatus != OrderStatusConfirmed {
+		return nil, errors.New("only confirmed orders can be delivered, the order is " + order.OrderStatus)
+	}
+
+	if err := s.OrderRepository.UpdateOrderStatus(orderId, OrderStatusDelivered); err != nil {
+		logger.ActError("Unable to update the order status")
+		return nil, errors.New("failed to update order status")
+	}
+	order.OrderStatus = OrderStatusDelivered
+	return order, nil
+}
@@ -2,25 +2,30 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"shophub-backend/logger"
 	"shophub-backend/model"
 	"shophub-backend/repository"
+	"strings"
 )
 
 type PaymentService interface {
 	GetPaymentByOrderId(OrderId uint) (*model.Payment, error)
 	ProcessPayment(orderId uint, paymentMethod string) (*model.Payment, error)
+	FailPayment(keycloakUserID string, orderId uint) (*model.Payment, error)
 }
 
 type PaymentServiceImpl struct {
 	PaymentRepository repository.PaymentRepository
 	OrderRepository   repository.OrderRepository
+	InventoryService  InventoryService
 }
 
-func NewPaymentServiceImpl(PaymentRepository repository.PaymentRepository, OrderRepository repository.OrderRepository) (service PaymentService, err error) {
+func NewPaymentServiceImpl(PaymentRepository repository.PaymentRepository, OrderRepository repository.OrderRepository, InventoryService InventoryService) (service PaymentService, err error) {
 	return &PaymentServiceImpl{
 		PaymentRepository: PaymentRepository,
 		OrderRepository:   OrderRepository,
+		InventoryService:  InventoryService,
 	}, err
 }
 
@@ -50,6 +55,11 @@ func (s *PaymentServiceImpl) ProcessPayment(orderId uint, paymentMethod string)
 		return nil, errors.New("payment order ID is not set")
 	}
 
+	// The reserved stock is taken off the product stock once the order is paid
+	if err := s.InventoryService.CommitOrder(*payment.OrderId); err != nil {
+		return nil, err
+	}
+
 	if err := s.PaymentRepository.UpdatePaymentStatus(*payment.OrderId, "PAID"); err != nil {
 		return nil, err
 	}
@@ -59,3 +69,37 @@ func (s *PaymentServiceImpl) ProcessPayment(orderId uint, paymentMethod string)
 	return payment, nil
 
 }
+
+// FailPayment records a failed payment on one of the user's pending orders, the order is cancelled and
+// its stock given back, including stock already committed for a cash order
+func (s *PaymentServiceImpl) FailPayment(keycloakUserID string, orderId uint) (*model.Payment, error) {
+	order, err := s.OrderRepository.GetOrderById(orderId)
+	if err != nil || order.KeycloakUserID != keycloakUserID {
+		return nil, errors.New("order not found")
+	}
+	if !strings.EqualFold(order.OrderStatus, OrderStatusPending) {
+		return nil, fmt.Errorf("only pending orders can fail payment, the order is %s", strings.ToLower(order.OrderStatus))
+	}
+
+	payment, err := s.PaymentRepository.GetPaymentByOrder(orderId)
+	if err != nil {
+		logger.ActError("error occured while fetching the payment")
+		return nil, errors.New("payment not found")
+	}
+	if payment.Status == "PAID" {
+		return nil, errors.New("payment has already been paid")
+	}
+
+	if err := s.PaymentRepository.UpdatePaymentStatus(orderId, PaymentStatusFailed); err != nil {
+		return nil, err
+	}
+	if err := s.OrderRepository.UpdateOrderStatus(orderId, OrderStatusCancelled); err != nil {
+		return nil, err
+	}
+	if err := s.InventoryService.ReleaseOrder(orderId, ReleaseReasonPaymentFailed); err != nil {
+		return nil, err
+	}
+
+	payment.Status = PaymentStatusFailed
+	return payment, nil
+}
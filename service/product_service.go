@@ -1,34 +1,372 @@
 package service
 
 import (
+	"fmt"
+	"shophub-backend/data"
+	"shophub-backend/facet"
+	"shophub-backend/logger"
 	"shophub-backend/model"
 	"shophub-backend/repository"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
 )
 
+const ProductSortRating = "rating"
+
 type ProductService interface {
-	GetAllProducts() ([]model.Product, error)
-	GetProductById(productId uint) (*model.Product, error)
-	GetProductBySlug(productSlug string) (*model.Product, error)
+	GetAllProducts(currency string, query data.ProductListQuery) (*data.ProductListResponse, error)
+	GetProductById(productId uint, currency string) (*data.ProductResponse, error)
+	GetProductBySlug(productSlug string, currency string) (*data.ProductResponse, error)
+	GetProductsByIds(productIds []uint, currency string) ([]data.ProductResponse, error)
+	GetStockHistory(productId uint) ([]model.StockMovement, error)
+	UpdateProductStatus(productId uint, req data.UpdateProductStatusRequest) (*model.Product, error)
+	DeleteProduct(productId uint) error
+	ApplyPublishingSchedule() (int, error)
 }
 
 type ProductServiceImpl struct {
-	ProductRepository repository.ProductRepository
+	ProductRepository   repository.ProductRepository
+	AttributeRepository repository.AttributeRepository
+	CurrencyService     CurrencyService
+	InventoryService    InventoryService
 }
 
-func NewProductServiceImpl(ProductRepository repository.ProductRepository) (service ProductService, err error) {
+func NewProductServiceImpl(ProductRepository repository.ProductRepository, AttributeRepository repository.AttributeRepository, CurrencyService CurrencyService, InventoryService InventoryService) (service ProductService, err error) {
 	return &ProductServiceImpl{
-		ProductRepository: ProductRepository,
+		ProductRepository:   ProductRepository,
+		AttributeRepository: AttributeRepository,
+		CurrencyService:     CurrencyService,
+		InventoryService:    InventoryService,
 	}, err
 }
 
-func (s *ProductServiceImpl) GetAllProducts() ([]model.Product, error) {
-	return s.ProductRepository.GetAllProducts()
+// GetAllProducts lists the products of the category, or of all categories, that match the filters,
+// with the facets of the filterable attributes counted over the whole category
+func (s *ProductServiceImpl) GetAllProducts(currency string, query data.ProductListQuery) (*data.ProductListResponse, error) {
+	currency, err := s.CurrencyService.ResolveCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.ProductRepository.GetAllProducts()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	listable := products[:0]
+	for _, product := range products {
+		if !product.IsAvailable(now) {
+			continue
+		}
+		if query.CategoryID != nil && product.CategoryID != *query.CategoryID {
+			continue
+		}
+		listable = append(listable, product)
+	}
+	products = listable
+
+	definitions, err := s.AttributeRepository.GetFilterableAttributes(query.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	attributes := facetAttributes(definitions)
+	selection, err := filterSelection(attributes, query.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]facet.Item, 0, len(products))
+	for _, product := range products {
+		items = append(items, facetItem(product))
+	}
+	matched := map[uint]bool{}
+	for _, item := range facet.Filter(items, selection) {
+		matched[item.ID] = true
+	}
+	var listed []model.Product
+	for _, product := range products {
+		if matched[product.ProductID] {
+			listed = append(listed, product)
+		}
+	}
+	if err := sortProducts(listed, query.Sort); err != nil {
+		return nil, err
+	}
+
+	available, variantAvailable, err := s.availableStock(listed)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]data.ProductResponse, 0, len(listed))
+	for _, product := range listed {
+		response, err := s.toResponse(product, currency, available, variantAvailable)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return &data.ProductListResponse{
+		Products: responses,
+		Facets:   facet.Compute(attributes, items, selection),
+		Total:    len(responses),
+	}, nil
+}
+
+func (s *ProductServiceImpl) GetProductById(productId uint, currency string) (*data.ProductResponse, error) {
+	currency, err := s.CurrencyService.ResolveCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.ProductRepository.GetProductById(productId)
+	if err != nil || !product.IsAvailable(time.Now()) {
+		return nil, fmt.Errorf("product not found")
+	}
+	available, variantAvailable, err := s.availableStock([]model.Product{*product})
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(*product, currency, available, variantAvailable)
+}
+
+func (s *ProductServiceImpl) GetProductBySlug(productSlug string, currency string) (*data.ProductResponse, error) {
+	currency, err := s.CurrencyService.ResolveCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.ProductRepository.GetProductBySlug(productSlug)
+	if err != nil || !product.IsAvailable(time.Now()) {
+		return nil, fmt.Errorf("product not found")
+	}
+	available, variantAvailable, err := s.availableStock([]model.Product{*product})
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(*product, currency, available, variantAvailable)
+}
+
+// GetProductsByIds returns the products in the order of the ids, ids of products that do not exist
+// or are not available are skipped
+func (s *ProductServiceImpl) GetProductsByIds(productIds []uint, currency string) ([]data.ProductResponse, error) {
+	currency, err := s.CurrencyService.ResolveCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded, err := s.ProductRepository.GetProductsByIds(productIds)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var products []model.Product
+	for _, product := range loaded {
+		if product.IsAvailable(now) {
+			products = append(products, product)
+		}
+	}
+	available, variantAvailable, err := s.availableStock(products)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[uint]model.Product, len(products))
+	for _, product := range products {
+		byId[product.ProductID] = product
+	}
+	responses := make([]data.ProductResponse, 0, len(products))
+	for _, productId := range productIds {
+		product, ok := byId[productId]
+		if !ok {
+			continue
+		}
+		response, err := s.toResponse(product, currency, available, variantAvailable)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+func (s *ProductServiceImpl) GetStockHistory(productId uint) ([]model.StockMovement, error) {
+	if _, err := s.ProductRepository.GetProductById(productId); err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+	return s.InventoryService.GetStockHistory(productId)
 }
 
-func (s *ProductServiceImpl) GetProductById(productId uint) (*model.Product, error) {
+// UpdateProductStatus sets the status of the product and the times it is published and unpublished
+func (s *ProductServiceImpl) UpdateProductStatus(productId uint, req data.UpdateProductStatusRequest) (*model.Product, error) {
+	if _, err := s.ProductRepository.GetProductById(productId); err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+	if req.PublishAt != nil && req.UnpublishAt != nil && !req.UnpublishAt.After(*req.PublishAt) {
+		return nil, fmt.Errorf("unpublish_at must be after publish_at")
+	}
+
+	if err := s.ProductRepository.UpdateProductStatus(productId, req.Status, req.PublishAt, req.UnpublishAt); err != nil {
+		logger.ActError("Unable to update product status", zap.Uint("product_id", productId), zap.Error(err))
+		return nil, fmt.Errorf("failed to update product status")
+	}
 	return s.ProductRepository.GetProductById(productId)
 }
 
-func (s *ProductServiceImpl) GetProductBySlug(productSlug string) (*model.Product, error) {
-	return s.ProductRepository.GetProductBySlug(productSlug)
+// DeleteProduct archives the product and hides it everywhere, orders keep their copy of it
+// and cart items of it are flagged as unavailable
+func (s *ProductServiceImpl) DeleteProduct(productId uint) error {
+	if _, err := s.ProductRepository.GetProductById(productId); err != nil {
+		return fmt.Errorf("product not found")
+	}
+	if err := s.ProductRepository.DeleteProduct(productId); err != nil {
+		logger.ActError("Unable to delete product", zap.Uint("product_id", productId), zap.Error(err))
+		return fmt.Errorf("failed to delete product")
+	}
+	logger.ActInfo("Product deleted", zap.Uint("product_id", productId))
+	return nil
+}
+
+// ApplyPublishingSchedule publishes and unpublishes the products whose scheduled time has come,
+// it returns how many products changed status
+func (s *ProductServiceImpl) ApplyPublishingSchedule() (int, error) {
+	published, unpublished, err := s.ProductRepository.ApplyPublishingSchedule(time.Now())
+	if err != nil {
+		logger.ActError("Unable to apply the publishing schedule", zap.Error(err))
+		return 0, fmt.Errorf("failed to apply publishing schedule")
+	}
+	if published > 0 || unpublished > 0 {
+		logger.ActInfo("Publishing schedule applied", zap.Int64("published", published), zap.Int64("unpublished", unpublished))
+	}
+	return int(published + unpublished), nil
+}
+
+// availableStock is the available stock of the products and of their variants
+func (s *ProductServiceImpl) availableStock(products []model.Product) (map[uint]int, map[uint]int, error) {
+	available, err := s.InventoryService.AvailableStock(products)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var variantIds []uint
+	for _, product := range products {
+		for _, variant := range product.Variants {
+			variantIds = append(variantIds, variant.VariantID)
+		}
+	}
+	variantAvailable, err := s.InventoryService.AvailableVariantStock(variantIds)
+	if err != nil {
+		return nil, nil, err
+	}
+	return available, variantAvailable, nil
+}
+
+// toResponse adds the product price converted to the display currency and the available stock,
+// and the same for each of its active variants
+func (s *ProductServiceImpl) toResponse(product model.Product, currency string, available map[uint]int, variantAvailable map[uint]int) (*data.ProductResponse, error) {
+	rate, err := s.CurrencyService.Rate(product.Currency, currency)
+	if err != nil {
+		return nil, err
+	}
+	price, err := s.CurrencyService.Convert(product.ProductPrice, currency)
+	if err != nil {
+		return nil, err
+	}
+	response := &data.ProductResponse{
+		Product:         product,
+		DisplayCurrency: currency,
+		DisplayPrice:    price,
+		ExchangeRate:    rate,
+		AvailableStock:  available[product.ProductID],
+	}
+
+	for i := range product.Variants {
+		variant := &product.Variants[i]
+		if !variant.IsActive {
+			continue
+		}
+		variantPrice, err := s.CurrencyService.Convert(product.PriceFor(variant), currency)
+		if err != nil {
+			return nil, err
+		}
+		response.Variants = append(response.Variants, data.VariantResponse{
+			ProductVariant: *variant,
+			Name:           variant.Name(),
+			DisplayPrice:   variantPrice,
+			AvailableStock: variantAvailable[variant.VariantID],
+		})
+	}
+	return response, nil
+}
+
+// facetAttributes turns the attribute definitions into facets, attributes of different categories
+// with the same code are one facet described by the first of them
+func facetAttributes(definitions []model.AttributeDefinition) []facet.Attribute {
+	var attributes []facet.Attribute
+	seen := map[string]bool{}
+	for _, definition := range definitions {
+		if seen[definition.Code] {
+			continue
+		}
+		seen[definition.Code] = true
+		attributes = append(attributes, toFacetAttribute(definition))
+	}
+	return attributes
+}
+
+// filterSelection checks the filters against the filterable attributes and normalizes their values
+// so they compare equal to the stored values
+func filterSelection(attributes []facet.Attribute, filters map[string][]string) (facet.Selection, error) {
+	byCode := make(map[string]facet.Attribute, len(attributes))
+	for _, attribute := range attributes {
+		byCode[attribute.Code] = attribute
+	}
+
+	selection := facet.Selection{}
+	for code, values := range filters {
+		attribute, ok := byCode[strings.ToLower(code)]
+		if !ok {
+			return nil, fmt.Errorf("invalid filter: %s is not a filterable attribute", code)
+		}
+		for _, value := range values {
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+			normalized, err := facet.Normalize(attribute, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter: %v", err)
+			}
+			selection[attribute.Code] = append(selection[attribute.Code], normalized)
+		}
+	}
+	return selection, nil
+}
+
+// sortProducts orders the products, by rating puts the highest average first and breaks ties by the number of reviews
+func sortProducts(products []model.Product, by string) error {
+	switch by {
+	case "":
+		return nil
+	case ProductSortRating:
+		sort.SliceStable(products, func(i, j int) bool {
+			if products[i].RatingAverage != products[j].RatingAverage {
+				return products[i].RatingAverage > products[j].RatingAverage
+			}
+			return products[i].RatingCount > products[j].RatingCount
+		})
+		return nil
+	default:
+		return fmt.Errorf("invalid sort: products can be sorted by %s", ProductSortRating)
+	}
+}
+
+func facetItem(product model.Product) facet.Item {
+	values := make(map[string]string, len(product.Attributes))
+	for _, attribute := range product.Attributes {
+		values[attribute.Attribute.Code] = attribute.Value
+	}
+	return facet.Item{ID: product.ProductID, Values: values}
 }
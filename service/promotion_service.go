@@ -0,0 +1,331 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/model"
+	"shophub-backend/money"
+	"shophub-backend/promotion"
+	"shophub-backend/repository"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type PromotionService interface {
+	PriceLines(keycloakUserID string, lines []promotion.Line, couponCode string) (*promotion.Pricing, error)
+	EvaluateCoupon(keycloakUserID string, code string, lines []promotion.Line) (*model.Coupon, *promotion.Discount, error)
+	RedeemCoupon(coupon *model.Coupon, keycloakUserID string, discountAmount money.Money) (*model.CouponRedemption, error)
+	AttachRedemptionOrder(redemptionId uint, orderId uint) error
+	CancelRedemption(redemptionId uint) error
+	CreateCoupon(req data.CreateCouponRequest) (*model.Coupon, error)
+	GetAllCoupons() ([]model.Coupon, error)
+	CreatePromotion(req data.CreatePromotionRequest) (*model.Promotion, error)
+	GetAllPromotions() ([]model.Promotion, error)
+}
+
+type PromotionServiceImpl struct {
+	CouponRepository    repository.CouponRepository
+	PromotionRepository repository.PromotionRepository
+	CurrencyService     CurrencyService
+}
+
+func NewPromotionServiceImpl(CouponRepository repository.CouponRepository, PromotionRepository repository.PromotionRepository, CurrencyService CurrencyService) (service PromotionService, err error) {
+	return &PromotionServiceImpl{
+		CouponRepository:    CouponRepository,
+		PromotionRepository: PromotionRepository,
+		CurrencyService:     CurrencyService,
+	}, err
+}
+
+// PriceLines applies the automatic promotions and then the coupon, if any, to the lines.
+// The lines must all be in the same currency, promotion and coupon amounts are converted to it.
+// An invalid coupon does not fail the pricing, the reason is returned in CouponError instead.
+func (s *PromotionServiceImpl) PriceLines(keycloakUserID string, lines []promotion.Line, couponCode string) (*promotion.Pricing, error) {
+	promotions, err := s.PromotionRepository.GetActivePromotions()
+	if err != nil {
+		logger.ActError("Unable to load active promotions", zap.Error(err))
+		return nil, fmt.Errorf("failed to load promotions")
+	}
+	if err := s.localizePromotions(promotions, linesCurrency(lines)); err != nil {
+		return nil, err
+	}
+
+	automatic := promotion.Evaluate(promotions, lines, time.Now())
+	if couponCode == "" {
+		return promotion.NewPricing(lines, automatic, nil, nil), nil
+	}
+
+	coupon, couponDiscount, err := s.EvaluateCoupon(keycloakUserID, couponCode, promotion.DiscountedLines(lines, automatic.Discount))
+	if err != nil {
+		pricing := promotion.NewPricing(lines, automatic, nil, nil)
+		pricing.CouponError = err.Error()
+		return pricing, nil
+	}
+
+	return promotion.NewPricing(lines, automatic, coupon, couponDiscount), nil
+}
+
+// EvaluateCoupon validates the coupon for the user and calculates its discount on the given lines
+func (s *PromotionServiceImpl) EvaluateCoupon(keycloakUserID string, code string, lines []promotion.Line) (*model.Coupon, *promotion.Discount, error) {
+	coupon, err := s.CouponRepository.GetCouponByCode(NormalizeCouponCode(code))
+	if err != nil {
+		logger.ActError("Coupon not found", zap.String("code", code))
+		return nil, nil, fmt.Errorf("coupon not found")
+	}
+
+	if err := promotion.ValidateCoupon(coupon, time.Now()); err != nil {
+		return nil, nil, err
+	}
+	if err := s.localizeCoupon(coupon, linesCurrency(lines)); err != nil {
+		return nil, nil, err
+	}
+
+	if coupon.PerUserLimit > 0 {
+		used, err := s.CouponRepository.CountRedemptionsByUser(coupon.CouponID, keycloakUserID)
+		if err != nil {
+			logger.ActError("Unable to count coupon redemptions", zap.Error(err))
+			return nil, nil, fmt.Errorf("failed to validate coupon")
+		}
+		if used >= int64(coupon.PerUserLimit) {
+			return nil, nil, fmt.Errorf("coupon usage limit reached for this user")
+		}
+	}
+
+	discount, err := promotion.CouponDiscount(coupon, lines)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return coupon, discount, nil
+}
+
+// RedeemCoupon counts a use of the coupon against its global and per user limits and records the redemption,
+// it is done before the orders are created and attached to the first order once it exists
+func (s *PromotionServiceImpl) RedeemCoupon(coupon *model.Coupon, keycloakUserID string, discountAmount money.Money) (*model.CouponRedemption, error) {
+	redemption := &model.CouponRedemption{
+		CouponID:       coupon.CouponID,
+		KeycloakUserID: keycloakUserID,
+		DiscountAmount: discountAmount,
+		Currency:       discountAmount.Currency,
+	}
+	if err := s.CouponRepository.RedeemCoupon(redemption); err != nil {
+		if errors.Is(err, repository.ErrCouponUsageLimit) || errors.Is(err, repository.ErrCouponUserLimit) {
+			return nil, err
+		}
+		logger.ActError("Unable to redeem coupon", zap.Error(err))
+		return nil, fmt.Errorf("failed to redeem coupon")
+	}
+	return redemption, nil
+}
+
+func (s *PromotionServiceImpl) AttachRedemptionOrder(redemptionId uint, orderId uint) error {
+	if err := s.CouponRepository.AttachRedemptionOrder(redemptionId, orderId); err != nil {
+		logger.ActError("Unable to attach the coupon redemption to the order", zap.Error(err))
+		return fmt.Errorf("failed to attach coupon redemption to order")
+	}
+	return nil
+}
+
+// CancelRedemption gives the use back to the coupon when the checkout fails after redeeming it
+func (s *PromotionServiceImpl) CancelRedemption(redemptionId uint) error {
+	if err := s.CouponRepository.CancelRedemption(redemptionId); err != nil {
+		logger.ActError("Unable to cancel the coupon redemption", zap.Uint("redemption_id", redemptionId), zap.Error(err))
+		return fmt.Errorf("failed to cancel coupon redemption")
+	}
+	return nil
+}
+
+func (s *PromotionServiceImpl) CreateCoupon(req data.CreateCouponRequest) (*model.Coupon, error) {
+	if req.DiscountType == promotion.DiscountTypePercentage && req.DiscountValue > 100 {
+		return nil, fmt.Errorf("percentage discount cannot be more than 100")
+	}
+	if req.MaxDiscountAmount.IsNegative() || req.MinOrderValue.IsNegative() {
+		return nil, fmt.Errorf("coupon amounts cannot be negative")
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && req.EndsAt.Before(*req.StartsAt) {
+		return nil, fmt.Errorf("coupon end date must be after the start date")
+	}
+
+	coupon := &model.Coupon{
+		Code:              NormalizeCouponCode(req.Code),
+		Description:       req.Description,
+		DiscountType:      req.DiscountType,
+		DiscountValue:     req.DiscountValue,
+		MaxDiscountAmount: req.MaxDiscountAmount,
+		MinOrderValue:     req.MinOrderValue,
+		UsageLimit:        req.UsageLimit,
+		PerUserLimit:      req.PerUserLimit,
+		StartsAt:          req.StartsAt,
+		EndsAt:            req.EndsAt,
+		IsActive:          true,
+	}
+	for _, productID := range req.ProductIDs {
+		coupon.Products = append(coupon.Products, model.Product{ProductID: productID})
+	}
+	for _, categoryID := range req.CategoryIDs {
+		coupon.Categories = append(coupon.Categories, model.Category{CategoryID: categoryID})
+	}
+
+	if err := s.CouponRepository.CreateCoupon(coupon); err != nil {
+		logger.ActError("Unable to create coupon", zap.Error(err))
+		return nil, fmt.Errorf("failed to create coupon: %v", err)
+	}
+	return coupon, nil
+}
+
+func (s *PromotionServiceImpl) GetAllCoupons() ([]model.Coupon, error) {
+	return s.CouponRepository.GetAllCoupons()
+}
+
+func (s *PromotionServiceImpl) CreatePromotion(req data.CreatePromotionRequest) (*model.Promotion, error) {
+	switch req.Type {
+	case promotion.PromotionTypeBuyXGetY:
+		if req.BuyQuantity <= 0 || req.GetQuantity <= 0 || req.GetDiscountPercent <= 0 || req.GetDiscountPercent > 100 {
+			return nil, fmt.Errorf("buy x get y promotions need buy_quantity, get_quantity and a get_discount_percent between 0 and 100")
+		}
+	case promotion.PromotionTypeBundle:
+		if len(req.ProductIDs) < 2 || !req.BundlePrice.IsPositive() {
+			return nil, fmt.Errorf("bundle promotions need at least two product_ids and a bundle_price")
+		}
+	case promotion.PromotionTypeTieredSpend:
+		if len(req.Tiers) == 0 {
+			return nil, fmt.Errorf("tiered spend promotions need at least one tier")
+		}
+		for _, tier := range req.Tiers {
+			if tier.MinSpend.IsNegative() {
+				return nil, fmt.Errorf("tier min_spend cannot be negative")
+			}
+		}
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && req.EndsAt.Before(*req.StartsAt) {
+		return nil, fmt.Errorf("promotion end date must be after the start date")
+	}
+
+	promo := &model.Promotion{
+		Name:               req.Name,
+		Description:        req.Description,
+		Type:               req.Type,
+		Priority:           req.Priority,
+		Exclusive:          req.Exclusive,
+		BuyQuantity:        req.BuyQuantity,
+		GetQuantity:        req.GetQuantity,
+		GetDiscountPercent: req.GetDiscountPercent,
+		BundlePrice:        req.BundlePrice,
+		StartsAt:           req.StartsAt,
+		EndsAt:             req.EndsAt,
+		IsActive:           true,
+	}
+	for _, productID := range req.ProductIDs {
+		promo.Products = append(promo.Products, model.Product{ProductID: productID})
+	}
+	for _, categoryID := range req.CategoryIDs {
+		promo.Categories = append(promo.Categories, model.Category{CategoryID: categoryID})
+	}
+	for _, tier := range req.Tiers {
+		promo.Tiers = append(promo.Tiers, model.PromotionTier{
+			MinSpend:      tier.MinSpend,
+			DiscountType:  tier.DiscountType,
+			DiscountValue: tier.DiscountValue,
+		})
+	}
+
+	if err := s.PromotionRepository.CreatePromotion(promo); err != nil {
+		logger.ActError("Unable to create promotion", zap.Error(err))
+		return nil, fmt.Errorf("failed to create promotion: %v", err)
+	}
+	return promo, nil
+}
+
+func (s *PromotionServiceImpl) GetAllPromotions() ([]model.Promotion, error) {
+	return s.PromotionRepository.GetAllPromotions()
+}
+
+// NormalizeCouponCode makes coupon codes case-insensitive
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// localizePromotions converts the promotion amounts, which are in the default currency, to the given currency
+func (s *PromotionServiceImpl) localizePromotions(promotions []model.Promotion, currency string) error {
+	for i := range promotions {
+		promo := &promotions[i]
+		bundlePrice, err := s.CurrencyService.Convert(promo.BundlePrice, currency)
+		if err != nil {
+			return err
+		}
+		promo.BundlePrice = bundlePrice
+
+		for j := range promo.Tiers {
+			tier := &promo.Tiers[j]
+			if tier.MinSpend, err = s.CurrencyService.Convert(tier.MinSpend, currency); err != nil {
+				return err
+			}
+			if tier.DiscountType == promotion.DiscountTypeFixed {
+				if tier.DiscountValue, err = s.convertFixedAmount(tier.DiscountValue, currency); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// localizeCoupon converts the coupon amounts, which are in the default currency, to the given currency
+func (s *PromotionServiceImpl) localizeCoupon(coupon *model.Coupon, currency string) error {
+	var err error
+	if coupon.MinOrderValue, err = s.CurrencyService.Convert(coupon.MinOrderValue, currency); err != nil {
+		return err
+	}
+	if coupon.MaxDiscountAmount, err = s.CurrencyService.Convert(coupon.MaxDiscountAmount, currency); err != nil {
+		return err
+	}
+	if coupon.DiscountType == promotion.DiscountTypeFixed {
+		if coupon.DiscountValue, err = s.convertFixedAmount(coupon.DiscountValue, currency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *PromotionServiceImpl) convertFixedAmount(amount float64, currency string) (float64, error) {
+	converted, err := s.CurrencyService.Convert(money.FromFloat(amount, money.DefaultCurrency), currency)
+	if err != nil {
+		return 0, err
+	}
+	return converted.Float64(), nil
+}
+
+// linesCurrency is the currency the lines are priced in
+func linesCurrency(lines []promotion.Line) string {
+	if len(lines) > 0 && lines[0].UnitPrice.Currency != "" {
+		return lines[0].UnitPrice.Currency
+	}
+	return money.DefaultCurrency
+}
+
+// selectedCartLines builds promotion lines from the selected cart items at current product or variant prices,
+// converted to the given currency
+func selectedCartLines(cart *model.Cart, currencyService CurrencyService, currency string) ([]promotion.Line, error) {
+	var lines []promotion.Line
+	for _, item := range cart.Items {
+		if !item.IsSelected {
+			continue
+		}
+		unitPrice, err := currencyService.Convert(item.CurrentPrice(), currency)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, promotion.Line{
+			ItemID:     item.ID,
+			ProductID:  item.ProductID,
+			CategoryID: item.Product.CategoryID,
+			Quantity:   item.Quantity,
+			UnitPrice:  unitPrice,
+		})
+	}
+	return lines, nil
+}
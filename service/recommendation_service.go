@@ -0,0 +1,154 @@
+package service
+
+import (
+	"fmt"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/model"
+	"shophub-backend/recommendation"
+	"shophub-backend/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	RecommendationReasonBoughtTogether     = "frequently_bought_together"
+	RecommendationReasonCategoryBestseller = "category_bestseller"
+)
+
+type RecommendationService interface {
+	RefreshCoPurchases() (int, error)
+	GetRecommendations(productId uint, limit int, currency string) (*data.RecommendationResponse, error)
+}
+
+// RecommendationServiceImpl recommends the products most often bought together with a product,
+// topped up with the bestsellers of its category when there are not enough of them
+type RecommendationServiceImpl struct {
+	RecommendationRepository repository.RecommendationRepository
+	ProductRepository        repository.ProductRepository
+	ProductService           ProductService
+	// WindowDays is the period of order history the recommendations are based on
+	WindowDays int
+	// BasketMinutes is how far apart orders of a customer can be to count as bought together
+	BasketMinutes int
+	// MinPairCount is how often products must be bought together to be recommended
+	MinPairCount int
+	DefaultLimit int
+	MaxLimit     int
+}
+
+func NewRecommendationServiceImpl(RecommendationRepository repository.RecommendationRepository, ProductRepository repository.ProductRepository, ProductService ProductService, WindowDays int, BasketMinutes int, MinPairCount int, DefaultLimit int, MaxLimit int) (service RecommendationService, err error) {
+	return &RecommendationServiceImpl{
+		RecommendationRepository: RecommendationRepository,
+		ProductRepository:        ProductRepository,
+		ProductService:           ProductService,
+		WindowDays:               WindowDays,
+		BasketMinutes:            BasketMinutes,
+		MinPairCount:             MinPairCount,
+		DefaultLimit:             DefaultLimit,
+		MaxLimit:                 MaxLimit,
+	}, err
+}
+
+// RefreshCoPurchases rebuilds the co-purchase pairs from the orders in the window, cancelled orders
+// are left out. It returns the number of pairs stored.
+func (s *RecommendationServiceImpl) RefreshCoPurchases() (int, error) {
+	orders, err := s.RecommendationRepository.GetPurchases(s.since(), OrderStatusCancelled)
+	if err != nil {
+		logger.ActError("Unable to fetch purchases", zap.Error(err))
+		return 0, fmt.Errorf("failed to fetch purchases")
+	}
+
+	purchases := make([]recommendation.Purchase, 0, len(orders))
+	for _, order := range orders {
+		purchases = append(purchases, recommendation.Purchase{
+			UserID:    order.KeycloakUserID,
+			ProductID: order.ProductId,
+			At:        order.CreatedAt,
+		})
+	}
+
+	now := time.Now()
+	pairs := recommendation.CoPurchases(purchases, time.Duration(s.BasketMinutes)*time.Minute, s.MinPairCount)
+	coPurchases := make([]model.CoPurchase, 0, len(pairs))
+	for _, pair := range pairs {
+		coPurchases = append(coPurchases, model.CoPurchase{
+			ProductID:        pair.ProductID,
+			RelatedProductID: pair.RelatedProductID,
+			Count:            pair.Count,
+			ComputedAt:       now,
+		})
+	}
+	if err := s.RecommendationRepository.ReplaceCoPurchases(coPurchases); err != nil {
+		logger.ActError("Unable to store co-purchases", zap.Error(err))
+		return 0, fmt.Errorf("failed to store co-purchases")
+	}
+	logger.ActInfo("Co-purchases refreshed", zap.Int("orders", len(orders)), zap.Int("pairs", len(coPurchases)))
+	return len(coPurchases), nil
+}
+
+// GetRecommendations returns up to limit products for the product page, those most often bought
+// together with the product first, then the bestsellers of its category. A limit of 0 uses the default.
+func (s *RecommendationServiceImpl) GetRecommendations(productId uint, limit int, currency string) (*data.RecommendationResponse, error) {
+	if limit == 0 {
+		limit = s.DefaultLimit
+	}
+	if limit < 1 || limit > s.MaxLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", s.MaxLimit)
+	}
+
+	product, err := s.ProductRepository.GetProductById(productId)
+	if err != nil || !product.IsAvailable(time.Now()) {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	pairs, err := s.RecommendationRepository.GetCoPurchases(productId, limit)
+	if err != nil {
+		logger.ActError("Unable to fetch co-purchases", zap.Uint("product_id", productId), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch recommendations")
+	}
+	ids := make([]uint, 0, limit)
+	reasons := map[uint]string{}
+	counts := map[uint]int{}
+	for _, pair := range pairs {
+		ids = append(ids, pair.RelatedProductID)
+		reasons[pair.RelatedProductID] = RecommendationReasonBoughtTogether
+		counts[pair.RelatedProductID] = pair.Count
+	}
+
+	if len(ids) < limit {
+		excluded := append([]uint{productId}, ids...)
+		bestsellers, err := s.RecommendationRepository.GetCategoryBestsellers(product.CategoryID, s.since(), OrderStatusCancelled, excluded, limit-len(ids))
+		if err != nil {
+			logger.ActError("Unable to fetch category bestsellers", zap.Uint("category_id", product.CategoryID), zap.Error(err))
+			return nil, fmt.Errorf("failed to fetch recommendations")
+		}
+		for _, bestseller := range bestsellers {
+			ids = append(ids, bestseller.ProductID)
+			reasons[bestseller.ProductID] = RecommendationReasonCategoryBestseller
+			counts[bestseller.ProductID] = bestseller.Quantity
+		}
+	}
+
+	products, err := s.ProductService.GetProductsByIds(ids, currency)
+	if err != nil {
+		return nil, err
+	}
+	response := &data.RecommendationResponse{
+		ProductID:       productId,
+		Recommendations: make([]data.Recommendation, 0, len(products)),
+	}
+	for _, recommended := range products {
+		response.Recommendations = append(response.Recommendations, data.Recommendation{
+			ProductResponse: recommended,
+			Reason:          reasons[recommended.ProductID],
+			Count:           counts[recommended.ProductID],
+		})
+	}
+	return response, nil
+}
+
+func (s *RecommendationServiceImpl) since() time.Time {
+	return time.Now().AddDate(0, 0, -s.WindowDays)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"fmt"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/model"
+	"shophub-backend/repository"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type ReviewService interface {
+	CreateReview(keycloakUserID string, productId uint, req data.CreateReviewRequest) (*model.Review, error)
+	GetProductReviews(productId uint) ([]model.Review, error)
+	GetReviews(status string) ([]model.Review, error)
+	ModerateReview(reviewId uint, req data.ModerateReviewRequest) (*model.Review, error)
+}
+
+type ReviewServiceImpl struct {
+	ReviewRepository  repository.ReviewRepository
+	ProductRepository repository.ProductRepository
+	OrderRepository   repository.OrderRepository
+}
+
+func NewReviewServiceImpl(ReviewRepository repository.ReviewRepository, ProductRepository repository.ProductRepository, OrderRepository repository.OrderRepository) (service ReviewService, err error) {
+	return &ReviewServiceImpl{
+		ReviewRepository:  ReviewRepository,
+		ProductRepository: ProductRepository,
+		OrderRepository:   OrderRepository,
+	}, err
+}
+
+// CreateReview submits a review for moderation. Only customers with a delivered order of the product
+// can review it, once per product.
+func (s *ReviewServiceImpl) CreateReview(keycloakUserID string, productId uint, req data.CreateReviewRequest) (*model.Review, error) {
+	if product, err := s.ProductRepository.GetProductById(productId); err != nil || !product.IsAvailable(time.Now()) {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	delivered, err := s.OrderRepository.HasOrderWithStatus(keycloakUserID, productId, OrderStatusDelivered)
+	if err != nil {
+		logger.ActError("Unable to check the orders of the user", zap.Uint("product_id", productId), zap.Error(err))
+		return nil, fmt.Errorf("failed to check orders")
+	}
+	if !delivered {
+		return nil, fmt.Errorf("only customers who received the product can review it")
+	}
+	if existing, err := s.ReviewRepository.GetUserReview(productId, keycloakUserID); err == nil && existing != nil {
+		return nil, fmt.Errorf("you have already reviewed this product")
+	}
+
+	review := &model.Review{
+		ProductID:      productId,
+		KeycloakUserID: keycloakUserID,
+		Rating:         req.Rating,
+		Title:          strings.TrimSpace(req.Title),
+		Body:           strings.TrimSpace(req.Body),
+		Status:         model.ReviewStatusPending,
+	}
+	if review.Title == "" || review.Body == "" {
+		return nil, fmt.Errorf("title and body must not be blank")
+	}
+	if err := s.ReviewRepository.CreateReview(review); err != nil {
+		logger.ActError("Unable to create review", zap.Uint("product_id", productId), zap.Error(err))
+		return nil, fmt.Errorf("failed to create review")
+	}
+	return review, nil
+}
+
+// GetProductReviews returns the published reviews of the product
+func (s *ReviewServiceImpl) GetProductReviews(productId uint) ([]model.Review, error) {
+	if product, err := s.ProductRepository.GetProductById(productId); err != nil || !product.IsAvailable(time.Now()) {
+		return nil, fmt.Errorf("product not found")
+	}
+	reviews, err := s.ReviewRepository.GetReviewsByProduct(productId, model.ReviewStatusApproved)
+	if err != nil {
+		logger.ActError("Unable to fetch reviews", zap.Uint("product_id", productId), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch reviews")
+	}
+	return reviews, nil
+}
+
+// GetReviews returns the reviews with the status for moderation, pending reviews by default
+func (s *ReviewServiceImpl) GetReviews(status string) ([]model.Review, error) {
+	status = strings.ToUpper(strings.TrimSpace(status))
+	if status == "" {
+		status = model.ReviewStatusPending
+	}
+	switch status {
+	case model.ReviewStatusPending, model.ReviewStatusApproved, model.ReviewStatusRejected:
+	default:
+		return nil, fmt.Errorf("status must be one of %s, %s or %s", model.ReviewStatusPending, model.ReviewStatusApproved, model.ReviewStatusRejected)
+	}
+
+	reviews, err := s.ReviewRepository.GetReviewsByStatus(status)
+	if err != nil {
+		logger.ActError("Unable to fetch reviews", zap.String("status", status), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch reviews")
+	}
+	return reviews, nil
+}
+
+// ModerateReview approves or rejects the review, the rating of the product follows its approved reviews
+func (s *ReviewServiceImpl) ModerateReview(reviewId uint, req data.ModerateReviewRequest) (*model.Review, error) {
+	if _, err := s.ReviewRepository.GetReviewById(reviewId); err != nil {
+		return nil, fmt.Errorf("review not found")
+	}
+	if err := s.ReviewRepository.UpdateReviewStatus(reviewId, req.Status); err != nil {
+		logger.ActError("Unable to update review status", zap.Uint("review_id", reviewId), zap.Error(err))
+		return nil, fmt.Errorf("failed to update review status")
+	}
+	return s.ReviewRepository.GetReviewById(reviewId)
+}
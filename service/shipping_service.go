@@ -0,0 +1,213 @@
+package service
+
+import (
+	"fmt"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/model"
+	"shophub-backend/money"
+	"shophub-backend/postal"
+	"shophub-backend/promotion"
+	"shophub-backend/repository"
+	"shophub-backend/shipping"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+type ShippingService interface {
+	GetShippingOptions(keycloakUserID string, addressId *uint, country string, currency string) (*data.ShippingOptionsResponse, error)
+	ShippingOptions(country string, parcel shipping.Parcel) ([]shipping.Option, error)
+	CreateZone(req data.CreateShippingZoneRequest) (*model.ShippingZone, error)
+	CreateMethod(zoneId uint, req data.CreateShippingMethodRequest) (*model.ShippingMethod, error)
+	GetAllZones() ([]model.ShippingZone, error)
+}
+
+type ShippingServiceImpl struct {
+	ShippingRepository repository.ShippingRepository
+	CartRepository     repository.CartRepository
+	AddressRepository  repository.AddressRepository
+	PromotionService   PromotionService
+	CurrencyService    CurrencyService
+}
+
+func NewShippingServiceImpl(
+	ShippingRepository repository.ShippingRepository,
+	CartRepository repository.CartRepository,
+	AddressRepository repository.AddressRepository,
+	PromotionService PromotionService,
+	CurrencyService CurrencyService,
+) (service ShippingService, err error) {
+	return &ShippingServiceImpl{
+		ShippingRepository: ShippingRepository,
+		CartRepository:     CartRepository,
+		AddressRepository:  AddressRepository,
+		PromotionService:   PromotionService,
+		CurrencyService:    CurrencyService,
+	}, err
+}
+
+// GetShippingOptions quotes the shipping methods for the selected cart items, shipped to a
+// saved address of the user or to the given country
+func (s *ShippingServiceImpl) GetShippingOptions(keycloakUserID string, addressId *uint, country string, currency string) (*data.ShippingOptionsResponse, error) {
+	currency, err := s.CurrencyService.ResolveCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if addressId != nil {
+		address, err := s.AddressRepository.GetAddressById(*addressId)
+		if err != nil || address.KeycloakUserID != keycloakUserID {
+			return nil, fmt.Errorf("address not found")
+		}
+		country = address.Country
+	}
+	if strings.TrimSpace(country) == "" {
+		return nil, fmt.Errorf("a shipping address or country is required")
+	}
+
+	cart, err := s.CartRepository.GetOrCreateCart(keycloakUserID)
+	if err != nil {
+		logger.ActError("Error getting or creating cart")
+		return nil, fmt.Errorf("failed to get or create cart")
+	}
+
+	lines, err := selectedCartLines(cart, s.CurrencyService, currency)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("no items selected for checkout")
+	}
+	pricing, err := s.PromotionService.PriceLines(keycloakUserID, lines, cart.CouponCode)
+	if err != nil {
+		return nil, err
+	}
+
+	parcel := cartParcel(cart, pricing)
+	options, err := s.ShippingOptions(country, parcel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.ShippingOptionsResponse{
+		AddressID: addressId,
+		Country:   country,
+		Currency:  currency,
+		WeightKg:  parcel.WeightKg,
+		Subtotal:  parcel.Subtotal,
+		Options:   options,
+	}, nil
+}
+
+// ShippingOptions returns the methods that can ship the parcel to the country, in the parcel's currency
+func (s *ShippingServiceImpl) ShippingOptions(country string, parcel shipping.Parcel) ([]shipping.Option, error) {
+	zones, err := s.ShippingRepository.GetAllZones()
+	if err != nil {
+		logger.ActError("Unable to load shipping zones", zap.Error(err))
+		return nil, fmt.Errorf("failed to load shipping methods")
+	}
+
+	zone := shipping.ZoneFor(zones, postal.NormalizeCountry(country))
+	if zone == nil {
+		return []shipping.Option{}, nil
+	}
+
+	// Method amounts are in the default currency
+	for i := range zone.Methods {
+		method := &zone.Methods[i]
+		for _, amount := range []*money.Money{&method.BaseRate, &method.RatePerKg, &method.FreeThreshold} {
+			converted, err := s.CurrencyService.Convert(*amount, parcel.Subtotal.Currency)
+			if err != nil {
+				return nil, err
+			}
+			*amount = converted
+		}
+	}
+	return shipping.Options(zone, parcel), nil
+}
+
+func (s *ShippingServiceImpl) CreateZone(req data.CreateShippingZoneRequest) (*model.ShippingZone, error) {
+	zone := &model.ShippingZone{
+		Name:      strings.TrimSpace(req.Name),
+		IsDefault: req.IsDefault,
+	}
+	for _, country := range req.Countries {
+		if country = strings.TrimSpace(country); country == "" {
+			continue
+		}
+		match, ok := postal.LookupCountry(country)
+		if !ok {
+			return nil, fmt.Errorf("unknown country %q, use an ISO 3166 country code", country)
+		}
+		zone.Countries = append(zone.Countries, model.ShippingZoneCountry{Country: match.Code})
+	}
+	if len(zone.Countries) == 0 && !zone.IsDefault {
+		return nil, fmt.Errorf("a shipping zone needs at least one country unless it is the default zone")
+	}
+
+	if err := s.ShippingRepository.CreateZone(zone); err != nil {
+		logger.ActError("Unable to create shipping zone", zap.Error(err))
+		return nil, fmt.Errorf("failed to create shipping zone: %v", err)
+	}
+	return zone, nil
+}
+
+func (s *ShippingServiceImpl) CreateMethod(zoneId uint, req data.CreateShippingMethodRequest) (*model.ShippingMethod, error) {
+	if _, err := s.ShippingRepository.GetZoneById(zoneId); err != nil {
+		return nil, fmt.Errorf("shipping zone not found")
+	}
+
+	switch req.Type {
+	case shipping.MethodTypeWeightBased:
+		if !req.RatePerKg.IsPositive() {
+			return nil, fmt.Errorf("weight based methods need a rate_per_kg")
+		}
+	case shipping.MethodTypeFreeOverThreshold:
+		if !req.FreeThreshold.IsPositive() {
+			return nil, fmt.Errorf("free over threshold methods need a free_threshold")
+		}
+	}
+	if req.BaseRate.IsNegative() || req.RatePerKg.IsNegative() || req.FreeThreshold.IsNegative() {
+		return nil, fmt.Errorf("shipping rates cannot be negative")
+	}
+	if req.MaxDeliveryDays < req.MinDeliveryDays {
+		return nil, fmt.Errorf("max_delivery_days cannot be less than min_delivery_days")
+	}
+
+	method := &model.ShippingMethod{
+		ShippingZoneID:  zoneId,
+		Name:            strings.TrimSpace(req.Name),
+		Type:            req.Type,
+		BaseRate:        req.BaseRate,
+		RatePerKg:       req.RatePerKg,
+		FreeThreshold:   req.FreeThreshold,
+		MaxWeightKg:     req.MaxWeightKg,
+		MinDeliveryDays: req.MinDeliveryDays,
+		MaxDeliveryDays: req.MaxDeliveryDays,
+		IsActive:        true,
+	}
+	if err := s.ShippingRepository.CreateMethod(method); err != nil {
+		logger.ActError("Unable to create shipping method", zap.Error(err))
+		return nil, fmt.Errorf("failed to create shipping method: %v", err)
+	}
+	return method, nil
+}
+
+func (s *ShippingServiceImpl) GetAllZones() ([]model.ShippingZone, error) {
+	return s.ShippingRepository.GetAllZones()
+}
+
+// cartParcel is the parcel for the selected cart items, valued after the promotion and coupon discounts
+func cartParcel(cart *model.Cart, pricing *promotion.Pricing) shipping.Parcel {
+	weight := 0.0
+	for _, item := range cart.Items {
+		if item.IsSelected {
+			weight += item.Product.WeightKg * float64(item.Quantity)
+		}
+	}
+	return shipping.Parcel{
+		WeightKg: weight,
+		Subtotal: pricing.Subtotal.Sub(pricing.DiscountAmount),
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"fmt"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/model"
+	"shophub-backend/notification"
+	"shophub-backend/repository"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	NotificationTypeLowStock   = "low_stock"
+	NotificationTypeOutOfStock = "out_of_stock"
+)
+
+type StockAlertService interface {
+	CheckStock() (int, error)
+	GetLowStockReport(windowDays int) (*data.LowStockReport, error)
+	SetReorderThreshold(productId uint, req data.UpdateReorderThresholdRequest) (*model.Product, error)
+}
+
+// StockAlertServiceImpl watches the available stock of the products against their reorder threshold
+// and notifies merchandisers when a product runs low or out of stock
+type StockAlertServiceImpl struct {
+	ProductRepository       repository.ProductRepository
+	StockMovementRepository repository.StockMovementRepository
+	InventoryService        InventoryService
+	Notifier                notification.Notifier
+	// DefaultThreshold applies to products without their own reorder threshold
+	DefaultThreshold int
+	// WindowDays is the period sales velocity is measured over
+	WindowDays int
+	Recipient  string
+}
+
+func NewStockAlertServiceImpl(ProductRepository repository.ProductRepository, StockMovementRepository repository.StockMovementRepository, InventoryService InventoryService, Notifier notification.Notifier, DefaultThreshold int, WindowDays int, Recipient string) (service StockAlertService, err error) {
+	return &StockAlertServiceImpl{
+		ProductRepository:       ProductRepository,
+		StockMovementRepository: StockMovementRepository,
+		InventoryService:        InventoryService,
+		Notifier:                Notifier,
+		DefaultThreshold:        DefaultThreshold,
+		WindowDays:              WindowDays,
+		Recipient:               Recipient,
+	}, err
+}
+
+// CheckStock notifies about every product that went low or out of stock since the last check,
+// products are alerted again only after their state changes. It returns how many alerts were sent.
+func (s *StockAlertServiceImpl) CheckStock() (int, error) {
+	products, available, err := s.loadStock()
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, product := range products {
+		alert := s.alertFor(product, available[product.ProductID])
+		if alert == product.StockAlert {
+			continue
+		}
+
+		if alert != model.StockAlertNone {
+			if err := s.notify(product, alert, available[product.ProductID]); err != nil {
+				logger.ActError("Unable to send stock alert", zap.Uint("product_id", product.ProductID), zap.Error(err))
+				continue
+			}
+			sent++
+		}
+		if err := s.ProductRepository.UpdateStockAlert(product.ProductID, alert); err != nil {
+			logger.ActError("Unable to save the stock alert", zap.Uint("product_id", product.ProductID), zap.Error(err))
+		}
+	}
+
+	if sent > 0 {
+		logger.ActInfo("Stock alerts sent", zap.Int("count", sent))
+	}
+	return sent, nil
+}
+
+// GetLowStockReport lists the products at or below their reorder threshold, the fastest selling first,
+// using the configured window when windowDays is zero
+func (s *StockAlertServiceImpl) GetLowStockReport(windowDays int) (*data.LowStockReport, error) {
+	if windowDays <= 0 {
+		windowDays = s.WindowDays
+	}
+
+	products, available, err := s.loadStock()
+	if err != nil {
+		return nil, err
+	}
+	sold, err := s.StockMovementRepository.GetUnitsSold(time.Now().AddDate(0, 0, -windowDays))
+	if err != nil {
+		logger.ActError("Unable to load units sold", zap.Error(err))
+		return nil, fmt.Errorf("failed to load units sold")
+	}
+
+	report := &data.LowStockReport{
+		WindowDays:  windowDays,
+		GeneratedAt: time.Now(),
+		Products:    []data.LowStockProduct{},
+	}
+	for _, product := range products {
+		stock := available[product.ProductID]
+		alert := s.alertFor(product, stock)
+		if alert == model.StockAlertNone {
+			continue
+		}
+
+		velocity := float64(sold[product.ProductID]) / float64(windowDays)
+		entry := data.LowStockProduct{
+			ProductID:        product.ProductID,
+			ProductName:      product.ProductName,
+			ProductSlug:      product.ProductSlug,
+			AvailableStock:   stock,
+			ReorderThreshold: s.thresholdFor(product),
+			Status:           alert,
+			UnitsSold:        sold[product.ProductID],
+			SalesVelocity:    velocity,
+		}
+		if velocity > 0 {
+			cover := float64(max(stock, 0)) / velocity
+			entry.DaysOfCover = &cover
+		}
+		report.Products = append(report.Products, entry)
+	}
+
+	sort.SliceStable(report.Products, func(i, j int) bool {
+		if report.Products[i].SalesVelocity != report.Products[j].SalesVelocity {
+			return report.Products[i].SalesVelocity > report.Products[j].SalesVelocity
+		}
+		return report.Products[i].AvailableStock < report.Products[j].AvailableStock
+	})
+	return report, nil
+}
+
+func (s *StockAlertServiceImpl) SetReorderThreshold(productId uint, req data.UpdateReorderThresholdRequest) (*model.Product, error) {
+	product, err := s.ProductRepository.GetProductById(productId)
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+	if err := s.ProductRepository.UpdateReorderThreshold(productId, req.ReorderThreshold); err != nil {
+		logger.ActError("Unable to update the reorder threshold", zap.Uint("product_id", productId), zap.Error(err))
+		return nil, fmt.Errorf("failed to update reorder threshold")
+	}
+	product.ReorderThreshold = req.ReorderThreshold
+	return product, nil
+}
+
+func (s *StockAlertServiceImpl) loadStock() ([]model.Product, map[uint]int, error) {
+	products, err := s.ProductRepository.GetAllProducts()
+	if err != nil {
+		logger.ActError("Unable to load products", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to load products")
+	}
+	available, err := s.InventoryService.AvailableStock(products)
+	if err != nil {
+		return nil, nil, err
+	}
+	return products, available, nil
+}
+
+func (s *StockAlertServiceImpl) thresholdFor(product model.Product) int {
+	if product.ReorderThreshold != nil {
+		return *product.ReorderThreshold
+	}
+	return s.DefaultThreshold
+}
+
+func (s *StockAlertServiceImpl) alertFor(product model.Product, available int) string {
+	switch {
+	case available <= 0:
+		return model.StockAlertOutOfStock
+	case available <= s.thresholdFor(product):
+		return model.StockAlertLowStock
+	default:
+		return model.StockAlertNone
+	}
+}
+
+func (s *StockAlertServiceImpl) notify(product model.Product, alert string, available int) error {
+	notificationType := NotificationTypeLowStock
+	subject := fmt.Sprintf("%s is low on stock", product.ProductName)
+	message := fmt.Sprintf("%s has %d unit(s) left, at or below the reorder threshold of %d.", product.ProductName, available, s.thresholdFor(product))
+	if alert == model.StockAlertOutOfStock {
+		notificationType = NotificationTypeOutOfStock
+		subject = fmt.Sprintf("%s is out of stock", product.ProductName)
+		message = fmt.Sprintf("%s has no stock left to sell.", product.ProductName)
+	}
+
+	return s.Notifier.Notify(notification.Notification{
+		Type:      notificationType,
+		Recipient: s.Recipient,
+		Subject:   subject,
+		Message:   message,
+		Data: map[string]interface{}{
+			"product_id":        product.ProductID,
+			"product_slug":      product.ProductSlug,
+			"available_stock":   available,
+			"reorder_threshold": s.thresholdFor(product),
+		},
+		CreatedAt: time.Now(),
+	})
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"fmt"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/model"
+	"shophub-backend/postal"
+	"shophub-backend/promotion"
+	"shophub-backend/repository"
+	"shophub-backend/tax"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+type TaxService interface {
+	CalculateTax(address tax.Address, lines []promotion.Line, pricing *promotion.Pricing) (*tax.Result, error)
+	CreateTaxRate(req data.CreateTaxRateRequest) (*model.TaxRate, error)
+	GetAllTaxRates() ([]model.TaxRate, error)
+}
+
+type TaxServiceImpl struct {
+	TaxRepository repository.TaxRepository
+}
+
+func NewTaxServiceImpl(TaxRepository repository.TaxRepository) (service TaxService, err error) {
+	return &TaxServiceImpl{
+		TaxRepository: TaxRepository,
+	}, err
+}
+
+// CalculateTax taxes the priced lines for the shipping address, on what is left after the discounts
+func (s *TaxServiceImpl) CalculateTax(address tax.Address, lines []promotion.Line, pricing *promotion.Pricing) (*tax.Result, error) {
+	// Rates are stored with country and region codes, addresses saved before validation may use names
+	normalized := postal.Normalize(postal.Address{Country: address.Country, Region: address.Region})
+	address = tax.Address{Country: normalized.Country, Region: normalized.Region}
+
+	rates, err := s.TaxRepository.GetActiveTaxRates(address.Country)
+	if err != nil {
+		logger.ActError("Unable to load tax rates", zap.Error(err))
+		return nil, fmt.Errorf("failed to load tax rates")
+	}
+
+	taxable := make([]tax.Line, 0, len(lines))
+	for _, line := range lines {
+		taxable = append(taxable, tax.Line{
+			ItemID:     line.ItemID,
+			CategoryID: line.CategoryID,
+			Amount:     line.Total().Sub(pricing.LineDiscounts[line.ItemID]),
+		})
+	}
+
+	var calculator tax.TaxCalculator = tax.NewTableCalculator(rates)
+	return calculator.Calculate(address, taxable)
+}
+
+func (s *TaxServiceImpl) CreateTaxRate(req data.CreateTaxRateRequest) (*model.TaxRate, error) {
+	if _, ok := postal.LookupCountry(req.Country); !ok {
+		return nil, fmt.Errorf("unknown country %q, use an ISO 3166 country code", req.Country)
+	}
+	location := postal.Normalize(postal.Address{Country: req.Country, Region: req.Region})
+
+	rate := &model.TaxRate{
+		Name:       strings.TrimSpace(req.Name),
+		Country:    location.Country,
+		Region:     location.Region,
+		CategoryID: req.CategoryID,
+		Rate:       req.Rate,
+		Inclusive:  req.Inclusive,
+		IsActive:   true,
+	}
+	if err := s.TaxRepository.CreateTaxRate(rate); err != nil {
+		logger.ActError("Unable to create tax rate", zap.Error(err))
+		return nil, fmt.Errorf("failed to create tax rate: %v", err)
+	}
+	return rate, nil
+}
+
+func (s *TaxServiceImpl) GetAllTaxRates() ([]model.TaxRate, error) {
+	return s.TaxRepository.GetAllTaxRates()
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"fmt"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/model"
+	"shophub-backend/money"
+	"shophub-backend/repository"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+type VariantService interface {
+	CreateOption(productId uint, req data.CreateProductOptionRequest) (*model.ProductOption, error)
+	CreateVariant(productId uint, req data.CreateVariantRequest) (*model.ProductVariant, error)
+	UpdateVariant(productId uint, variantId uint, req data.UpdateVariantRequest) (*model.ProductVariant, error)
+}
+
+type VariantServiceImpl struct {
+	VariantRepository repository.VariantRepository
+	ProductRepository repository.ProductRepository
+}
+
+func NewVariantServiceImpl(VariantRepository repository.VariantRepository, ProductRepository repository.ProductRepository) (service VariantService, err error) {
+	return &VariantServiceImpl{
+		VariantRepository: VariantRepository,
+		ProductRepository: ProductRepository,
+	}, err
+}
+
+// CreateOption adds an option to the product. Options can only be added before the product has variants,
+// as every variant needs a value of each option.
+func (s *VariantServiceImpl) CreateOption(productId uint, req data.CreateProductOptionRequest) (*model.ProductOption, error) {
+	product, err := s.ProductRepository.GetProductById(productId)
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+	if product.HasVariants() {
+		return nil, fmt.Errorf("options cannot be added once the product has variants")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	for _, existing := range product.Options {
+		if strings.EqualFold(existing.Name, name) {
+			return nil, fmt.Errorf("the product already has an option named %s", existing.Name)
+		}
+	}
+
+	option := &model.ProductOption{
+		ProductID: productId,
+		Name:      name,
+		Position:  len(product.Options),
+	}
+	seen := map[string]bool{}
+	for _, value := range req.Values {
+		value = strings.TrimSpace(value)
+		if seen[strings.ToLower(value)] {
+			return nil, fmt.Errorf("option value %s is listed more than once", value)
+		}
+		seen[strings.ToLower(value)] = true
+		option.Values = append(option.Values, model.ProductOptionValue{Value: value, Position: len(option.Values)})
+	}
+
+	if err := s.VariantRepository.CreateOption(option); err != nil {
+		logger.ActError("Unable to create product option", zap.Uint("product_id", productId), zap.Error(err))
+		return nil, fmt.Errorf("failed to create option")
+	}
+	return option, nil
+}
+
+// CreateVariant adds a variant for a combination of option values that the product does not sell yet
+func (s *VariantServiceImpl) CreateVariant(productId uint, req data.CreateVariantRequest) (*model.ProductVariant, error) {
+	product, err := s.ProductRepository.GetProductById(productId)
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+	if len(product.Options) == 0 {
+		return nil, fmt.Errorf("the product has no options, add its options before its variants")
+	}
+
+	values, err := variantValues(product, req.OptionValueIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range product.Variants {
+		if sameValues(existing.OptionValues, values) {
+			return nil, fmt.Errorf("variant %s already exists for these option values", existing.SKU)
+		}
+	}
+
+	variant := &model.ProductVariant{
+		ProductID:    productId,
+		IsActive:     true,
+		OptionValues: values,
+	}
+	if req.IsActive != nil {
+		variant.IsActive = *req.IsActive
+	}
+	if err := s.applyVariantFields(variant, req.SKU, req.PriceOverride, req.ImageURLs); err != nil {
+		return nil, err
+	}
+
+	if err := s.VariantRepository.CreateVariant(variant); err != nil {
+		logger.ActError("Unable to create product variant", zap.Uint("product_id", productId), zap.Error(err))
+		return nil, fmt.Errorf("failed to create variant")
+	}
+	return s.VariantRepository.GetVariantById(variant.VariantID)
+}
+
+func (s *VariantServiceImpl) UpdateVariant(productId uint, variantId uint, req data.UpdateVariantRequest) (*model.ProductVariant, error) {
+	variant, err := s.VariantRepository.GetVariantById(variantId)
+	if err != nil || variant.ProductID != productId {
+		return nil, fmt.Errorf("variant not found")
+	}
+
+	if req.IsActive != nil {
+		variant.IsActive = *req.IsActive
+	}
+	if err := s.applyVariantFields(variant, req.SKU, req.PriceOverride, req.ImageURLs); err != nil {
+		return nil, err
+	}
+
+	if err := s.VariantRepository.UpdateVariant(variant); err != nil {
+		logger.ActError("Unable to update product variant", zap.Uint("variant_id", variantId), zap.Error(err))
+		return nil, fmt.Errorf("failed to update variant")
+	}
+	return s.VariantRepository.GetVariantById(variantId)
+}
+
+// applyVariantFields sets the fields a variant is created and updated with, the SKU must be unique
+func (s *VariantServiceImpl) applyVariantFields(variant *model.ProductVariant, sku string, priceOverride *money.Money, imageURLs []string) error {
+	sku = strings.ToUpper(strings.TrimSpace(sku))
+	if existing, err := s.VariantRepository.GetVariantBySKU(sku); err == nil && existing.VariantID != variant.VariantID {
+		return fmt.Errorf("sku %s is already in use", sku)
+	}
+	if priceOverride != nil && !priceOverride.IsPositive() {
+		return fmt.Errorf("price override must be positive")
+	}
+
+	variant.SKU = sku
+	variant.PriceOverride = priceOverride
+	variant.Images = nil
+	for _, url := range imageURLs {
+		variant.Images = append(variant.Images, model.ProductVariantImage{
+			ImageURL: strings.TrimSpace(url),
+			Position: len(variant.Images),
+		})
+	}
+	return nil
+}
+
+// variantValues looks up the option values of a new variant, one value of each of the product's options
+func variantValues(product *model.Product, valueIds []uint) ([]model.ProductOptionValue, error) {
+	byId := map[uint]model.ProductOptionValue{}
+	for _, option := range product.Options {
+		for _, value := range option.Values {
+			byId[value.ValueID] = value
+		}
+	}
+
+	chosen := map[uint]bool{}
+	var values []model.ProductOptionValue
+	for _, valueId := range valueIds {
+		value, ok := byId[valueId]
+		if !ok {
+			return nil, fmt.Errorf("option value %d is not an option value of this product", valueId)
+		}
+		if chosen[value.OptionID] {
+			return nil, fmt.Errorf("a variant has one value of each option, option %d is given more than once", value.OptionID)
+		}
+		chosen[value.OptionID] = true
+		values = append(values, value)
+	}
+	for _, option := range product.Options {
+		if !chosen[option.OptionID] {
+			return nil, fmt.Errorf("a value for option %s is required", option.Name)
+		}
+	}
+	return values, nil
+}
+
+func sameValues(a []model.ProductOptionValue, b []model.ProductOptionValue) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	ids := map[uint]bool{}
+	for _, value := range a {
+		ids[value.ValueID] = true
+	}
+	for _, value := range b {
+		if !ids[value.ValueID] {
+			return false
+		}
+	}
+	return true
+}
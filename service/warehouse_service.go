@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"shophub-backend/data"
+	"shophub-backend/logger"
+	"shophub-backend/model"
+	"shophub-backend/postal"
+	"shophub-backend/repository"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+type WarehouseService interface {
+	CreateWarehouse(req data.CreateWarehouseRequest) (*model.Warehouse, error)
+	GetAllWarehouses() ([]model.Warehouse, error)
+	GetWarehouseStock(warehouseId uint) ([]model.StockLevel, error)
+	AdjustStock(warehouseId uint, productId uint, actor string, req data.AdjustStockRequest) (*model.StockLevel, error)
+}
+
+type WarehouseServiceImpl struct {
+	WarehouseRepository repository.WarehouseRepository
+	ProductRepository   repository.ProductRepository
+}
+
+func NewWarehouseServiceImpl(WarehouseRepository repository.WarehouseRepository, ProductRepository repository.ProductRepository) (service WarehouseService, err error) {
+	return &WarehouseServiceImpl{
+		WarehouseRepository: WarehouseRepository,
+		ProductRepository:   ProductRepository,
+	}, err
+}
+
+func (s *WarehouseServiceImpl) CreateWarehouse(req data.CreateWarehouseRequest) (*model.Warehouse, error) {
+	warehouse := &model.Warehouse{
+		Code:     strings.ToUpper(strings.TrimSpace(req.Code)),
+		Name:     strings.TrimSpace(req.Name),
+		IsActive: true,
+	}
+	if req.IsActive != nil {
+		warehouse.IsActive = *req.IsActive
+	}
+	if strings.TrimSpace(req.Country) != "" {
+		country, ok := postal.LookupCountry(req.Country)
+		if !ok {
+			return nil, fmt.Errorf("unknown country %q, use an ISO 3166 country code", req.Country)
+		}
+		warehouse.Country = country.Code
+	}
+
+	if err := s.WarehouseRepository.CreateWarehouse(warehouse); err != nil {
+		logger.ActError("Unable to create warehouse", zap.Error(err))
+		return nil, fmt.Errorf("failed to create warehouse: %v", err)
+	}
+	return warehouse, nil
+}
+
+func (s *WarehouseServiceImpl) GetAllWarehouses() ([]model.Warehouse, error) {
+	return s.WarehouseRepository.GetAllWarehouses()
+}
+
+func (s *WarehouseServiceImpl) GetWarehouseStock(warehouseId uint) ([]model.StockLevel, error) {
+	if _, err := s.WarehouseRepository.GetWarehouseById(warehouseId); err != nil {
+		return nil, fmt.Errorf("warehouse not found")
+	}
+	return s.WarehouseRepository.GetStockLevels(warehouseId)
+}
+
+// AdjustStock adds the delta to the stock of the product in the warehouse, a negative delta removes stock.
+// The change is recorded in the stock ledger under the actor's name.
+func (s *WarehouseServiceImpl) AdjustStock(warehouseId uint, productId uint, actor string, req data.AdjustStockRequest) (*model.StockLevel, error) {
+	if req.Delta == 0 {
+		return nil, fmt.Errorf("delta must not be zero")
+	}
+	if _, err := s.WarehouseRepository.GetWarehouseById(warehouseId); err != nil {
+		return nil, fmt.Errorf("warehouse not found")
+	}
+	product, err := s.ProductRepository.GetProductById(productId)
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+	// The stock of a product sold as variants is kept per variant
+	if product.HasVariants() && req.VariantID == 0 {
+		return nil, fmt.Errorf("variant_id is required, the product is sold as variants")
+	}
+	if req.VariantID != 0 && product.Variant(req.VariantID) == nil {
+		return nil, fmt.Errorf("variant not found")
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = model.StockMovementAdjustment
+	}
+	level, err := s.WarehouseRepository.AdjustStock(model.StockMovement{
+		ProductID:   productId,
+		VariantID:   req.VariantID,
+		WarehouseID: &warehouseId,
+		Quantity:    req.Delta,
+		Reason:      reason,
+		Actor:       actor,
+		Reference:   strings.TrimSpace(req.Reference),
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrStockBelowReserved) {
+			return nil, err
+		}
+		logger.ActError("Unable to adjust stock", zap.Error(err))
+		return nil, fmt.Errorf("failed to adjust stock: %v", err)
+	}
+
+	logger.ActInfo("Stock adjusted", zap.Uint("warehouse_id", warehouseId), zap.Uint("product_id", productId), zap.Uint("variant_id", req.VariantID), zap.Int("delta", req.Delta), zap.String("reason", reason))
+	return level, nil
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"fmt"
+	"shophub-backend/logger"
+	"shophub-backend/model"
+	"shophub-backend/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const DefaultWishlistName = "Saved for later"
+
+type WishlistService interface {
+	GetUserWishlists(keycloakUserID string) ([]model.Wishlist, error)
+	CreateWishlist(keycloakUserID string, name string) (*model.Wishlist, error)
+	DeleteWishlist(keycloakUserID string, wishlistId uint) error
+	AddToWishlist(keycloakUserID string, wishlistId uint, productID uint) error
+	RemoveFromWishlist(keycloakUserID string, wishlistId uint, itemId uint) error
+	MoveToCart(keycloakUserID string, wishlistId uint, itemId uint) error
+	SaveForLater(keycloakUserID string, cartItemId uint, wishlistId uint) error
+}
+
+type WishlistServiceImpl struct {
+	WishlistRepository repository.WishlistRepository
+	CartRepository     repository.CartRepository
+	ProductRepository  repository.ProductRepository
+	InventoryService   InventoryService
+}
+
+func NewWishlistServiceImpl(WishlistRepository repository.WishlistRepository, CartRepository repository.CartRepository, ProductRepository repository.ProductRepository, InventoryService InventoryService) (service WishlistService, err error) {
+	return &WishlistServiceImpl{
+		WishlistRepository: WishlistRepository,
+		CartRepository:     CartRepository,
+		ProductRepository:  ProductRepository,
+		InventoryService:   InventoryService,
+	}, err
+}
+
+// GetUserWishlists returns every wishlist of the user, creating the default list on first use
+func (s *WishlistServiceImpl) GetUserWishlists(keycloakUserID string) ([]model.Wishlist, error) {
+	if _, err := s.getOrCreateDefaultWishlist(keycloakUserID); err != nil {
+		return nil, err
+	}
+
+	wishlists, err := s.WishlistRepository.GetWishlistsByUser(keycloakUserID)
+	if err != nil {
+		logger.ActError("Error retrieving the wishlists", zap.Error(err))
+		return nil, fmt.Errorf("failed to get wishlists")
+	}
+
+	return wishlists, nil
+}
+
+func (s *WishlistServiceImpl) CreateWishlist(keycloakUserID string, name string) (*model.Wishlist, error) {
+	wishlist := &model.Wishlist{
+		KeycloakUserID: keycloakUserID,
+		Name:           name,
+		Items:          []model.WishlistItem{},
+	}
+
+	if err := s.WishlistRepository.CreateWishlist(wishlist); err != nil {
+		logger.ActError("Error creating the wishlist", zap.Error(err))
+		return nil, fmt.Errorf("failed to create wishlist")
+	}
+
+	logger.ActInfo("Wishlist created successfully")
+	return wishlist, nil
+}
+
+func (s *WishlistServiceImpl) DeleteWishlist(keycloakUserID string, wishlistId uint) error {
+	wishlist, err := s.getUserWishlist(keycloakUserID, wishlistId)
+	if err != nil {
+		return err
+	}
+
+	if wishlist.IsDefault {
+		return fmt.Errorf("default wishlist cannot be deleted")
+	}
+
+	return s.WishlistRepository.DeleteWishlist(wishlist.WishlistID)
+}
+
+// AddToWishlist adds a product that is on sale to the wishlist, adding the same product twice is a no-op
+func (s *WishlistServiceImpl) AddToWishlist(keycloakUserID string, wishlistId uint, productID uint) error {
+	wishlist, err := s.getUserWishlist(keycloakUserID, wishlistId)
+	if err != nil {
+		return err
+	}
+
+	product, err := s.ProductRepository.GetProductById(productID)
+	if err != nil || !product.IsAvailable(time.Now()) {
+		logger.ActError("Product not found")
+		return fmt.Errorf("product not found")
+	}
+
+	return s.addProductToWishlist(wishlist.WishlistID, product)
+}
+
+func (s *WishlistServiceImpl) RemoveFromWishlist(keycloakUserID string, wishlistId uint, itemId uint) error {
+	item, err := s.getUserWishlistItem(keycloakUserID, wishlistId, itemId)
+	if err != nil {
+		return err
+	}
+
+	return s.WishlistRepository.RemoveItemFromWishlist(item.ID)
+}
+
+// MoveToCart adds one unit of the wishlist product to the cart and removes it from the wishlist
+func (s *WishlistServiceImpl) MoveToCart(keycloakUserID string, wishlistId uint, itemId uint) error {
+	item, err := s.getUserWishlistItem(keycloakUserID, wishlistId, itemId)
+	if err != nil {
+		return err
+	}
+
+	cart, err := s.CartRepository.GetOrCreateCart(keycloakUserID)
+	if err != nil {
+		logger.ActError("Error getting or creating cart")
+		return fmt.Errorf("failed to get or create cart")
+	}
+
+	product, err := s.ProductRepository.GetProductById(item.ProductID)
+	if err != nil {
+		logger.ActError("Product not found")
+		return fmt.Errorf("product not found")
+	}
+	if !product.IsAvailable(time.Now()) {
+		return fmt.Errorf("product is not available")
+	}
+	// The wishlist does not keep a variant, it is chosen when adding the product to the cart
+	if product.HasVariants() {
+		return fmt.Errorf("variant is required for this product, add it to the cart with the chosen variant")
+	}
+	stock, err := s.availableStock(product)
+	if err != nil {
+		return err
+	}
+
+	// Merge with an existing cart line for the same product
+	existingItem, err := s.CartRepository.GetCartItemByProductId(cart.CartID, item.ProductID, nil)
+	if err == nil {
+		newQuantity := existingItem.Quantity + 1
+		if stock < newQuantity {
+			logger.ActError("Not enough stock")
+			return fmt.Errorf("insufficient stock for the product. Only %d item(s) available", stock)
+		}
+
+		if err := s.CartRepository.UpdateCartItemQuantity(existingItem.ID, newQuantity); err != nil {
+			logger.ActError("Error updating cart item quantity")
+			return fmt.Errorf("failed to update cart item quantity")
+		}
+	} else {
+		if stock < 1 {
+			logger.ActError("Not enough stock")
+			return fmt.Errorf("insufficient stock for the product")
+		}
+
+		cartItem := &model.CartItem{
+			CartID:     cart.CartID,
+			ProductID:  item.ProductID,
+			Quantity:   1,
+			UnitPrice:  product.ProductPrice,
+			TotalPrice: product.ProductPrice,
+			IsSelected: true,
+		}
+		if err := s.CartRepository.AddItemToCart(cartItem); err != nil {
+			logger.ActError("Error adding item to the cart")
+			return err
+		}
+	}
+
+	if err := s.WishlistRepository.RemoveItemFromWishlist(item.ID); err != nil {
+		logger.ActError("Error removing item from the wishlist", zap.Error(err))
+		return fmt.Errorf("failed to remove item from wishlist")
+	}
+
+	logger.ActInfo("Wishlist item moved to the cart successfully")
+	return nil
+}
+
+// SaveForLater moves a cart item into a wishlist, using the default wishlist when wishlistId is 0
+func (s *WishlistServiceImpl) SaveForLater(keycloakUserID string, cartItemId uint, wishlistId uint) error {
+	cart, err := s.CartRepository.GetOrCreateCart(keycloakUserID)
+	if err != nil {
+		logger.ActError("Error getting or creating cart")
+		return fmt.Errorf("failed to get or create cart")
+	}
+
+	cartItem, err := s.CartRepository.GetCartItemById(cartItemId)
+	if err != nil || cartItem.CartID != cart.CartID {
+		logger.ActError("Cart item not found")
+		return fmt.Errorf("cart item not found")
+	}
+
+	var wishlist *model.Wishlist
+	if wishlistId == 0 {
+		wishlist, err = s.getOrCreateDefaultWishlist(keycloakUserID)
+	} else {
+		wishlist, err = s.getUserWishlist(keycloakUserID, wishlistId)
+	}
+	if err != nil {
+		return err
+	}
+
+	// The item is saved even when the product is no longer on sale, so it is not lost from the cart
+	product, err := s.ProductRepository.GetProductById(cartItem.ProductID)
+	if err != nil {
+		logger.ActError("Product not found")
+		return fmt.Errorf("product not found")
+	}
+	if err := s.addProductToWishlist(wishlist.WishlistID, product); err != nil {
+		return err
+	}
+
+	if err := s.CartRepository.RemoveItemFromCart(cartItem.ID); err != nil {
+		logger.ActError("Error removing item from the cart", zap.Error(err))
+		return fmt.Errorf("failed to remove item from cart")
+	}
+
+	logger.ActInfo("Cart item saved for later successfully")
+	return nil
+}
+
+func (s *WishlistServiceImpl) addProductToWishlist(wishlistId uint, product *model.Product) error {
+	if _, err := s.WishlistRepository.GetWishlistItemByProductId(wishlistId, product.ProductID); err == nil {
+		logger.ActInfo("Product already in the wishlist")
+		return nil
+	}
+
+	stock, err := s.availableStock(product)
+	if err != nil {
+		return err
+	}
+	item := &model.WishlistItem{
+		WishlistID: wishlistId,
+		ProductID:  product.ProductID,
+		OutOfStock: stock <= 0,
+	}
+	if err := s.WishlistRepository.AddItemToWishlist(item); err != nil {
+		logger.ActError("Error adding item to the wishlist", zap.Error(err))
+		return fmt.Errorf("failed to add item to wishlist")
+	}
+
+	logger.ActInfo("Item added to the wishlist successfully")
+	return nil
+}
+
+// availableStock is the stock of the product in the active warehouses less the stock reserved for unpaid orders
+func (s *WishlistServiceImpl) availableStock(product *model.Product) (int, error) {
+	available, err := s.InventoryService.AvailableStock([]model.Product{*product})
+	if err != nil {
+		return 0, err
+	}
+	return available[product.ProductID], nil
+}
+
+func (s *WishlistServiceImpl) getOrCreateDefaultWishlist(keycloakUserID string) (*model.Wishlist, error) {
+	wishlist, err := s.WishlistRepository.GetDefaultWishlist(keycloakUserID)
+	if err == nil {
+		return wishlist, nil
+	}
+
+	wishlist = &model.Wishlist{
+		KeycloakUserID: keycloakUserID,
+		Name:           DefaultWishlistName,
+		IsDefault:      true,
+	}
+	if err := s.WishlistRepository.CreateWishlist(wishlist); err != nil {
+		logger.ActError("Error creating the default wishlist", zap.Error(err))
+		return nil, fmt.Errorf("failed to create default wishlist")
+	}
+	return wishlist, nil
+}
+
+func (s *WishlistServiceImpl) getUserWishlist(keycloakUserID string, wishlistId uint) (*model.Wishlist, error) {
+	wishlist, err := s.WishlistRepository.GetWishlistById(wishlistId)
+	if err != nil || wishlist.KeycloakUserID != keycloakUserID {
+		logger.ActError("Wishlist not found")
+		return nil, fmt.Errorf("wishlist not found")
+	}
+	return wishlist, nil
+}
+
+func (s *WishlistServiceImpl) getUserWishlistItem(keycloakUserID string, wishlistId uint, itemId uint) (*model.WishlistItem, error) {
+	wishlist, err := s.getUserWishlist(keycloakUserID, wishlistId)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.WishlistRepository.GetWishlistItemById(itemId)
+	if err != nil || item.WishlistID != wishlist.WishlistID {
+		logger.ActError("Wishlist item not found")
+		return nil, fmt.Errorf("wishlist item not found")
+	}
+	return item, nil
+}
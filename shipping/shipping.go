@@ -0,0 +1,111 @@
+package shipping
+
+import (
+	"fmt"
+	"shophub-backend/model"
+	"shophub-backend/money"
+	"sort"
+	"strings"
+)
+
+const (
+	MethodTypeFlatRate          = "FLAT_RATE"
+	MethodTypeWeightBased       = "WEIGHT_BASED"
+	MethodTypeFreeOverThreshold = "FREE_OVER_THRESHOLD"
+)
+
+// Parcel is what is being shipped, Subtotal is the merchandise value after discounts.
+// Method amounts must already be in the currency of the subtotal.
+type Parcel struct {
+	WeightKg float64
+	Subtotal money.Money
+}
+
+// Option is a shipping method available for a parcel, with its cost
+type Option struct {
+	ShippingMethodID uint        `json:"shipping_method_id"`
+	Name             string      `json:"name"`
+	Type             string      `json:"type"`
+	Cost             money.Money `json:"cost"`
+	MinDeliveryDays  int         `json:"min_delivery_days"`
+	MaxDeliveryDays  int         `json:"max_delivery_days"`
+	Explanation      string      `json:"explanation"`
+}
+
+// ZoneFor returns the zone that ships to the country, falling back to the default zone
+func ZoneFor(zones []model.ShippingZone, country string) *model.ShippingZone {
+	var fallback *model.ShippingZone
+	for i := range zones {
+		zone := &zones[i]
+		for _, zoneCountry := range zone.Countries {
+			if strings.EqualFold(strings.TrimSpace(zoneCountry.Country), strings.TrimSpace(country)) {
+				return zone
+			}
+		}
+		if zone.IsDefault && fallback == nil {
+			fallback = zone
+		}
+	}
+	return fallback
+}
+
+// Options returns the active methods of the zone that can ship the parcel, cheapest first
+func Options(zone *model.ShippingZone, parcel Parcel) []Option {
+	options := []Option{}
+	if zone == nil {
+		return options
+	}
+	for _, method := range zone.Methods {
+		if option, ok := Quote(method, parcel); ok {
+			options = append(options, option)
+		}
+	}
+	sort.SliceStable(options, func(i, j int) bool {
+		return options[i].Cost.LessThan(options[j].Cost)
+	})
+	return options
+}
+
+// Quote calculates the cost of shipping the parcel with the method,
+// false when the method is inactive or cannot ship the parcel
+func Quote(method model.ShippingMethod, parcel Parcel) (Option, bool) {
+	if !method.IsActive {
+		return Option{}, false
+	}
+	if method.MaxWeightKg > 0 && parcel.WeightKg > method.MaxWeightKg {
+		return Option{}, false
+	}
+
+	option := Option{
+		ShippingMethodID: method.ShippingMethodID,
+		Name:             method.Name,
+		Type:             method.Type,
+		MinDeliveryDays:  method.MinDeliveryDays,
+		MaxDeliveryDays:  method.MaxDeliveryDays,
+	}
+
+	switch method.Type {
+	case MethodTypeFlatRate:
+		option.Cost = method.BaseRate
+		option.Explanation = fmt.Sprintf("Flat rate of %s", method.BaseRate)
+	case MethodTypeWeightBased:
+		option.Cost = method.BaseRate.Add(method.RatePerKg.MulFloat(parcel.WeightKg))
+		option.Explanation = fmt.Sprintf("%s plus %s per kg for %s kg", method.BaseRate, method.RatePerKg, formatWeight(parcel.WeightKg))
+	case MethodTypeFreeOverThreshold:
+		if !parcel.Subtotal.LessThan(method.FreeThreshold) {
+			option.Cost = money.Zero(parcel.Subtotal.Currency)
+			option.Explanation = fmt.Sprintf("Free for orders of %s or more", method.FreeThreshold)
+		} else {
+			option.Cost = method.BaseRate
+			option.Explanation = fmt.Sprintf("%s, free for orders of %s or more", method.BaseRate, method.FreeThreshold)
+		}
+	default:
+		return Option{}, false
+	}
+	option.Cost = option.Cost.WithCurrency(parcel.Subtotal.Currency)
+	return option, true
+}
+
+func formatWeight(weight float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.3f", weight), "0"), ".")
+}
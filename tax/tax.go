@@ -0,0 +1,163 @@
+package tax
+
+import (
+	"shophub-backend/model"
+	"shophub-backend/money"
+	"sort"
+	"strings"
+)
+
+// Address is the part of the shipping address that decides which rates apply
+type Address struct {
+	Country string
+	Region  string
+}
+
+// Line is a taxable amount, the line total after promotion and coupon discounts
+type Line struct {
+	ItemID     uint
+	CategoryID uint
+	Amount     money.Money
+}
+
+// TaxLine is the tax charged on a single line
+type TaxLine struct {
+	ItemID        uint        `json:"item_id"`
+	TaxRateID     uint        `json:"tax_rate_id"`
+	Name          string      `json:"name"`
+	Country       string      `json:"country"`
+	Region        string      `json:"region,omitempty"`
+	Rate          float64     `json:"rate"`
+	Inclusive     bool        `json:"inclusive"`
+	TaxableAmount money.Money `json:"taxable_amount"`
+	Amount        money.Money `json:"amount"`
+}
+
+// Result is the tax on a set of lines. ExclusiveAmount is the part of the tax that is added
+// on top of the prices, the rest is already included in them.
+type Result struct {
+	Lines           []TaxLine
+	Amount          money.Money
+	ExclusiveAmount money.Money
+}
+
+// LinesFor returns the tax lines of a single item
+func (r *Result) LinesFor(itemID uint) []TaxLine {
+	var lines []TaxLine
+	for _, line := range r.Lines {
+		if line.ItemID == itemID {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// ExclusiveAmountFor is the tax added on top of the price of a single item
+func (r *Result) ExclusiveAmountFor(itemID uint) money.Money {
+	amount := money.Zero(r.Amount.Currency)
+	for _, line := range r.LinesFor(itemID) {
+		if !line.Inclusive {
+			amount = amount.Add(line.Amount)
+		}
+	}
+	return amount
+}
+
+type TaxCalculator interface {
+	Calculate(address Address, lines []Line) (*Result, error)
+}
+
+// TableCalculator picks the most specific rate for every line from a table of rates.
+// A rate with a region is more specific than one for the whole country, and within that
+// a rate for the line's category is more specific than one for all categories.
+type TableCalculator struct {
+	rates []model.TaxRate
+}
+
+func NewTableCalculator(rates []model.TaxRate) *TableCalculator {
+	ordered := make([]model.TaxRate, len(rates))
+	copy(ordered, rates)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].TaxRateID < ordered[j].TaxRateID
+	})
+	return &TableCalculator{rates: ordered}
+}
+
+func (c *TableCalculator) Calculate(address Address, lines []Line) (*Result, error) {
+	currency := money.DefaultCurrency
+	if len(lines) > 0 && lines[0].Amount.Currency != "" {
+		currency = lines[0].Amount.Currency
+	}
+	result := &Result{
+		Lines:           []TaxLine{},
+		Amount:          money.Zero(currency),
+		ExclusiveAmount: money.Zero(currency),
+	}
+
+	for _, line := range lines {
+		rate := c.rateFor(address, line.CategoryID)
+		if rate == nil || !line.Amount.IsPositive() {
+			continue
+		}
+
+		var amount money.Money
+		if rate.Inclusive {
+			// The price already contains the tax, only the included part is reported
+			amount = line.Amount.Sub(line.Amount.MulFloat(1 / (1 + rate.Rate/100)))
+		} else {
+			amount = line.Amount.Percent(rate.Rate)
+		}
+
+		result.Lines = append(result.Lines, TaxLine{
+			ItemID:        line.ItemID,
+			TaxRateID:     rate.TaxRateID,
+			Name:          rate.Name,
+			Country:       rate.Country,
+			Region:        rate.Region,
+			Rate:          rate.Rate,
+			Inclusive:     rate.Inclusive,
+			TaxableAmount: line.Amount,
+			Amount:        amount,
+		})
+		result.Amount = result.Amount.Add(amount)
+		if !rate.Inclusive {
+			result.ExclusiveAmount = result.ExclusiveAmount.Add(amount)
+		}
+	}
+	return result, nil
+}
+
+func (c *TableCalculator) rateFor(address Address, categoryID uint) *model.TaxRate {
+	var best *model.TaxRate
+	bestScore := -1
+	for i := range c.rates {
+		rate := &c.rates[i]
+		if !rate.IsActive || !sameName(rate.Country, address.Country) {
+			continue
+		}
+
+		score := 0
+		if rate.Region != "" {
+			if !sameName(rate.Region, address.Region) {
+				continue
+			}
+			score += 2
+		}
+		if rate.CategoryID != nil {
+			if *rate.CategoryID != categoryID {
+				continue
+			}
+			score++
+		}
+
+		if score > bestScore {
+			best = rate
+			bestScore = score
+		}
+	}
+	return best
+}
+
+func sameName(a string, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
@@ -0,0 +1,154 @@
+package tax
+
+import (
+	"shophub-backend/model"
+	"shophub-backend/money"
+	"testing"
+)
+
+func category(id uint) *uint {
+	return &id
+}
+
+// rates are listed out of order, the calculator orders them by id
+var rates = []model.TaxRate{
+	{TaxRateID: 11, Name: "US duplicate", Country: "US", Rate: 9, IsActive: true},
+	{TaxRateID: 1, Name: "US sales tax", Country: "US", Rate: 5, IsActive: true},
+	{TaxRateID: 2, Name: "California", Country: "US", Region: "CA", Rate: 7.25, IsActive: true},
+	{TaxRateID: 3, Name: "US food", Country: "US", CategoryID: category(10), Rate: 2, IsActive: true},
+	{TaxRateID: 4, Name: "California food", Country: "US", Region: "CA", CategoryID: category(10), Rate: 1, IsActive: true},
+	{TaxRateID: 5, Name: "Germany VAT", Country: "DE", Rate: 19, Inclusive: true, IsActive: true},
+	{TaxRateID: 6, Name: "Germany reduced VAT", Country: "DE", CategoryID: category(10), Rate: 7, Inclusive: true, IsActive: true},
+	{TaxRateID: 7, Name: "France VAT", Country: "FR", Rate: 20, Inclusive: true, IsActive: false},
+	{TaxRateID: 8, Name: "Texas clothing", Country: "US", Region: "TX", CategoryID: category(20), Rate: 8, IsActive: true},
+	{TaxRateID: 9, Name: "US clothing", Country: "US", CategoryID: category(30), Rate: 3, IsActive: true},
+}
+
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		name       string
+		address    Address
+		categoryID uint
+		amount     int64
+		currency   string
+		wantRate   uint
+		want       int64
+	}{
+		{"country rate", Address{Country: "US", Region: "NY"}, 1, 10000, "USD", 1, 500},
+		{"region beats country", Address{Country: "US", Region: "CA"}, 1, 10000, "USD", 2, 725},
+		{"category beats all categories", Address{Country: "US", Region: "NY"}, 10, 10000, "USD", 3, 200},
+		{"region and category beat everything", Address{Country: "US", Region: "CA"}, 10, 10000, "USD", 4, 100},
+		{"region beats a country rate for the category", Address{Country: "US", Region: "CA"}, 30, 10000, "USD", 2, 725},
+		{"region rate for another category does not apply", Address{Country: "US", Region: "TX"}, 1, 10000, "USD", 1, 500},
+		{"region rate for the category", Address{Country: "US", Region: "TX"}, 20, 10000, "USD", 8, 800},
+		{"country and region are matched case insensitively", Address{Country: " us", Region: "ca "}, 1, 10000, "USD", 2, 725},
+		{"equally specific rates use the lowest id", Address{Country: "US"}, 1, 10000, "USD", 1, 500},
+		{"exclusive rounds half away from zero", Address{Country: "US"}, 1, 10, "USD", 1, 1},
+		{"exclusive rounds down below half", Address{Country: "US", Region: "CA"}, 1, 333, "USD", 2, 24},
+		{"inclusive reports the included part", Address{Country: "DE"}, 1, 11900, "EUR", 5, 1900},
+		{"inclusive rounds the price without tax", Address{Country: "DE"}, 1, 1000, "EUR", 5, 160},
+		{"inclusive category rate", Address{Country: "DE"}, 10, 10700, "EUR", 6, 700},
+		{"zero decimal currency", Address{Country: "DE"}, 1, 1190, "JPY", 5, 190},
+	}
+
+	calculator := NewTableCalculator(rates)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calculator.Calculate(tt.address, []Line{
+				{ItemID: 1, CategoryID: tt.categoryID, Amount: money.New(tt.amount, tt.currency)},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Lines) != 1 {
+				t.Fatalf("got %d tax lines, want 1", len(result.Lines))
+			}
+
+			line := result.Lines[0]
+			if line.TaxRateID != tt.wantRate {
+				t.Errorf("rate = %d (%s), want %d", line.TaxRateID, line.Name, tt.wantRate)
+			}
+			if line.Amount != money.New(tt.want, tt.currency) {
+				t.Errorf("tax = %d %s, want %d %s", line.Amount.Amount, line.Amount.Currency, tt.want, tt.currency)
+			}
+			if line.TaxableAmount != money.New(tt.amount, tt.currency) {
+				t.Errorf("taxable amount = %d, want %d", line.TaxableAmount.Amount, tt.amount)
+			}
+			if result.Amount != line.Amount {
+				t.Errorf("total tax = %d, want %d", result.Amount.Amount, line.Amount.Amount)
+			}
+		})
+	}
+}
+
+func TestCalculateWithoutRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		address Address
+	}{
+		{"inactive rate is ignored", Address{Country: "FR"}},
+		{"country without rates", Address{Country: "JP"}},
+		{"no country", Address{}},
+	}
+
+	calculator := NewTableCalculator(rates)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calculator.Calculate(tt.address, []Line{{ItemID: 1, Amount: money.New(10000, "EUR")}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Lines) != 0 || !result.Amount.IsZero() || !result.ExclusiveAmount.IsZero() {
+				t.Errorf("result = %+v, want no tax", result)
+			}
+			if result.Amount.Currency != "EUR" {
+				t.Errorf("currency = %q, want EUR", result.Amount.Currency)
+			}
+		})
+	}
+}
+
+func TestCalculateTotals(t *testing.T) {
+	tests := []struct {
+		name          string
+		address       Address
+		want          int64
+		wantExclusive int64
+	}{
+		{"exclusive tax is added on top", Address{Country: "US"}, 700, 700},
+		{"inclusive tax is already in the prices", Address{Country: "DE"}, 2251, 0},
+	}
+
+	lines := []Line{
+		{ItemID: 1, CategoryID: 1, Amount: money.New(10000, "USD")},
+		{ItemID: 2, CategoryID: 10, Amount: money.New(10000, "USD")},
+		{ItemID: 3, CategoryID: 1, Amount: money.Zero("USD")},
+		{ItemID: 4, CategoryID: 1, Amount: money.New(-500, "USD")},
+	}
+
+	calculator := NewTableCalculator(rates)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calculator.Calculate(tt.address, lines)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result.Lines) != 2 {
+				t.Fatalf("got %d tax lines, want 2, zero and negative lines are skipped", len(result.Lines))
+			}
+			if result.Amount.Amount != tt.want {
+				t.Errorf("tax = %d, want %d", result.Amount.Amount, tt.want)
+			}
+			if result.ExclusiveAmount.Amount != tt.wantExclusive {
+				t.Errorf("exclusive tax = %d, want %d", result.ExclusiveAmount.Amount, tt.wantExclusive)
+			}
+			if lines := result.LinesFor(3); len(lines) != 0 {
+				t.Errorf("zero line has tax lines %+v", lines)
+			}
+			if got := result.ExclusiveAmountFor(1).Add(result.ExclusiveAmountFor(2)); got != result.ExclusiveAmount {
+				t.Errorf("exclusive tax per item adds up to %d, want %d", got.Amount, result.ExclusiveAmount.Amount)
+			}
+		})
+	}
+}